@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/geocode/fakegeocoder"
+)
+
+var fakeGeocoderPort int
+
+var fakeGeocoderCmd = &cobra.Command{
+	Use:   "fake-geocoder",
+	Short: "Start a local Nominatim compatible geocoder stand-in for development",
+	Run: func(cmd *cobra.Command, args []string) {
+		server := fakegeocoder.New()
+
+		fmt.Printf("Fake geocoder listening on :%d\n", fakeGeocoderPort)
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", fakeGeocoderPort), server.Handler()); err != nil {
+			fmt.Fprintf(os.Stderr, "Fake geocoder stopped: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	fakeGeocoderCmd.Flags().IntVar(&fakeGeocoderPort, "port", 9300, "port to listen on")
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/oidc/fakeissuer"
+)
+
+var (
+	fakeIdpPort         int
+	fakeIdpIssuer       string
+	fakeIdpClientID     string
+	fakeIdpClientSecret string
+)
+
+var fakeIdpCmd = &cobra.Command{
+	Use:   "fake-idp",
+	Short: "Start a local OpenID Connect stand-in issuer for development",
+	Run: func(cmd *cobra.Command, args []string) {
+		issuer := fakeIdpIssuer
+		if issuer == "" {
+			issuer = fmt.Sprintf("http://localhost:%d", fakeIdpPort)
+		}
+
+		server, err := fakeissuer.New(fakeissuer.Config{
+			Issuer:       issuer,
+			ClientID:     fakeIdpClientID,
+			ClientSecret: fakeIdpClientSecret,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create fake issuer: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Fake OIDC issuer %s listening on :%d\n", issuer, fakeIdpPort)
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", fakeIdpPort), server.Handler()); err != nil {
+			fmt.Fprintf(os.Stderr, "Fake issuer stopped: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	fakeIdpCmd.Flags().IntVar(&fakeIdpPort, "port", 9000, "port to listen on")
+	fakeIdpCmd.Flags().StringVar(&fakeIdpIssuer, "issuer", "", "issuer URL (defaults to http://localhost:<port>)")
+	fakeIdpCmd.Flags().StringVar(&fakeIdpClientID, "client-id", "web-server", "accepted client id")
+	fakeIdpCmd.Flags().StringVar(&fakeIdpClientSecret, "client-secret", "web-server-secret", "accepted client secret")
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/storage/fakes3"
+)
+
+var (
+	fakeS3Port      int
+	fakeS3AccessKey string
+	fakeS3SecretKey string
+	fakeS3Region    string
+)
+
+var fakeS3Cmd = &cobra.Command{
+	Use:   "fake-s3",
+	Short: "Start a local in-memory S3 compatible stand-in for development",
+	Run: func(cmd *cobra.Command, args []string) {
+		server := fakes3.New(fakes3.Config{
+			AccessKey: fakeS3AccessKey,
+			SecretKey: fakeS3SecretKey,
+			Region:    fakeS3Region,
+		})
+
+		fmt.Printf("Fake S3 listening on :%d\n", fakeS3Port)
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", fakeS3Port), server.Handler()); err != nil {
+			fmt.Fprintf(os.Stderr, "Fake S3 stopped: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	fakeS3Cmd.Flags().IntVar(&fakeS3Port, "port", 9100, "port to listen on")
+	fakeS3Cmd.Flags().StringVar(&fakeS3AccessKey, "access-key", "fake-access-key", "accepted access key")
+	fakeS3Cmd.Flags().StringVar(&fakeS3SecretKey, "secret-key", "fake-secret-key", "accepted secret key")
+	fakeS3Cmd.Flags().StringVar(&fakeS3Region, "region", "us-east-1", "region requests must be signed for")
+}
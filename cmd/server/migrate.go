@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/config"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
 )
 
 var migrateCmd = &cobra.Command{
@@ -35,5 +39,23 @@ var migrateCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
 			os.Exit(1)
 		}
+
+		if opt == "up" {
+			migrateData(viper)
+		}
 	},
 }
+
+// migrateData runs the data migrations that need the server's own code, once
+// the schema is up to date. Each leaves already migrated rows alone.
+func migrateData(viper *viper.Viper) {
+	log := config.NewLogger(viper)
+	db := config.NewDatabase(viper, log.App)
+
+	countryUseCase := usecase.NewCountryUseCase(db, log.App, repository.NewAddressRepository(log.App),
+		repository.NewContactHistoryRepository(log.App))
+	if err := countryUseCase.Normalize(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Normalizing address countries failed: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/route"
+)
+
+// The document is held to the registered routes and the dto package by the
+// tests of the route package.
+var openAPICmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Print the OpenAPI document",
+	Run: func(cmd *cobra.Command, args []string) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(route.OpenAPI()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode document: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -17,4 +17,8 @@ func init() {
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(migrateCmd)
 	rootCmd.AddCommand(createCmd)
+	rootCmd.AddCommand(fakeIdpCmd)
+	rootCmd.AddCommand(fakeS3Cmd)
+	rootCmd.AddCommand(fakeGeocoderCmd)
+	rootCmd.AddCommand(openAPICmd)
 }
\ No newline at end of file
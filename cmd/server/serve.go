@@ -16,7 +16,7 @@ var serveCmd = &cobra.Command{
 		log := config.NewLogger(viperConfig)
 		db := config.NewDatabase(viperConfig, log.App)
 		validate := config.NewValidator(viperConfig)
-		app := config.NewEcho(viperConfig)
+		app := config.NewEcho(viperConfig, log.App)
 
 		config.Bootstrap(&config.BootstrapConfig{
 			DB:       db,
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_contacts_user_id;
+DROP INDEX IF EXISTS idx_contacts_phone_trgm;
+DROP INDEX IF EXISTS idx_contacts_email_trgm;
+DROP INDEX IF EXISTS idx_contacts_last_name_trgm;
+DROP INDEX IF EXISTS idx_contacts_first_name_trgm;
+DROP INDEX IF EXISTS idx_contacts_search_vector;
+ALTER TABLE contacts DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,15 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple'::regconfig, coalesce(first_name, '')), 'A') ||
+    setweight(to_tsvector('simple'::regconfig, coalesce(last_name, '')), 'A') ||
+    setweight(to_tsvector('simple'::regconfig, coalesce(email, '')), 'B') ||
+    setweight(to_tsvector('simple'::regconfig, coalesce(phone, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_search_vector ON contacts USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_contacts_first_name_trgm ON contacts USING GIN (first_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_contacts_last_name_trgm ON contacts USING GIN (last_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_contacts_email_trgm ON contacts USING GIN (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_contacts_phone_trgm ON contacts USING GIN (phone gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_contacts_user_id ON contacts (user_id);
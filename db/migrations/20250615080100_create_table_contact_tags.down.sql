@@ -0,0 +1 @@
+DROP TABLE IF EXISTS contact_tags;
\ No newline at end of file
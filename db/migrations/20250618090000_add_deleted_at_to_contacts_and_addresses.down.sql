@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_addresses_trashed;
+DROP INDEX IF EXISTS idx_contacts_trashed;
+ALTER TABLE addresses DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS deleted_at;
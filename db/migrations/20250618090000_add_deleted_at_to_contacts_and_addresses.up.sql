@@ -0,0 +1,5 @@
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS deleted_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS deleted_at BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_trashed ON contacts (user_id, deleted_at) WHERE deleted_at <> 0;
+CREATE INDEX IF NOT EXISTS idx_addresses_trashed ON addresses (deleted_at) WHERE deleted_at <> 0;
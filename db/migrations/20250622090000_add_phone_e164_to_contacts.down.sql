@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_contacts_user_id_phone_e164;
+ALTER TABLE contacts DROP COLUMN IF EXISTS phone_e164;
@@ -0,0 +1,13 @@
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS phone_e164 TEXT NOT NULL DEFAULT '';
+
+-- Numbers already written in international form can be carried over as is;
+-- national ones are normalized the next time the contact is saved.
+UPDATE contacts
+SET phone_e164 = '+' || regexp_replace(phone, '[^0-9]', '', 'g')
+WHERE phone LIKE '+%' AND length(regexp_replace(phone, '[^0-9]', '', 'g')) BETWEEN 8 AND 15;
+
+UPDATE contacts
+SET email = split_part(email, '@', 1) || '@' || lower(split_part(email, '@', 2))
+WHERE email LIKE '%@%' AND email NOT LIKE '%@%@%';
+
+CREATE INDEX IF NOT EXISTS idx_contacts_user_id_phone_e164 ON contacts (user_id, phone_e164 text_pattern_ops);
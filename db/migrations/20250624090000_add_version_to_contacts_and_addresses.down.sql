@@ -0,0 +1,2 @@
+ALTER TABLE addresses DROP COLUMN IF EXISTS version;
+ALTER TABLE contacts DROP COLUMN IF EXISTS version;
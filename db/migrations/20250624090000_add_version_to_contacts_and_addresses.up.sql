@@ -0,0 +1,2 @@
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS contact_history (
+    id TEXT PRIMARY KEY,
+    contact_id TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    version BIGINT NOT NULL,
+    actor_id TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '{}',
+    snapshot JSONB NOT NULL DEFAULT '{}',
+    created_at BIGINT NOT NULL,
+    CONSTRAINT fk_contact FOREIGN KEY(contact_id) REFERENCES contacts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_history_contact_id ON contact_history (contact_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_contact_history_entity_version ON contact_history (entity_id, version);
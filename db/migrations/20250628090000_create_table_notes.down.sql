@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_contacts_last_interaction_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS last_interaction_at;
+DROP TABLE IF EXISTS notes;
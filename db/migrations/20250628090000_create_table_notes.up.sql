@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS notes (
+    id TEXT PRIMARY KEY,
+    contact_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    body TEXT NOT NULL,
+    occurred_at BIGINT NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple'::regconfig, body)) STORED,
+    CONSTRAINT fk_contact FOREIGN KEY(contact_id) REFERENCES contacts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notes_contact_id_occurred_at ON notes (contact_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notes_search_vector ON notes USING GIN (search_vector);
+
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS last_interaction_at BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_contacts_last_interaction_at ON contacts (user_id, last_interaction_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_contacts_custom_fields;
+ALTER TABLE contacts DROP COLUMN IF EXISTS custom_fields;
+DROP TABLE IF EXISTS custom_fields;
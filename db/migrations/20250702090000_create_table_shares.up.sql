@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS shares (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL,
+    grantee_id TEXT NOT NULL,
+    contact_id TEXT,
+    tag_id TEXT,
+    permission TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    CONSTRAINT fk_owner FOREIGN KEY(owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_grantee FOREIGN KEY(grantee_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contact FOREIGN KEY(contact_id) REFERENCES contacts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_tag FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    CONSTRAINT chk_shares_target CHECK ((contact_id IS NULL) <> (tag_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_shares_contact_id_grantee_id ON shares (contact_id, grantee_id) WHERE contact_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_shares_tag_id_grantee_id ON shares (tag_id, grantee_id) WHERE tag_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_shares_grantee_id ON shares (grantee_id);
+CREATE INDEX IF NOT EXISTS idx_shares_owner_id ON shares (owner_id);
@@ -0,0 +1,2 @@
+ALTER TABLE notes DROP COLUMN IF EXISTS updated_by;
+ALTER TABLE notes DROP COLUMN IF EXISTS created_by;
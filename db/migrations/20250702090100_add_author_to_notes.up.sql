@@ -0,0 +1,6 @@
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS created_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS updated_by TEXT NOT NULL DEFAULT '';
+
+-- Before sharing only the contact's owner could write its notes.
+UPDATE notes SET created_by = contacts.user_id, updated_by = contacts.user_id
+FROM contacts WHERE contacts.id = notes.contact_id;
@@ -0,0 +1,2 @@
+ALTER TABLE contacts DROP COLUMN IF EXISTS photo_content_type;
+ALTER TABLE contacts DROP COLUMN IF EXISTS photo_id;
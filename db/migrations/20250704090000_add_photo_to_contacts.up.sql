@@ -0,0 +1,2 @@
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS photo_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS photo_content_type TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS uq_users_calendar_token;
+ALTER TABLE users DROP COLUMN IF EXISTS calendar_token;
+
+DROP INDEX IF EXISTS idx_contacts_has_dates;
+ALTER TABLE contacts DROP COLUMN IF EXISTS dates;
@@ -0,0 +1,5 @@
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS dates JSONB NOT NULL DEFAULT '[]';
+CREATE INDEX IF NOT EXISTS idx_contacts_has_dates ON contacts (user_id) WHERE dates <> '[]'::jsonb AND deleted_at = 0;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS calendar_token TEXT NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_users_calendar_token ON users (calendar_token) WHERE calendar_token <> '';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS reminders (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    contact_id TEXT NOT NULL,
+    date_key TEXT NOT NULL,
+    occurs_on TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contact FOREIGN KEY(contact_id) REFERENCES contacts(id) ON DELETE CASCADE
+);
+
+-- One reminder per date and occurrence, however often the job runs.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reminders_contact_id_date_key_occurs_on ON reminders (contact_id, date_key, occurs_on);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS uq_addresses_contact_id_primary;
+ALTER TABLE addresses DROP COLUMN IF EXISTS is_primary;
+ALTER TABLE addresses DROP COLUMN IF EXISTS custom_label;
+ALTER TABLE addresses DROP COLUMN IF EXISTS label;
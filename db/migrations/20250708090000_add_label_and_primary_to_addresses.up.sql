@@ -0,0 +1,13 @@
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS label TEXT NOT NULL DEFAULT '';
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS custom_label TEXT NOT NULL DEFAULT '';
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Contacts that already have addresses start out with the oldest one as primary.
+UPDATE addresses SET is_primary = TRUE
+WHERE id IN (
+    SELECT DISTINCT ON (contact_id) id FROM addresses
+    WHERE deleted_at = 0
+    ORDER BY contact_id, created_at, id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_addresses_contact_id_primary ON addresses (contact_id) WHERE is_primary AND deleted_at = 0;
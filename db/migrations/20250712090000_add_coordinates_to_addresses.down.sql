@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_addresses_coordinates;
+ALTER TABLE addresses DROP COLUMN IF EXISTS longitude;
+ALTER TABLE addresses DROP COLUMN IF EXISTS latitude;
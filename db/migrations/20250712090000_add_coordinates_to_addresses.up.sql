@@ -0,0 +1,7 @@
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE addresses ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+-- Proximity searches narrow addresses down to a bounding box through this
+-- index before computing distances.
+CREATE INDEX IF NOT EXISTS idx_addresses_coordinates ON addresses (latitude, longitude)
+WHERE latitude IS NOT NULL AND deleted_at = 0;
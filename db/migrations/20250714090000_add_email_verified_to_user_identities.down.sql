@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_identities_verified_email;
+ALTER TABLE user_identities DROP COLUMN IF EXISTS email_verified;
@@ -0,0 +1,6 @@
+-- Existing identities may carry emails the identity provider never verified,
+-- so none of them count as verified.
+ALTER TABLE user_identities ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_verified_email ON user_identities (LOWER(email))
+WHERE email_verified;
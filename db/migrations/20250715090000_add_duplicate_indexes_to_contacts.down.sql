@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_contacts_duplicate_name_trgm;
+DROP INDEX IF EXISTS idx_contacts_duplicate_phone;
+DROP INDEX IF EXISTS idx_contacts_duplicate_email;
@@ -0,0 +1,8 @@
+-- Finding duplicates joins contacts on these expressions, which must stay
+-- identical to the ones ContactRepository.FindDuplicatePairs uses.
+CREATE INDEX IF NOT EXISTS idx_contacts_duplicate_email ON contacts (user_id, (lower(trim(coalesce(email, '')))))
+WHERE deleted_at = 0;
+CREATE INDEX IF NOT EXISTS idx_contacts_duplicate_phone ON contacts (user_id, (regexp_replace(coalesce(nullif(phone_e164, ''), phone, ''), '[^0-9]', '', 'g')))
+WHERE deleted_at = 0;
+CREATE INDEX IF NOT EXISTS idx_contacts_duplicate_name_trgm ON contacts USING GIN ((lower(trim(coalesce(first_name, '') || ' ' || coalesce(last_name, '')))) gin_trgm_ops)
+WHERE deleted_at = 0;
@@ -0,0 +1,3 @@
+-- A state with a user_id links the identity that logs in to that user instead
+-- of logging in as, or provisioning, the identity's own user.
+ALTER TABLE oidc_states ADD COLUMN IF NOT EXISTS user_id TEXT REFERENCES users(id) ON DELETE CASCADE;
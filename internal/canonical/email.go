@@ -0,0 +1,14 @@
+package canonical
+
+import "strings"
+
+// Email trims the address and lowercases its domain. The local part is kept
+// as is, since RFC 5321 leaves its case significance to the receiving host.
+func Email(raw string) string {
+	raw = strings.TrimSpace(raw)
+	at := strings.LastIndexByte(raw, '@')
+	if at < 0 {
+		return raw
+	}
+	return raw[:at+1] + strings.ToLower(raw[at+1:])
+}
@@ -0,0 +1,23 @@
+package canonical
+
+import "testing"
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "ada@example.com", want: "ada@example.com"},
+		{raw: "Ada@Example.COM", want: "Ada@example.com"},
+		{raw: "  ada@example.com\t\n", want: "ada@example.com"},
+		{raw: " Ada.Lovelace+Notes@EXAMPLE.org ", want: "Ada.Lovelace+Notes@example.org"},
+		{raw: `"a@b"@Example.com`, want: `"a@b"@example.com`},
+		{raw: " Ada ", want: "Ada"},
+		{raw: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Email(tt.raw); got != tt.want {
+			t.Errorf("Email(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
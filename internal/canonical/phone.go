@@ -0,0 +1,207 @@
+// Package canonical turns free-form contact details into a single stored
+// form: phone numbers into E.164 and email addresses into a lowercase domain.
+package canonical
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// region describes how national numbers are written in one country. The
+// length bounds apply to the national significant number, i.e. without the
+// calling code and trunk prefix.
+type region struct {
+	callingCode string
+	trunk       string
+	minLength   int
+	maxLength   int
+	names       []string
+}
+
+var regions = map[string]region{
+	"AE": {"971", "0", 8, 9, []string{"united arab emirates", "uae"}},
+	"AR": {"54", "0", 10, 11, []string{"argentina"}},
+	"AT": {"43", "0", 4, 13, []string{"austria"}},
+	"AU": {"61", "0", 9, 9, []string{"australia"}},
+	"BD": {"880", "0", 8, 10, []string{"bangladesh"}},
+	"BE": {"32", "0", 8, 9, []string{"belgium"}},
+	"BR": {"55", "0", 10, 11, []string{"brazil"}},
+	"CA": {"1", "1", 10, 10, []string{"canada"}},
+	"CH": {"41", "0", 9, 9, []string{"switzerland"}},
+	"CL": {"56", "", 9, 9, []string{"chile"}},
+	"CN": {"86", "0", 9, 11, []string{"china"}},
+	"CO": {"57", "", 8, 10, []string{"colombia"}},
+	"CZ": {"420", "", 9, 9, []string{"czechia", "czech republic"}},
+	"DE": {"49", "0", 6, 13, []string{"germany", "deutschland"}},
+	"DK": {"45", "", 8, 8, []string{"denmark"}},
+	"EG": {"20", "0", 8, 10, []string{"egypt"}},
+	"ES": {"34", "", 9, 9, []string{"spain", "españa"}},
+	"FI": {"358", "0", 5, 12, []string{"finland"}},
+	"FR": {"33", "0", 9, 9, []string{"france"}},
+	"GB": {"44", "0", 9, 10, []string{"united kingdom", "uk", "great britain", "england", "scotland", "wales"}},
+	"GR": {"30", "", 10, 10, []string{"greece"}},
+	"HK": {"852", "", 8, 8, []string{"hong kong"}},
+	"ID": {"62", "0", 8, 12, []string{"indonesia"}},
+	"IE": {"353", "0", 7, 9, []string{"ireland"}},
+	"IL": {"972", "0", 8, 9, []string{"israel"}},
+	"IN": {"91", "0", 10, 10, []string{"india"}},
+	"IT": {"39", "", 6, 11, []string{"italy", "italia"}},
+	"JP": {"81", "0", 9, 10, []string{"japan"}},
+	"KE": {"254", "0", 9, 9, []string{"kenya"}},
+	"KR": {"82", "0", 8, 10, []string{"south korea", "korea", "republic of korea"}},
+	"MX": {"52", "", 10, 10, []string{"mexico"}},
+	"MY": {"60", "0", 8, 10, []string{"malaysia"}},
+	"NG": {"234", "0", 8, 10, []string{"nigeria"}},
+	"NL": {"31", "0", 9, 9, []string{"netherlands", "the netherlands", "holland"}},
+	"NO": {"47", "", 8, 8, []string{"norway"}},
+	"NZ": {"64", "0", 8, 10, []string{"new zealand"}},
+	"PH": {"63", "0", 8, 10, []string{"philippines"}},
+	"PK": {"92", "0", 9, 10, []string{"pakistan"}},
+	"PL": {"48", "", 9, 9, []string{"poland"}},
+	"PT": {"351", "", 9, 9, []string{"portugal"}},
+	"RU": {"7", "8", 10, 10, []string{"russia", "russian federation"}},
+	"SA": {"966", "0", 8, 9, []string{"saudi arabia"}},
+	"SE": {"46", "0", 7, 10, []string{"sweden"}},
+	"SG": {"65", "", 8, 8, []string{"singapore"}},
+	"TH": {"66", "0", 8, 9, []string{"thailand"}},
+	"TR": {"90", "0", 10, 10, []string{"turkey", "türkiye"}},
+	"TW": {"886", "0", 8, 9, []string{"taiwan"}},
+	"UA": {"380", "0", 9, 9, []string{"ukraine"}},
+	"US": {"1", "1", 10, 10, []string{"united states", "united states of america", "usa", "us", "america"}},
+	"VN": {"84", "0", 9, 10, []string{"vietnam", "viet nam"}},
+	"ZA": {"27", "0", 9, 9, []string{"south africa"}},
+}
+
+var regionsByName = func() map[string]string {
+	byName := map[string]string{}
+	for code, r := range regions {
+		byName[strings.ToLower(code)] = code
+		for _, name := range r.names {
+			byName[name] = code
+		}
+	}
+	return byName
+}()
+
+var callingCodes = func() map[string]bool {
+	codes := map[string]bool{}
+	for _, r := range regions {
+		codes[r.callingCode] = true
+	}
+	return codes
+}()
+
+// Region resolves an ISO 3166 alpha-2 code or an English country name to a
+// supported region code, or returns "" when it is not known.
+func Region(country string) string {
+	return regionsByName[strings.ToLower(strings.TrimSpace(country))]
+}
+
+// Phone parses a phone number as typed by a user into E.164. Numbers written
+// in international form (+ or 00) need no region; national numbers are read
+// in the given region. An empty input yields an empty result.
+func Phone(raw string, regionCode string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+
+	// Drop an extension, which E.164 cannot carry.
+	lower := strings.ToLower(raw)
+	for _, marker := range []string{"ext", "x", "#", ";"} {
+		if i := strings.Index(lower, marker); i > 0 {
+			raw, lower = raw[:i], lower[:i]
+		}
+	}
+
+	international := strings.HasPrefix(raw, "+")
+	var digits strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+		default:
+			return "", fmt.Errorf("%w: unexpected character %q", ErrInvalidPhone, r)
+		}
+	}
+
+	number := digits.String()
+	if !international && strings.HasPrefix(number, "00") {
+		international = true
+		number = number[2:]
+	}
+
+	if international {
+		return internationalPhone(number)
+	}
+
+	r, ok := regions[strings.ToUpper(regionCode)]
+	if !ok {
+		return "", fmt.Errorf("%w: national number without a known region, use the +<country code> form", ErrInvalidPhone)
+	}
+
+	national := number
+	if r.trunk != "" && strings.HasPrefix(national, r.trunk) && len(national)-len(r.trunk) >= r.minLength {
+		national = national[len(r.trunk):]
+	}
+
+	if len(national) < r.minLength || len(national) > r.maxLength {
+		return "", fmt.Errorf("%w: expected %s digits for region %s", ErrInvalidPhone, lengthRange(r), strings.ToUpper(regionCode))
+	}
+
+	return "+" + r.callingCode + national, nil
+}
+
+// PhonePrefix is Phone for partial input, as typed into a search box: it
+// skips the length checks and returns "" when the input cannot be read.
+func PhonePrefix(raw string, regionCode string) string {
+	var digits strings.Builder
+	for _, r := range raw {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+	if number == "" {
+		return ""
+	}
+
+	trimmed := strings.TrimSpace(raw)
+	if strings.HasPrefix(trimmed, "+") {
+		return "+" + number
+	}
+	if strings.HasPrefix(number, "00") {
+		return "+" + number[2:]
+	}
+
+	r, ok := regions[strings.ToUpper(regionCode)]
+	if !ok || r.trunk == "" || !strings.HasPrefix(number, r.trunk) {
+		return ""
+	}
+	return "+" + r.callingCode + number[len(r.trunk):]
+}
+
+func internationalPhone(number string) (string, error) {
+	if len(number) < 8 || len(number) > 15 {
+		return "", fmt.Errorf("%w: international numbers have 8 to 15 digits", ErrInvalidPhone)
+	}
+
+	for length := 1; length <= 3; length++ {
+		if callingCodes[number[:length]] {
+			return "+" + number, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: unknown country calling code", ErrInvalidPhone)
+}
+
+func lengthRange(r region) string {
+	if r.minLength == r.maxLength {
+		return fmt.Sprint(r.minLength)
+	}
+	return fmt.Sprintf("%d to %d", r.minLength, r.maxLength)
+}
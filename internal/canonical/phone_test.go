@@ -0,0 +1,100 @@
+package canonical
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPhone(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		region  string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", raw: "  ", region: "US", want: ""},
+		{name: "national with trunk prefix", raw: "030 1234567", region: "DE", want: "+49301234567"},
+		{name: "national without trunk prefix", raw: "(415) 555-2671", region: "US", want: "+14155552671"},
+		{name: "national with long distance prefix", raw: "1 415 555 2671", region: "US", want: "+14155552671"},
+		{name: "lowercase region", raw: "020 7946 0958", region: "gb", want: "+442079460958"},
+		{name: "region without trunk prefix", raw: "612 345 678", region: "ES", want: "+34612345678"},
+		{name: "plus prefix ignores region", raw: "+44 20 7946 0958", region: "US", want: "+442079460958"},
+		{name: "plus prefix without region", raw: "+1 (415) 555-2671", want: "+14155552671"},
+		{name: "00 prefix", raw: "0044 20 7946 0958", want: "+442079460958"},
+		{name: "dotted and slashed", raw: "+49.30/1234567", want: "+49301234567"},
+		{name: "extension ext", raw: "+1 415 555 2671 ext. 12", want: "+14155552671"},
+		{name: "extension x", raw: "415-555-2671 x12", region: "US", want: "+14155552671"},
+		{name: "extension hash", raw: "+49 30 1234567#5", want: "+49301234567"},
+		{name: "extension semicolon", raw: "+49 30 1234567;ext=5", want: "+49301234567"},
+		{name: "letters", raw: "call me", region: "US", wantErr: true},
+		{name: "national without region", raw: "030 1234567", wantErr: true},
+		{name: "national with unknown region", raw: "030 1234567", region: "XX", wantErr: true},
+		{name: "national too short", raw: "555 2671", region: "US", wantErr: true},
+		{name: "national too long", raw: "415 555 2671 1", region: "US", wantErr: true},
+		{name: "international too short", raw: "+49 301", wantErr: true},
+		{name: "international too long", raw: "+49 3012 3456 7890 12", wantErr: true},
+		{name: "unknown calling code", raw: "+999 1234 5678", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Phone(tt.raw, tt.region)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPhone) {
+					t.Fatalf("Phone(%q, %q) error = %v, want %v", tt.raw, tt.region, err, ErrInvalidPhone)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Phone(%q, %q) error = %v", tt.raw, tt.region, err)
+			}
+			if got != tt.want {
+				t.Errorf("Phone(%q, %q) = %q, want %q", tt.raw, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhonePrefix(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		want   string
+	}{
+		{raw: "+44 20", want: "+4420"},
+		{raw: " +1 (415", region: "DE", want: "+1415"},
+		{raw: "0044 20", want: "+4420"},
+		{raw: "030 12", region: "DE", want: "+493012"},
+		{raw: "1 415", region: "us", want: "+1415"},
+		{raw: "415", region: "US", want: ""},
+		{raw: "612 34", region: "ES", want: ""},
+		{raw: "030", region: "", want: ""},
+		{raw: "ada", region: "DE", want: ""},
+		{raw: "", region: "DE", want: ""},
+	}
+	for _, tt := range tests {
+		if got := PhonePrefix(tt.raw, tt.region); got != tt.want {
+			t.Errorf("PhonePrefix(%q, %q) = %q, want %q", tt.raw, tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		country string
+		want    string
+	}{
+		{country: "DE", want: "DE"},
+		{country: "de", want: "DE"},
+		{country: " Germany ", want: "DE"},
+		{country: "UK", want: "GB"},
+		{country: "United States of America", want: "US"},
+		{country: "Atlantis", want: ""},
+		{country: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Region(tt.country); got != tt.want {
+			t.Errorf("Region(%q) = %q, want %q", tt.country, got, tt.want)
+		}
+	}
+}
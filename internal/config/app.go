@@ -1,12 +1,16 @@
 package config
 
 import (
+	"context"
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/spf13/viper"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/route"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/job"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
 	"gorm.io/gorm"
@@ -25,26 +29,113 @@ func Bootstrap(config *BootstrapConfig) {
 	userRepository := repository.NewUserRepository(config.Log.App)
 	contactRepository := repository.NewContactRepository(config.Log.App)
 	addressRepository := repository.NewAddressRepository(config.Log.App)
+	userIdentityRepository := repository.NewUserIdentityRepository(config.Log.App)
+	oidcStateRepository := repository.NewOIDCStateRepository(config.Log.App)
+	tagRepository := repository.NewTagRepository(config.Log.App)
+	importJobRepository := repository.NewImportJobRepository(config.Log.App)
+	contactHistoryRepository := repository.NewContactHistoryRepository(config.Log.App)
+	noteRepository := repository.NewNoteRepository(config.Log.App)
+	customFieldRepository := repository.NewCustomFieldRepository(config.Log.App)
+	shareRepository := repository.NewShareRepository(config.Log.App)
+	reminderRepository := repository.NewReminderRepository(config.Log.App)
+
+	blobStore := NewBlobStore(config.Config, config.Log.App)
+	notifier := NewNotifier(config.Config, config.Log.App)
+	geocoder := NewGeocoder(config.Config, config.Log.App)
 
 	// setup use cases
 	userUseCase := usecase.NewUserUseCase(config.DB, config.Log.App, config.Validate, userRepository)
-	contactUseCase := usecase.NewContactUseCase(config.DB, config.Log.App, config.Validate, contactRepository)
-	addressUseCase := usecase.NewAddressUseCase(config.DB, config.Log.App, config.Validate, contactRepository, addressRepository)
+	contactUseCase := usecase.NewContactUseCase(config.DB, config.Log.App, config.Validate, contactRepository, addressRepository, tagRepository,
+		contactHistoryRepository, noteRepository, customFieldRepository, blobStore, config.Config.GetString("phone.default_region"))
+	addressUseCase := usecase.NewAddressUseCase(config.DB, config.Log.App, config.Validate, contactRepository, addressRepository,
+		contactHistoryRepository, geocoder)
+	noteUseCase := usecase.NewNoteUseCase(config.DB, config.Log.App, config.Validate, contactRepository, noteRepository)
+	tagUseCase := usecase.NewTagUseCase(config.DB, config.Log.App, config.Validate, tagRepository, contactRepository,
+		contactHistoryRepository)
+	customFieldUseCase := usecase.NewCustomFieldUseCase(config.DB, config.Log.App, config.Validate, customFieldRepository, contactRepository,
+		contactHistoryRepository)
+	shareUseCase := usecase.NewShareUseCase(config.DB, config.Log.App, config.Validate, shareRepository, contactRepository,
+		tagRepository, userRepository)
+	photoUseCase := usecase.NewPhotoUseCase(config.DB, config.Log.App, config.Validate, contactRepository, contactHistoryRepository,
+		blobStore, config.Config.GetInt64("photo.max_size"))
+	calendarUseCase := usecase.NewCalendarUseCase(config.DB, config.Log.App, config.Validate, userRepository, contactRepository)
+	reminderUseCase := usecase.NewReminderUseCase(config.DB, config.Log.App, contactRepository, reminderRepository, notifier,
+		config.Config.GetInt("reminder.days_ahead"))
+	trashUseCase := usecase.NewTrashUseCase(config.DB, config.Log.App, contactRepository, addressRepository, blobStore)
+	importUseCase := usecase.NewImportUseCase(config.DB, config.Log.App, config.Validate, contactRepository, addressRepository,
+		contactHistoryRepository, customFieldRepository, importJobRepository, geocoder, config.Config.GetInt("import.async_threshold"), config.Config.GetInt("import.max_rows"),
+		config.Config.GetString("phone.default_region"))
 
 	// setup controller
 	userController := http.NewUserController(userUseCase, config.Log.App)
 	contactController := http.NewContactController(contactUseCase, config.Log.App)
 	addressController := http.NewAddressController(addressUseCase, config.Log.App)
+	tagController := http.NewTagController(tagUseCase, config.Log.App)
+	importController := http.NewImportController(importUseCase, config.Log.App)
+	noteController := http.NewNoteController(noteUseCase, config.Log.App)
+	customFieldController := http.NewCustomFieldController(customFieldUseCase, config.Log.App)
+	shareController := http.NewShareController(shareUseCase, config.Log.App)
+	photoController := http.NewPhotoController(photoUseCase, config.Log.App)
+	calendarController := http.NewCalendarController(calendarUseCase, config.Log.App)
+	userV2Controller := http.NewUserV2Controller(userUseCase, config.Log.App)
+	contactV2Controller := http.NewContactV2Controller(contactUseCase, config.Log.App)
+	addressV2Controller := http.NewAddressV2Controller(addressUseCase, config.Log.App)
+	tagV2Controller := http.NewTagV2Controller(tagUseCase, config.Log.App)
+	graphQLController := http.NewGraphQLController(userUseCase, contactUseCase, addressUseCase, config.Log.App,
+		config.Config.GetInt64("graphql.max_body_size"), config.Config.GetInt("graphql.max_depth"), config.Config.GetInt("graphql.max_complexity"))
+	document := route.OpenAPI()
+	openAPIController := http.NewOpenAPIController(document, config.Log.App)
+
+	var oidcController *http.OIDCController
+	if oidcClient := NewOIDCClient(config.Config); oidcClient != nil {
+		oidcUseCase := usecase.NewOIDCUseCase(config.DB, config.Log.App, config.Validate, oidcClient,
+			config.Config.GetBool("oidc.link_existing"), userRepository, userIdentityRepository, oidcStateRepository)
+		oidcController = http.NewOIDCController(oidcUseCase, config.Log.App)
+	}
 
 	// setup middleware
 	authMiddleware := middleware.NewAuth(userUseCase)
+	requestIdMiddleware := middleware.NewRequestId()
+	openAPIMiddleware := NewOpenAPIValidation(config.Config, config.Log.App, document)
+	v1Middleware := NewV1Deprecation(config.Config, config.Log.App, route.V2Prefix)
 
 	routeConfig := route.RouteConfig{
-		App:               config.App,
-		UserController:    userController,
-		ContactController: contactController,
-		AddressController: addressController,
-		AuthMiddleware:    authMiddleware,
+		App:                   config.App,
+		UserController:        userController,
+		ContactController:     contactController,
+		AddressController:     addressController,
+		TagController:         tagController,
+		ImportController:      importController,
+		NoteController:        noteController,
+		CustomFieldController: customFieldController,
+		ShareController:       shareController,
+		PhotoController:       photoController,
+		CalendarController:    calendarController,
+		OIDCController:        oidcController,
+		OpenAPIController:     openAPIController,
+		UserV2Controller:      userV2Controller,
+		ContactV2Controller:   contactV2Controller,
+		AddressV2Controller:   addressV2Controller,
+		TagV2Controller:       tagV2Controller,
+		GraphQLController:     graphQLController,
+		AuthMiddleware:        authMiddleware,
+		RequestIdMiddleware:   requestIdMiddleware,
+		OpenAPIMiddleware:     openAPIMiddleware,
+		V1Middleware:          v1Middleware,
 	}
 	routeConfig.Setup()
+
+	// setup jobs
+	if retentionDays := config.Config.GetInt("trash.retention_days"); retentionDays > 0 {
+		trashPurgeJob := job.NewTrashPurgeJob(trashUseCase, config.Log.App,
+			time.Duration(retentionDays)*24*time.Hour,
+			time.Duration(config.Config.GetInt("trash.purge_interval"))*time.Second)
+		go trashPurgeJob.Run(context.Background())
+	}
+
+	if config.Config.GetBool("reminder.enabled") {
+		reminderJob := job.NewReminderJob(reminderUseCase, config.Log.App,
+			time.Duration(config.Config.GetInt("reminder.interval"))*time.Second)
+		go reminderJob.Run(context.Background())
+	}
 }
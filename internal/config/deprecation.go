@@ -0,0 +1,45 @@
+package config
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"go.uber.org/zap"
+)
+
+// NewV1Deprecation is the middleware that announces the deprecation of v1 of
+// the API, or nil while neither api.v1.deprecated_at nor api.v1.sunset_at is
+// set. Both are RFC 3339 timestamps.
+func NewV1Deprecation(viper *viper.Viper, log *zap.Logger, successor string) echo.MiddlewareFunc {
+	deprecatedAt := parseConfigTime(viper, log, "api.v1.deprecated_at")
+	sunsetAt := parseConfigTime(viper, log, "api.v1.sunset_at")
+	if deprecatedAt.IsZero() && sunsetAt.IsZero() {
+		return nil
+	}
+	return middleware.NewDeprecation(deprecatedAt, sunsetAt, successor)
+}
+
+// parseConfigTime reads an RFC 3339 timestamp, which YAML may already have
+// parsed when it was left unquoted.
+func parseConfigTime(viper *viper.Viper, log *zap.Logger, key string) time.Time {
+	switch value := viper.Get(key).(type) {
+	case nil:
+		return time.Time{}
+	case time.Time:
+		return value
+	case string:
+		if value == "" {
+			return time.Time{}
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			log.Fatal("invalid timestamp in config", zap.String("key", key), zap.Error(err))
+		}
+		return parsed
+	default:
+		log.Fatal("invalid timestamp in config", zap.String("key", key), zap.Any("value", value))
+		return time.Time{}
+	}
+}
@@ -1,26 +1,103 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
 	"github.com/labstack/echo/v4"
 	"github.com/spf13/viper"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"go.uber.org/zap"
 )
 
-func NewEcho(config *viper.Viper) *echo.Echo {
+const problemContentType = "application/problem+json"
+
+var problemStatus = map[apperror.Kind]int{
+	apperror.KindValidation:   http.StatusBadRequest,
+	apperror.KindNotFound:     http.StatusNotFound,
+	apperror.KindConflict:     http.StatusConflict,
+	apperror.KindUnauthorized: http.StatusUnauthorized,
+	apperror.KindForbidden:    http.StatusForbidden,
+	apperror.KindPrecondition: http.StatusPreconditionFailed,
+	apperror.KindTooLarge:     http.StatusRequestEntityTooLarge,
+	apperror.KindUnsupported:  http.StatusUnsupportedMediaType,
+	apperror.KindUpstream:     http.StatusBadGateway,
+	apperror.KindInternal:     http.StatusInternalServerError,
+}
+
+func NewEcho(config *viper.Viper, log *zap.Logger) *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = NewErrorHandler(log)
 	return e
 }
 
-func NewErrorHandler() echo.HTTPErrorHandler {
+// NewErrorHandler writes every failed request as application/problem+json.
+// Use cases fail with apperror.Error, while echo and the delivery layer may
+// still fail with echo.HTTPError; anything else is a 500 whose cause is only
+// logged.
+func NewErrorHandler(log *zap.Logger) echo.HTTPErrorHandler {
 	return func(err error, ctx echo.Context) {
-		if he, ok := err.(*echo.HTTPError); ok {
-			ctx.JSON(he.Code, map[string]string{
-				"error": he.Message.(string),
-			})
+		problem := newProblem(err)
+		problem.Instance = ctx.Request().URL.Path
+
+		logger := log.With(zap.Error(err), zap.Int("status", problem.Status), zap.String("path", problem.Instance))
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("request failed")
 		} else {
-			ctx.JSON(500, map[string]string{
-				"error": "Internal Server Error",
-			})
+			logger.Debug("request failed")
+		}
+
+		if ctx.Response().Committed {
+			return
+		}
+		if ctx.Request().Method == http.MethodHead {
+			err = ctx.NoContent(problem.Status)
+		} else {
+			ctx.Response().Header().Set(echo.HeaderContentType, problemContentType)
+			ctx.Response().WriteHeader(problem.Status)
+			err = json.NewEncoder(ctx.Response()).Encode(problem)
+		}
+		if err != nil {
+			log.With(zap.Error(err)).Error("failed to write error response")
+		}
+	}
+}
+
+func newProblem(err error) dto.ProblemResponse {
+	if appErr, ok := apperror.As(err); ok {
+		status, ok := problemStatus[appErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
 		}
-		ctx.Logger().Error(err)
+		problem := problemFor(status, string(appErr.Kind))
+		if status != http.StatusInternalServerError {
+			problem.Detail = appErr.Message
+			problem.Errors = appErr.Fields
+		}
+		return problem
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		problem := problemFor(httpErr.Code, "")
+		if message, ok := httpErr.Message.(string); ok && message != http.StatusText(httpErr.Code) {
+			problem.Detail = message
+		}
+		return problem
+	}
+
+	return problemFor(http.StatusInternalServerError, string(apperror.KindInternal))
+}
+
+// problemFor is the bare problem for a status. Without a kind, the code is
+// derived from the status text, such as "method_not_allowed".
+func problemFor(status int, code string) dto.ProblemResponse {
+	title := http.StatusText(status)
+	if code == "" {
+		code = strings.ReplaceAll(strings.ToLower(title), " ", "_")
 	}
+	return dto.ProblemResponse{Type: "about:blank", Title: title, Status: status, Code: code}
 }
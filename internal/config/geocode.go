@@ -0,0 +1,24 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/geocode"
+	"go.uber.org/zap"
+)
+
+func NewGeocoder(viper *viper.Viper, log *zap.Logger) geocode.Geocoder {
+	switch driver := viper.GetString("geocode.driver"); driver {
+	case "", "offline":
+		return geocode.NewOfflineGeocoder()
+	case "http":
+		// Unset, the rate is the one OpenStreetMap's Nominatim allows.
+		requestsPerSecond := viper.GetFloat64("geocode.http.requests_per_second")
+		if requestsPerSecond <= 0 {
+			requestsPerSecond = 1
+		}
+		return geocode.NewHTTPGeocoder(viper.GetString("geocode.http.url"), viper.GetString("geocode.http.user_agent"), requestsPerSecond)
+	default:
+		log.Fatal("unknown geocode driver", zap.String("driver", driver))
+		return nil
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/notify"
+	"go.uber.org/zap"
+)
+
+func NewNotifier(viper *viper.Viper, log *zap.Logger) notify.Notifier {
+	switch driver := viper.GetString("notify.driver"); driver {
+	case "", "log":
+		return notify.NewLogNotifier(log)
+	case "webhook":
+		url := viper.GetString("notify.webhook.url")
+		if url == "" {
+			log.Fatal("notify.webhook.url is required for the webhook notifier")
+		}
+		return notify.NewWebhookNotifier(url, viper.GetString("notify.webhook.secret"))
+	default:
+		log.Fatal("unknown notify driver", zap.String("driver", driver))
+		return nil
+	}
+}
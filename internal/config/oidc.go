@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/oidc"
+)
+
+func NewOIDCClient(viper *viper.Viper) *oidc.Client {
+	if !viper.GetBool("oidc.enabled") {
+		return nil
+	}
+
+	return oidc.NewClient(oidc.Config{
+		Issuer:       viper.GetString("oidc.issuer"),
+		ClientID:     viper.GetString("oidc.client_id"),
+		ClientSecret: viper.GetString("oidc.client_secret"),
+		RedirectURL:  viper.GetString("oidc.redirect_url"),
+		Scopes:       viper.GetStringSlice("oidc.scopes"),
+	})
+}
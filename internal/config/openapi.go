@@ -0,0 +1,24 @@
+package config
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/route"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/openapi"
+	"go.uber.org/zap"
+)
+
+// NewOpenAPIValidation is the middleware that holds traffic to the document,
+// or nil when openapi.validation is off.
+func NewOpenAPIValidation(viper *viper.Viper, log *zap.Logger, document *openapi.Document) echo.MiddlewareFunc {
+	switch mode := viper.GetString("openapi.validation"); mode {
+	case "", "off":
+		return nil
+	case "log", "reject":
+		return middleware.NewOpenAPIValidation(openapi.NewValidator(document, route.Aliases), mode == "reject", log)
+	default:
+		log.Fatal("unknown openapi validation mode", zap.String("mode", mode))
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/storage"
+	"go.uber.org/zap"
+)
+
+func NewBlobStore(viper *viper.Viper, log *zap.Logger) storage.BlobStore {
+	switch driver := viper.GetString("storage.driver"); driver {
+	case "", "local":
+		root := viper.GetString("storage.local.root")
+		if root == "" {
+			root = "data/blobs"
+		}
+		return storage.NewLocalStore(root)
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  viper.GetString("storage.s3.endpoint"),
+			Bucket:    viper.GetString("storage.s3.bucket"),
+			Region:    viper.GetString("storage.s3.region"),
+			AccessKey: viper.GetString("storage.s3.access_key"),
+			SecretKey: viper.GetString("storage.s3.secret_key"),
+		})
+	default:
+		log.Fatal("unknown storage driver", zap.String("driver", driver))
+		return nil
+	}
+}
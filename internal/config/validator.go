@@ -1,10 +1,33 @@
 package config
 
 import (
+	"reflect"
+	"regexp"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
 func NewValidator(viper *viper.Viper) *validator.Validate {
-	return validator.New()
-}
\ No newline at end of file
+	validate := validator.New()
+
+	// Report fields by their JSON name so errors match what clients send.
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
+	// field_name keeps user-chosen keys usable as JSON members and in query
+	// parameter names.
+	validate.RegisterValidation("field_name", func(field validator.FieldLevel) bool {
+		return fieldNamePattern.MatchString(field.Field().String())
+	})
+
+	return validate
+}
@@ -40,25 +40,47 @@ func (c *AddressController) Create(ctx echo.Context) error {
 		return err
 	}
 
+	ctx.Response().Header().Set("ETag", etag(response.Version))
 	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.AddressResponse]{Data: response})
 }
 
 func (c *AddressController) List(ctx echo.Context) error {
 	auth := middleware.GetUser(ctx)
 	contactId := ctx.Param("contactId")
+	paging := parsePagingQuery(ctx)
+
+	fields, err := parseFields[dto.AddressResponse](ctx)
+	if err != nil {
+		return err
+	}
 
 	request := &dto.ListAddressRequest{
-		UserId:    auth.ID,
-		ContactId: contactId,
+		UserId:       auth.ID,
+		ContactId:    contactId,
+		Sort:         paging.Sort,
+		After:        paging.After,
+		Before:       paging.Before,
+		IncludeTotal: paging.IncludeTotal,
+		Page:         paging.Page,
+		Size:         paging.Size,
 	}
 
-	responses, err := c.UseCase.List(ctx.Request().Context(), request)
+	responses, metadata, err := c.UseCase.List(ctx.Request().Context(), request)
 	if err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to list addresses")
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.AddressResponse]{Data: responses})
+	data, err := fields.pick(responses)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to pick address fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{
+		Data:   data,
+		Paging: metadata,
+	})
 }
 
 func (c *AddressController) Get(ctx echo.Context) error {
@@ -66,6 +88,11 @@ func (c *AddressController) Get(ctx echo.Context) error {
 	contactId := ctx.Param("contactId")
 	addressId := ctx.Param("addressId")
 
+	fields, err := parseFields[dto.AddressResponse](ctx)
+	if err != nil {
+		return err
+	}
+
 	request := &dto.GetAddressRequest{
 		UserId:    auth.ID,
 		ContactId: contactId,
@@ -78,7 +105,18 @@ func (c *AddressController) Get(ctx echo.Context) error {
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.AddressResponse]{Data: response})
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	if notModified(ctx, response.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	data, err := fields.pick(response)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to pick address fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: data})
 }
 
 func (c *AddressController) Update(ctx echo.Context) error {
@@ -90,9 +128,15 @@ func (c *AddressController) Update(ctx echo.Context) error {
 		return echo.ErrBadRequest
 	}
 
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
 	request.UserId = auth.ID
 	request.ContactId = ctx.Param("contactId")
 	request.ID = ctx.Param("addressId")
+	request.IfMatch = ifMatch
 
 	response, err := c.UseCase.Update(ctx.Request().Context(), request)
 	if err != nil {
@@ -100,6 +144,83 @@ func (c *AddressController) Update(ctx echo.Context) error {
 		return err
 	}
 
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.AddressResponse]{Data: response})
+}
+
+func (c *AddressController) Patch(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	format, patch, err := readPatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.PatchAddressRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("addressId"),
+		IfMatch:   ifMatch,
+		Format:    format,
+		Patch:     patch,
+	}
+
+	response, err := c.UseCase.Patch(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to patch address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.AddressResponse]{Data: response})
+}
+
+func (c *AddressController) SetPrimary(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.SetPrimaryAddressRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("addressId"),
+		IfMatch:   ifMatch,
+	}
+
+	response, err := c.UseCase.SetPrimary(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to set primary address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.AddressResponse]{Data: response})
+}
+
+func (c *AddressController) Restore(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.RestoreAddressRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("addressId"),
+	}
+
+	response, err := c.UseCase.Restore(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to restore address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
 	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.AddressResponse]{Data: response})
 }
 
@@ -108,10 +229,16 @@ func (c *AddressController) Delete(ctx echo.Context) error {
 	contactId := ctx.Param("contactId")
 	addressId := ctx.Param("addressId")
 
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
 	request := &dto.DeleteAddressRequest{
 		UserId:    auth.ID,
 		ContactId: contactId,
 		ID:        addressId,
+		IfMatch:   ifMatch,
 	}
 
 	if err := c.UseCase.Delete(ctx.Request().Context(), request); err != nil {
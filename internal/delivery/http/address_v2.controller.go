@@ -0,0 +1,227 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type AddressV2Controller struct {
+	UseCase *usecase.AddressUseCase
+	Log     *zap.Logger
+}
+
+func NewAddressV2Controller(useCase *usecase.AddressUseCase, log *zap.Logger) *AddressV2Controller {
+	return &AddressV2Controller{
+		Log:     log,
+		UseCase: useCase,
+	}
+}
+
+func (c *AddressV2Controller) Create(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.CreateAddressRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to parse request body")
+		return echo.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	request.ContactId = ctx.Param("contactId")
+
+	response, err := c.UseCase.Create(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to create address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.AddressResponseV2]{Data: converter.AddressResponseToV2(response)})
+}
+
+func (c *AddressV2Controller) List(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	fields, err := parseFields[dto.AddressResponseV2](ctx)
+	if err != nil {
+		return err
+	}
+
+	paging, err := parseCursorQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.ListAddressRequest{
+		UserId:       auth.ID,
+		ContactId:    ctx.Param("contactId"),
+		Sort:         paging.Sort,
+		After:        paging.After,
+		Before:       paging.Before,
+		IncludeTotal: paging.IncludeTotal,
+		Page:         paging.Page,
+		Size:         paging.Size,
+	}
+
+	responses, metadata, err := c.UseCase.List(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to list addresses")
+		return err
+	}
+
+	data, err := fields.pick(converter.AddressResponsesToV2(responses))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to pick address fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[any]{
+		Data:   data,
+		Paging: converter.PageMetadataToV2(metadata),
+	})
+}
+
+func (c *AddressV2Controller) Get(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	fields, err := parseFields[dto.AddressResponseV2](ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.GetAddressRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("addressId"),
+	}
+
+	response, err := c.UseCase.Get(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to get address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	if notModified(ctx, response.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	data, err := fields.pick(converter.AddressResponseToV2(response))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to pick address fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[any]{Data: data})
+}
+
+func (c *AddressV2Controller) Update(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.UpdateAddressRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to parse request body")
+		return echo.ErrBadRequest
+	}
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request.UserId = auth.ID
+	request.ContactId = ctx.Param("contactId")
+	request.ID = ctx.Param("addressId")
+	request.IfMatch = ifMatch
+
+	response, err := c.UseCase.Update(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to update address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.AddressResponseV2]{Data: converter.AddressResponseToV2(response)})
+}
+
+func (c *AddressV2Controller) Patch(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	format, patch, err := readPatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.PatchAddressRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("addressId"),
+		IfMatch:   ifMatch,
+		Format:    format,
+		Patch:     patch,
+	}
+
+	response, err := c.UseCase.Patch(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to patch address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.AddressResponseV2]{Data: converter.AddressResponseToV2(response)})
+}
+
+func (c *AddressV2Controller) Restore(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.RestoreAddressRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("addressId"),
+	}
+
+	response, err := c.UseCase.Restore(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to restore address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.AddressResponseV2]{Data: converter.AddressResponseToV2(response)})
+}
+
+func (c *AddressV2Controller) SetPrimary(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.SetPrimaryAddressRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("addressId"),
+		IfMatch:   ifMatch,
+	}
+
+	response, err := c.UseCase.SetPrimary(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to set primary address")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.AddressResponseV2]{Data: converter.AddressResponseToV2(response)})
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/ical"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type CalendarController struct {
+	Log     *zap.Logger
+	UseCase *usecase.CalendarUseCase
+}
+
+func NewCalendarController(useCase *usecase.CalendarUseCase, logger *zap.Logger) *CalendarController {
+	return &CalendarController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *CalendarController) CreateToken(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.CreateCalendarTokenRequest{
+		UserId: auth.ID,
+	}
+
+	response, err := c.UseCase.CreateToken(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating calendar token")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.CalendarTokenResponse]{Data: response})
+}
+
+func (c *CalendarController) DeleteToken(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.DeleteCalendarTokenRequest{
+		UserId: auth.ID,
+	}
+
+	response, err := c.UseCase.DeleteToken(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting calendar token")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[bool]{Data: response})
+}
+
+// Feed serves the calendar to subscribing apps, which cannot send an
+// Authorization header; the secret token in the URL stands in for it.
+func (c *CalendarController) Feed(ctx echo.Context) error {
+	request := &dto.CalendarFeedRequest{
+		Token: ctx.QueryParam("token"),
+	}
+
+	response, err := c.UseCase.Feed(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error getting calendar feed")
+		return err
+	}
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentType, "text/calendar; charset=utf-8")
+	header.Set(echo.HeaderContentDisposition, `inline; filename="contacts.ics"`)
+	header.Set("Cache-Control", "private, max-age=3600")
+	ctx.Response().WriteHeader(http.StatusOK)
+
+	w := ical.NewWriter(ctx.Response())
+	w.Begin(response.Name)
+	for _, event := range response.Events {
+		w.Write(&ical.Event{
+			UID:         event.UID,
+			Summary:     event.Summary,
+			Description: event.Description,
+			Start:       event.Start,
+			Yearly:      true,
+		})
+	}
+	return w.End()
+}
@@ -1,14 +1,18 @@
 package http
 
 import (
+	"bytes"
+	"fmt"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/vcard"
 	"go.uber.org/zap"
 )
 
@@ -40,64 +44,217 @@ func (c *ContactController) Create(ctx echo.Context) error {
 		return err
 	}
 
+	ctx.Response().Header().Set("ETag", etag(response.Version))
 	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
 }
 
 func (c *ContactController) List(ctx echo.Context) error {
-	auth := middleware.GetUser(ctx)
-
-	page, _ := strconv.Atoi(ctx.QueryParam("page"))
-	if page == 0 {
-		page = 1
-	}
-
-	size, _ := strconv.Atoi(ctx.QueryParam("size"))
-	if size == 0 {
-		size = 10
+	fields, err := parseFields[dto.ContactResponse](ctx)
+	if err != nil {
+		return err
 	}
 
-	request := &dto.SearchContactRequest{
-		UserId: auth.ID,
-		Name:   ctx.QueryParam("name"),
-		Email:  ctx.QueryParam("email"),
-		Phone:  ctx.QueryParam("phone"),
-		Page:   page,
-		Size:   size,
+	request, err := parseSearchContactQuery(ctx, parsePagingQuery(ctx))
+	if err != nil {
+		return err
 	}
 
-	responses, total, err := c.UseCase.Search(ctx.Request().Context(), request)
+	responses, metadata, err := c.UseCase.Search(ctx.Request().Context(), request)
 	if err != nil {
 		c.Log.With(zap.Error(err)).Error("error searching contact")
 		return err
 	}
 
-	paging := &dto.PageMetadata{
-		Page:      request.Page,
-		Size:      request.Size,
-		TotalItem: total,
-		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	data, err := fields.pick(responses)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error picking contact fields")
+		return echo.ErrInternalServerError
 	}
 
-	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.ContactResponse]{
-		Data:   responses,
-		Paging: paging,
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{
+		Data:   data,
+		Paging: metadata,
 	})
 }
 
 func (c *ContactController) Get(ctx echo.Context) error {
 	auth := middleware.GetUser(ctx)
 
+	// The router cannot split "/contacts/:contactId.vcf" from the plain route,
+	// so the suffix is handled here.
+	if contactId, ok := strings.CutSuffix(ctx.Param("contactId"), ".vcf"); ok {
+		return c.exportOne(ctx, &dto.GetContactRequest{UserId: auth.ID, ID: contactId, Expand: []string{"addresses"}})
+	}
+
+	fields, err := parseFields[dto.ContactResponse](ctx)
+	if err != nil {
+		return err
+	}
+
 	request := &dto.GetContactRequest{
 		UserId: auth.ID,
 		ID:     ctx.Param("contactId"),
+		Expand: parseList(ctx, "expand"),
+	}
+
+	response, err := c.UseCase.Get(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	if notModified(ctx, response.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	data, err := fields.pick(response)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error picking contact fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: data})
+}
+
+func (c *ContactController) Export(ctx echo.Context) error {
+	filter, err := parseContactFilterQuery(ctx)
+	if err != nil {
+		return err
 	}
 
+	request := &dto.ExportContactRequest{
+		UserId:       filter.UserId,
+		Format:       ctx.QueryParam("format"),
+		Query:        filter.Query,
+		Name:         filter.Name,
+		Email:        filter.Email,
+		Phone:        filter.Phone,
+		Tags:         filter.Tags,
+		TagMode:      filter.TagMode,
+		CustomFields: filter.CustomFields,
+		Near:         filter.Near,
+		RadiusKm:     filter.RadiusKm,
+	}
+	if request.Format == "" {
+		request.Format = "vcf"
+	}
+
+	response := ctx.Response()
+	exporter := newContactExporter(request.Format, response)
+
+	// Headers are only sent with the first contact, so validation and query
+	// errors can still produce a regular error response.
+	begin := func() error {
+		response.Header().Set(echo.HeaderContentType, exporter.ContentType())
+		response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "contacts."+request.Format))
+		response.WriteHeader(http.StatusOK)
+		return exporter.Begin()
+	}
+
+	err = c.UseCase.Export(ctx.Request().Context(), request, func(contact *dto.ContactResponse) error {
+		if !response.Committed {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		return exporter.Write(contact)
+	})
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error exporting contacts")
+		return err
+	}
+
+	if !response.Committed {
+		if err := begin(); err != nil {
+			return err
+		}
+	}
+
+	return exporter.End()
+}
+
+func (c *ContactController) exportOne(ctx echo.Context, request *dto.GetContactRequest) error {
 	response, err := c.UseCase.Get(ctx.Request().Context(), request)
 	if err != nil {
 		c.Log.With(zap.Error(err)).Error("error getting contact")
 		return err
 	}
 
+	var body bytes.Buffer
+	if err := vcard.NewWriter(&body).Write(contactToCard(response)); err != nil {
+		c.Log.With(zap.Error(err)).Error("error writing vcard")
+		return echo.ErrInternalServerError
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", response.ID+".vcf"))
+	return ctx.Blob(http.StatusOK, "text/vcard; charset=utf-8", body.Bytes())
+}
+
+func (c *ContactController) Duplicates(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.FindDuplicateContactRequest{
+		UserId:   auth.ID,
+		MinScore: 0.5,
+	}
+	if minScore := ctx.QueryParam("min_score"); minScore != "" {
+		value, err := strconv.ParseFloat(minScore, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "min_score must be a number")
+		}
+		request.MinScore = value
+	}
+
+	responses, err := c.UseCase.FindDuplicates(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error finding duplicate contacts")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.DuplicateGroupResponse]{Data: responses})
+}
+
+func (c *ContactController) Upcoming(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.UpcomingDateRequest{
+		UserId: auth.ID,
+		Days:   30,
+	}
+	if days := ctx.QueryParam("days"); days != "" {
+		value, err := strconv.Atoi(days)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "days must be a whole number")
+		}
+		request.Days = value
+	}
+
+	responses, err := c.UseCase.Upcoming(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error listing upcoming dates")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.UpcomingDateResponse]{Data: responses})
+}
+
+func (c *ContactController) Merge(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.MergeContactRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Merge(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error merging contacts")
+		return err
+	}
+
 	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
 }
 
@@ -110,8 +267,14 @@ func (c *ContactController) Update(ctx echo.Context) error {
 		return echo.ErrBadRequest
 	}
 
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
 	request.UserId = auth.ID
 	request.ID = ctx.Param("contactId")
+	request.IfMatch = ifMatch
 
 	response, err := c.UseCase.Update(ctx.Request().Context(), request)
 	if err != nil {
@@ -119,6 +282,38 @@ func (c *ContactController) Update(ctx echo.Context) error {
 		return err
 	}
 
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
+}
+
+func (c *ContactController) Patch(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	format, patch, err := readPatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.PatchContactRequest{
+		UserId:  auth.ID,
+		ID:      ctx.Param("contactId"),
+		IfMatch: ifMatch,
+		Format:  format,
+		Patch:   patch,
+	}
+
+	response, err := c.UseCase.Patch(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error patching contact")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
 	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
 }
 
@@ -126,9 +321,15 @@ func (c *ContactController) Delete(ctx echo.Context) error {
 	auth := middleware.GetUser(ctx)
 	contactId := ctx.Param("contactId")
 
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
 	request := &dto.DeleteContactRequest{
-		UserId: auth.ID,
-		ID:     contactId,
+		UserId:  auth.ID,
+		ID:      contactId,
+		IfMatch: ifMatch,
 	}
 
 	if err := c.UseCase.Delete(ctx.Request().Context(), request); err != nil {
@@ -138,3 +339,257 @@ func (c *ContactController) Delete(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, dto.WebResponse[bool]{Data: true})
 }
+
+func (c *ContactController) Trash(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+	paging := parsePagingQuery(ctx)
+
+	request := &dto.SearchTrashContactRequest{
+		UserId:       auth.ID,
+		Sort:         paging.Sort,
+		After:        paging.After,
+		Before:       paging.Before,
+		IncludeTotal: paging.IncludeTotal,
+		Page:         paging.Page,
+		Size:         paging.Size,
+	}
+
+	responses, metadata, err := c.UseCase.SearchTrash(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error listing trashed contacts")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.ContactResponse]{
+		Data:   responses,
+		Paging: metadata,
+	})
+}
+
+func (c *ContactController) SharedWithMe(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+	paging := parsePagingQuery(ctx)
+
+	request := &dto.SearchSharedContactRequest{
+		UserId:       auth.ID,
+		Sort:         paging.Sort,
+		After:        paging.After,
+		Before:       paging.Before,
+		IncludeTotal: paging.IncludeTotal,
+		Page:         paging.Page,
+		Size:         paging.Size,
+	}
+
+	responses, metadata, err := c.UseCase.SearchShared(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error listing shared contacts")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.SharedContactResponse]{
+		Data:   responses,
+		Paging: metadata,
+	})
+}
+
+func (c *ContactController) Restore(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.RestoreContactRequest{
+		UserId: auth.ID,
+		ID:     ctx.Param("contactId"),
+	}
+
+	response, err := c.UseCase.Restore(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error restoring contact")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
+}
+
+func (c *ContactController) History(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+	paging := parsePagingQuery(ctx)
+
+	request := &dto.ListContactHistoryRequest{
+		UserId:       auth.ID,
+		ContactId:    ctx.Param("contactId"),
+		Sort:         paging.Sort,
+		After:        paging.After,
+		Before:       paging.Before,
+		IncludeTotal: paging.IncludeTotal,
+		Page:         paging.Page,
+		Size:         paging.Size,
+	}
+
+	responses, metadata, err := c.UseCase.History(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error listing contact history")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.ContactHistoryResponse]{
+		Data:   responses,
+		Paging: metadata,
+	})
+}
+
+func (c *ContactController) Revert(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	version, err := strconv.ParseInt(ctx.Param("version"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "version must be a number")
+	}
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.RevertContactRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		Version:   version,
+		IfMatch:   ifMatch,
+	}
+
+	response, err := c.UseCase.Revert(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error restoring contact version")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
+}
+
+func (c *ContactController) AddTag(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.ContactTagRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		TagId:     ctx.Param("tagId"),
+	}
+
+	response, err := c.UseCase.AddTag(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error adding contact tag")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
+}
+
+func (c *ContactController) RemoveTag(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.ContactTagRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		TagId:     ctx.Param("tagId"),
+	}
+
+	response, err := c.UseCase.RemoveTag(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error removing contact tag")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
+}
+
+// parseSearchContactQuery reads a contact search from the query string. Every
+// version of the API searches alike; only paging differs between them.
+func parseSearchContactQuery(ctx echo.Context, paging pagingQuery) (*dto.SearchContactRequest, error) {
+	request, err := parseContactFilterQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Highlight, _ = strconv.ParseBool(ctx.QueryParam("highlight"))
+	request.Expand = parseList(ctx, "expand")
+	request.Sort = paging.Sort
+	request.After = paging.After
+	request.Before = paging.Before
+	request.IncludeTotal = paging.IncludeTotal
+	request.Page = paging.Page
+	request.Size = paging.Size
+	return request, nil
+}
+
+// parseContactFilterQuery reads the filters of a contact search, which the
+// export shares with the list.
+func parseContactFilterQuery(ctx echo.Context) (*dto.SearchContactRequest, error) {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.SearchContactRequest{
+		UserId:       auth.ID,
+		Query:        ctx.QueryParam("q"),
+		Name:         ctx.QueryParam("name"),
+		Email:        ctx.QueryParam("email"),
+		Phone:        ctx.QueryParam("phone"),
+		Tags:         ctx.QueryParams()["tag"],
+		TagMode:      ctx.QueryParam("tag_mode"),
+		CustomFields: parseCustomFieldQuery(ctx),
+	}
+
+	if near := ctx.QueryParam("near"); near != "" {
+		point, err := parseGeoPoint(near)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "near must be a latitude and longitude, as in near=52.52,13.405")
+		}
+		request.Near = point
+		request.RadiusKm = defaultRadiusKm
+		if radius := ctx.QueryParam("radius_km"); radius != "" {
+			value, err := strconv.ParseFloat(radius, 64)
+			if err != nil || math.IsNaN(value) {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "radius_km must be a number")
+			}
+			request.RadiusKm = value
+		}
+	}
+
+	return request, nil
+}
+
+// parseCustomFieldQuery collects cf.<name>=<value> query parameters, which
+// filter contacts by their custom fields.
+func parseCustomFieldQuery(ctx echo.Context) map[string]string {
+	var fields map[string]string
+	for key, values := range ctx.QueryParams() {
+		name, ok := strings.CutPrefix(key, "cf.")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		fields[name] = values[0]
+	}
+	return fields
+}
+
+// defaultRadiusKm is how far a near search reaches without radius_km.
+const defaultRadiusKm = 10
+
+// parseGeoPoint reads a "latitude,longitude" query parameter.
+func parseGeoPoint(value string) (*dto.GeoPoint, error) {
+	latitude, longitude, ok := strings.Cut(value, ",")
+	if !ok {
+		return nil, fmt.Errorf("missing comma in %q", value)
+	}
+
+	point := new(dto.GeoPoint)
+	var err error
+	if point.Latitude, err = strconv.ParseFloat(strings.TrimSpace(latitude), 64); err != nil {
+		return nil, err
+	}
+	if point.Longitude, err = strconv.ParseFloat(strings.TrimSpace(longitude), 64); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
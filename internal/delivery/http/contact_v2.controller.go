@@ -0,0 +1,171 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type ContactV2Controller struct {
+	UseCase *usecase.ContactUseCase
+	Log     *zap.Logger
+}
+
+func NewContactV2Controller(useCase *usecase.ContactUseCase, log *zap.Logger) *ContactV2Controller {
+	return &ContactV2Controller{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *ContactV2Controller) Create(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.CreateContactRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Create(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating contact")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.ContactResponseV2]{Data: converter.ContactResponseToV2(response)})
+}
+
+func (c *ContactV2Controller) List(ctx echo.Context) error {
+	fields, err := parseFields[dto.ContactResponseV2](ctx)
+	if err != nil {
+		return err
+	}
+
+	paging, err := parseCursorQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	request, err := parseSearchContactQuery(ctx, paging)
+	if err != nil {
+		return err
+	}
+
+	responses, metadata, err := c.UseCase.Search(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error searching contact")
+		return err
+	}
+
+	data, err := fields.pick(converter.ContactResponsesToV2(responses))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error picking contact fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[any]{
+		Data:   data,
+		Paging: converter.PageMetadataToV2(metadata),
+	})
+}
+
+func (c *ContactV2Controller) Get(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	fields, err := parseFields[dto.ContactResponseV2](ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.GetContactRequest{
+		UserId: auth.ID,
+		ID:     ctx.Param("contactId"),
+		Expand: parseList(ctx, "expand"),
+	}
+
+	response, err := c.UseCase.Get(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	if notModified(ctx, response.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	data, err := fields.pick(converter.ContactResponseToV2(response))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error picking contact fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[any]{Data: data})
+}
+
+func (c *ContactV2Controller) Update(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.UpdateContactRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request.UserId = auth.ID
+	request.ID = ctx.Param("contactId")
+	request.IfMatch = ifMatch
+
+	response, err := c.UseCase.Update(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating contact")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.ContactResponseV2]{Data: converter.ContactResponseToV2(response)})
+}
+
+func (c *ContactV2Controller) Patch(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	format, patch, err := readPatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.PatchContactRequest{
+		UserId:  auth.ID,
+		ID:      ctx.Param("contactId"),
+		IfMatch: ifMatch,
+		Format:  format,
+		Patch:   patch,
+	}
+
+	response, err := c.UseCase.Patch(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error patching contact")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.ContactResponseV2]{Data: converter.ContactResponseToV2(response)})
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type CustomFieldController struct {
+	UseCase *usecase.CustomFieldUseCase
+	Log     *zap.Logger
+}
+
+func NewCustomFieldController(useCase *usecase.CustomFieldUseCase, log *zap.Logger) *CustomFieldController {
+	return &CustomFieldController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *CustomFieldController) Create(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.CreateCustomFieldRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Create(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating custom field")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.CustomFieldResponse]{Data: response})
+}
+
+func (c *CustomFieldController) List(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.ListCustomFieldRequest{
+		UserId: auth.ID,
+	}
+
+	responses, err := c.UseCase.List(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error listing custom fields")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.CustomFieldResponse]{Data: responses})
+}
+
+func (c *CustomFieldController) Get(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.GetCustomFieldRequest{
+		UserId: auth.ID,
+		ID:     ctx.Param("customFieldId"),
+	}
+
+	response, err := c.UseCase.Get(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom field")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.CustomFieldResponse]{Data: response})
+}
+
+func (c *CustomFieldController) Update(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.UpdateCustomFieldRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	request.ID = ctx.Param("customFieldId")
+
+	response, err := c.UseCase.Update(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating custom field")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.CustomFieldResponse]{Data: response})
+}
+
+func (c *CustomFieldController) Delete(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.DeleteCustomFieldRequest{
+		UserId: auth.ID,
+		ID:     ctx.Param("customFieldId"),
+	}
+
+	if err := c.UseCase.Delete(ctx.Request().Context(), request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting custom field")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[bool]{Data: true})
+}
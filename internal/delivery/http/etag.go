@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// etag is the strong entity tag of a resource at the given version.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch reads If-Match into the versions it allows. A missing header or
+// "*" yields nil. Weak tags never match under the strong comparison If-Match
+// requires, so a header without any usable tag fails with 412 right away.
+func parseIfMatch(ctx echo.Context) ([]int64, error) {
+	header := strings.TrimSpace(ctx.Request().Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		if version, ok := parseETag(strings.TrimSpace(tag)); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, echo.NewHTTPError(http.StatusPreconditionFailed, "resource has been modified")
+	}
+	return versions, nil
+}
+
+// notModified reports whether If-None-Match already names the version, using
+// the weak comparison the header calls for.
+func notModified(ctx echo.Context, version int64) bool {
+	header := strings.TrimSpace(ctx.Request().Header.Get("If-None-Match"))
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tagVersion, ok := parseETag(strings.TrimPrefix(strings.TrimSpace(tag), "W/"))
+		if ok && tagVersion == version {
+			return true
+		}
+	}
+	return false
+}
+
+func parseETag(tag string) (int64, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/vcard"
+)
+
+// contactCSVHeader uses the import's default column names so an export can be
+// imported back without a mapping.
+var contactCSVHeader = []string{"first_name", "last_name", "email", "phone", "street", "city", "province", "postal_code", "country", "tags"}
+
+type contactExporter interface {
+	ContentType() string
+	Begin() error
+	Write(contact *dto.ContactResponse) error
+	End() error
+}
+
+func newContactExporter(format string, w io.Writer) contactExporter {
+	switch format {
+	case "csv":
+		return &csvContactExporter{w: csv.NewWriter(w)}
+	case "json":
+		return &jsonContactExporter{w: bufio.NewWriter(w)}
+	default:
+		return &vcardContactExporter{w: vcard.NewWriter(w)}
+	}
+}
+
+type vcardContactExporter struct {
+	w *vcard.Writer
+}
+
+func (e *vcardContactExporter) ContentType() string {
+	return "text/vcard; charset=utf-8"
+}
+
+func (e *vcardContactExporter) Begin() error {
+	return nil
+}
+
+func (e *vcardContactExporter) Write(contact *dto.ContactResponse) error {
+	return e.w.Write(contactToCard(contact))
+}
+
+func (e *vcardContactExporter) End() error {
+	return e.w.Flush()
+}
+
+// csvContactExporter writes one row per contact. CSV has no room for more than
+// one address, so only the first is kept; vCard exports carry all of them.
+type csvContactExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvContactExporter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (e *csvContactExporter) Begin() error {
+	return e.w.Write(contactCSVHeader)
+}
+
+func (e *csvContactExporter) Write(contact *dto.ContactResponse) error {
+	var address dto.AddressResponse
+	if len(contact.Addresses) > 0 {
+		address = contact.Addresses[0]
+	}
+
+	return e.w.Write([]string{
+		contact.FirstName,
+		contact.LastName,
+		contact.Email,
+		contact.Phone,
+		address.Street,
+		address.City,
+		address.Province,
+		address.PostalCode,
+		address.Country,
+		strings.Join(tagNames(contact.Tags), ";"),
+	})
+}
+
+func (e *csvContactExporter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonContactExporter struct {
+	w       *bufio.Writer
+	written bool
+}
+
+func (e *jsonContactExporter) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (e *jsonContactExporter) Begin() error {
+	_, err := e.w.WriteString("[")
+	return err
+}
+
+func (e *jsonContactExporter) Write(contact *dto.ContactResponse) error {
+	if e.written {
+		if err := e.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	e.written = true
+
+	payload, err := json.Marshal(contact)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(payload)
+	return err
+}
+
+func (e *jsonContactExporter) End() error {
+	if _, err := e.w.WriteString("]\n"); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func contactToCard(contact *dto.ContactResponse) *vcard.Card {
+	card := &vcard.Card{
+		UID:        contact.ID,
+		GivenName:  contact.FirstName,
+		FamilyName: contact.LastName,
+		Categories: tagNames(contact.Tags),
+	}
+
+	if contact.Email != "" {
+		card.Emails = []string{contact.Email}
+	}
+	if contact.Phone != "" {
+		card.Phones = []string{contact.Phone}
+	}
+
+	for _, date := range contact.Dates {
+		switch date.Type {
+		case entity.DateTypeBirthday:
+			card.Birthday = date.Date
+		case entity.DateTypeAnniversary:
+			card.Anniversary = date.Date
+		}
+	}
+
+	for _, address := range contact.Addresses {
+		var types []string
+		switch address.Label {
+		case entity.AddressLabelHome, entity.AddressLabelWork:
+			types = append(types, strings.ToUpper(address.Label))
+		}
+		if address.IsPrimary {
+			types = append(types, "PREF")
+		}
+
+		card.Addresses = append(card.Addresses, vcard.Address{
+			Types:      types,
+			Street:     address.Street,
+			City:       address.City,
+			Province:   address.Province,
+			PostalCode: address.PostalCode,
+			Country:    address.Country,
+		})
+	}
+
+	return card
+}
+
+func tagNames(tags []dto.TagResponse) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+)
+
+// sparseFields is the set of response members a client picked with
+// ?fields=id,first_name. A nil set keeps every member.
+type sparseFields map[string]bool
+
+// parseFields reads ?fields= for responses of type T. Only members of T can
+// be picked, so a typo fails instead of quietly returning empty objects.
+func parseFields[T any](ctx echo.Context) (sparseFields, error) {
+	names := parseList(ctx, "fields")
+	if names == nil {
+		return nil, nil
+	}
+
+	allowed := jsonMembers(reflect.TypeFor[T]())
+	fields := sparseFields{}
+	for _, name := range names {
+		if !slices.Contains(allowed, name) {
+			return nil, apperror.Invalid("fields is invalid", dto.FieldError{
+				Field:   "fields",
+				Message: fmt.Sprintf("%q is not a field, choose from: %s", name, strings.Join(allowed, ", ")),
+			})
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// parseList reads a comma separated query parameter, or nil when it is
+// missing or empty.
+func parseList(ctx echo.Context, name string) []string {
+	var values []string
+	for _, value := range strings.Split(ctx.QueryParam(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// jsonMembers lists the JSON member names of a response struct.
+func jsonMembers(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// pick trims a response, or each of a list of responses, to the picked
+// members. Members of nested objects, such as an expanded contact's
+// addresses, are kept whole.
+func (f sparseFields) pick(response any) (any, error) {
+	if f == nil {
+		return response, nil
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers stay json.Number so that large millisecond timestamps keep
+	// every digit.
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		f.trim(value)
+	case []any:
+		for _, item := range value {
+			if object, ok := item.(map[string]any); ok {
+				f.trim(object)
+			}
+		}
+	}
+	return value, nil
+}
+
+func (f sparseFields) trim(object map[string]any) {
+	for name := range object {
+		if !f[name] {
+			delete(object, name)
+		}
+	}
+}
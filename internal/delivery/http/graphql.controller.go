@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/graphql"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type GraphQLController struct {
+	Log            *zap.Logger
+	AddressUseCase *usecase.AddressUseCase
+	Executor       *graphql.Executor
+	MaxBodySize    int64
+}
+
+// NewGraphQLController serves the schema over the given use cases, refusing
+// request bodies over maxBodySize bytes and queries nested deeper than
+// maxDepth or more complex than maxComplexity.
+func NewGraphQLController(userUseCase *usecase.UserUseCase, contactUseCase *usecase.ContactUseCase,
+	addressUseCase *usecase.AddressUseCase, log *zap.Logger, maxBodySize int64, maxDepth int, maxComplexity int) *GraphQLController {
+	c := &GraphQLController{
+		Log:            log,
+		AddressUseCase: addressUseCase,
+		MaxBodySize:    maxBodySize,
+	}
+	c.Executor = &graphql.Executor{
+		Schema:        newGraphQLSchema(userUseCase, contactUseCase, addressUseCase),
+		MaxDepth:      maxDepth,
+		MaxComplexity: maxComplexity,
+		Present:       c.present,
+	}
+	return c
+}
+
+// Query answers 400 when the request could not be run at all, and 200 with
+// whatever data it produced otherwise, errors included.
+func (c *GraphQLController) Query(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	if c.MaxBodySize > 0 {
+		ctx.Request().Body = http.MaxBytesReader(ctx.Response(), ctx.Request().Body, c.MaxBodySize)
+	}
+
+	request := new(dto.GraphQLRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than %d bytes", c.MaxBodySize))
+		}
+		return echo.ErrBadRequest
+	}
+
+	requestCtx := context.WithValue(ctx.Request().Context(), graphQLRequestKey{}, &graphQLRequest{
+		auth:      auth,
+		addresses: c.addressLoader(auth),
+	})
+	response := c.Executor.Execute(requestCtx, graphql.Request{
+		Query:         request.Query,
+		OperationName: request.OperationName,
+		Variables:     request.Variables,
+	})
+
+	if response.Data == nil {
+		c.Log.Warn("rejected graphql request", zap.String("error", response.Errors[0].Message))
+		return ctx.JSON(http.StatusBadRequest, response)
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *GraphQLController) Schema(ctx echo.Context) error {
+	return ctx.Blob(http.StatusOK, "application/graphql; charset=utf-8", []byte(c.Executor.Schema.String()))
+}
+
+// addressLoader reads the addresses of every contact in a level of the query
+// at once. It is made per request, since what it reads depends on the user.
+func (c *GraphQLController) addressLoader(auth *dto.Auth) *graphql.Loader[string, []dto.AddressResponseV2] {
+	return graphql.NewLoader(func(ctx context.Context, contactIds []string) (map[string][]dto.AddressResponseV2, error) {
+		responses, err := c.AddressUseCase.ListByContacts(ctx, &dto.ListContactsAddressesRequest{
+			UserId:     auth.ID,
+			ContactIds: contactIds,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		addresses := make(map[string][]dto.AddressResponseV2, len(responses))
+		for contactId, response := range responses {
+			addresses[contactId] = converter.AddressResponsesToV2(response)
+		}
+		return addresses, nil
+	})
+}
+
+// present shows clients what an apperror says about itself, with its kind as
+// extensions.code, and hides every other error behind an internal one.
+func (c *GraphQLController) present(err error) *graphql.Error {
+	if appErr, ok := apperror.As(err); ok && appErr.Kind != apperror.KindInternal {
+		extensions := map[string]any{"code": appErr.Kind}
+		if len(appErr.Fields) > 0 {
+			extensions["fields"] = appErr.Fields
+		}
+		return &graphql.Error{Message: appErr.Message, Extensions: extensions}
+	}
+
+	c.Log.With(zap.Error(err)).Error("error resolving graphql field")
+	return &graphql.Error{
+		Message:    apperror.ErrInternal.Message,
+		Extensions: map[string]any{"code": apperror.KindInternal},
+	}
+}
@@ -0,0 +1,607 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/graphql"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+)
+
+// graphQLRequest is what the resolvers of one request share: who is asking,
+// and the loaders batching their reads.
+type graphQLRequest struct {
+	auth      *dto.Auth
+	addresses *graphql.Loader[string, []dto.AddressResponseV2]
+}
+
+type graphQLRequestKey struct{}
+
+func graphQLRequestFrom(ctx context.Context) *graphQLRequest {
+	return ctx.Value(graphQLRequestKey{}).(*graphQLRequest)
+}
+
+// contactConnection is a page of contacts.
+type contactConnection struct {
+	Nodes    []dto.ContactResponseV2 `json:"nodes"`
+	PageInfo *dto.CursorPageMetadata `json:"page_info"`
+}
+
+// addressesComplexity is what a contact's addresses count for in a query's
+// complexity, as if each contact had this many.
+const addressesComplexity = 10
+
+// newGraphQLSchema describes users, contacts and addresses over the same use
+// cases the REST controllers call. Objects are read from their v2 responses.
+func newGraphQLSchema(userUseCase *usecase.UserUseCase, contactUseCase *usecase.ContactUseCase,
+	addressUseCase *usecase.AddressUseCase) *graphql.Schema {
+	tag := &graphql.Object{Name: "Tag", Fields: []*graphql.FieldDefinition{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		{Name: "name", Type: nonNull(graphql.String)},
+		{Name: "createdAt", Type: nonNull(graphql.DateTime)},
+		{Name: "updatedAt", Type: nonNull(graphql.DateTime)},
+	}}
+
+	contactDate := &graphql.Object{Name: "ContactDate", Fields: []*graphql.FieldDefinition{
+		{Name: "type", Type: nonNull(graphql.String)},
+		{Name: "label", Type: graphql.String},
+		{Name: "date", Type: nonNull(graphql.String), Description: "YYYY-MM-DD, or --MM-DD when the year is not known"},
+	}}
+
+	address := &graphql.Object{Name: "Address", Fields: []*graphql.FieldDefinition{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		{Name: "street", Type: nonNull(graphql.String)},
+		{Name: "city", Type: nonNull(graphql.String)},
+		{Name: "province", Type: nonNull(graphql.String)},
+		{Name: "postalCode", Type: nonNull(graphql.String)},
+		{Name: "country", Type: nonNull(graphql.String)},
+		{Name: "formatted", Type: nonNull(graphql.String)},
+		{Name: "label", Type: graphql.String},
+		{Name: "customLabel", Type: graphql.String},
+		{Name: "isPrimary", Type: nonNull(graphql.Boolean)},
+		{Name: "latitude", Type: graphql.Float},
+		{Name: "longitude", Type: graphql.Float},
+		{Name: "createdAt", Type: nonNull(graphql.DateTime)},
+		{Name: "updatedAt", Type: nonNull(graphql.DateTime)},
+		{Name: "version", Type: nonNull(graphql.Int)},
+	}}
+
+	contact := &graphql.Object{Name: "Contact", Fields: []*graphql.FieldDefinition{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		{Name: "firstName", Type: nonNull(graphql.String)},
+		{Name: "lastName", Type: nonNull(graphql.String)},
+		{Name: "email", Type: nonNull(graphql.String)},
+		{Name: "phone", Type: nonNull(graphql.String)},
+		{Name: "phoneE164", Type: nonNull(graphql.String)},
+		{Name: "createdAt", Type: nonNull(graphql.DateTime)},
+		{Name: "updatedAt", Type: nonNull(graphql.DateTime)},
+		{Name: "deletedAt", Type: graphql.DateTime},
+		{Name: "version", Type: nonNull(graphql.Int)},
+		{Name: "customFields", Type: graphql.JSON},
+		{Name: "dates", Type: nonNull(listOf(nonNull(contactDate)))},
+		{Name: "lastInteractionAt", Type: graphql.DateTime},
+		{Name: "photoUrl", Type: graphql.String},
+		{Name: "thumbnailUrl", Type: graphql.String},
+		{Name: "rank", Type: graphql.Float, Description: "How well the contact matches q"},
+		{Name: "highlight", Type: graphql.String, Description: "The part of the contact that matched q as escaped HTML, with the matches in <mark>, when highlight is set"},
+		{Name: "distanceKm", Type: graphql.Float, Description: "How far the contact's primary address is from near"},
+		{Name: "tags", Type: nonNull(listOf(nonNull(tag)))},
+		{Name: "primaryAddress", Type: address},
+		{
+			Name: "addresses",
+			Type: nonNull(listOf(nonNull(address))),
+			Resolve: func(ctx context.Context, source any, _ map[string]any) (any, error) {
+				return graphQLRequestFrom(ctx).addresses.Load(ctx, graphQLContactId(source)), nil
+			},
+			Complexity: func(_ map[string]any, childComplexity int) int {
+				return 1 + addressesComplexity*childComplexity
+			},
+		},
+	}}
+
+	pageInfo := &graphql.Object{Name: "PageInfo", Fields: []*graphql.FieldDefinition{
+		{Name: "size", Type: nonNull(graphql.Int)},
+		{Name: "totalItem", Type: graphql.Int, Description: "Only counted when includeTotal is set"},
+		{Name: "nextCursor", Type: graphql.String, Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return optionalString(source.(*dto.CursorPageMetadata).NextCursor), nil
+		}},
+		{Name: "prevCursor", Type: graphql.String, Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return optionalString(source.(*dto.CursorPageMetadata).PrevCursor), nil
+		}},
+	}}
+
+	contactConnectionType := &graphql.Object{Name: "ContactConnection", Fields: []*graphql.FieldDefinition{
+		{Name: "nodes", Type: nonNull(listOf(nonNull(contact)))},
+		{Name: "pageInfo", Type: nonNull(pageInfo)},
+	}}
+
+	customFieldFilter := &graphql.InputObject{Name: "CustomFieldFilter", Fields: []*graphql.ArgumentDefinition{
+		{Name: "name", Type: nonNull(graphql.String)},
+		{Name: "value", Type: nonNull(graphql.String)},
+	}}
+	geoPoint := &graphql.InputObject{Name: "GeoPoint", Fields: []*graphql.ArgumentDefinition{
+		{Name: "latitude", Type: nonNull(graphql.Float)},
+		{Name: "longitude", Type: nonNull(graphql.Float)},
+	}}
+
+	contacts := &graphql.FieldDefinition{
+		Name:        "contacts",
+		Description: "Search the user's contacts, as GET /api/v2/contacts does",
+		Type:        nonNull(contactConnectionType),
+		Args: []*graphql.ArgumentDefinition{
+			{Name: "q", Type: graphql.String, Description: "Full text search over names, emails, phones and notes"},
+			{Name: "highlight", Type: graphql.Boolean, Default: false},
+			{Name: "name", Type: graphql.String},
+			{Name: "email", Type: graphql.String},
+			{Name: "phone", Type: graphql.String},
+			{Name: "tags", Type: listOf(nonNull(graphql.String))},
+			{Name: "tagMode", Type: graphql.String, Description: "any or all of tags"},
+			{Name: "customFields", Type: listOf(nonNull(customFieldFilter))},
+			{Name: "near", Type: geoPoint},
+			{Name: "radiusKm", Type: graphql.Float, Default: float64(defaultRadiusKm)},
+			{Name: "sort", Type: graphql.String},
+			{Name: "after", Type: graphql.String},
+			{Name: "before", Type: graphql.String},
+			{Name: "includeTotal", Type: graphql.Boolean, Default: false},
+			{Name: "size", Type: graphql.Int, Default: 10},
+		},
+		Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+			request := &dto.SearchContactRequest{
+				UserId:       graphQLRequestFrom(ctx).auth.ID,
+				Query:        stringArg(args, "q"),
+				Highlight:    boolArg(args, "highlight"),
+				Name:         stringArg(args, "name"),
+				Email:        stringArg(args, "email"),
+				Phone:        stringArg(args, "phone"),
+				Tags:         stringsArg(args, "tags"),
+				TagMode:      stringArg(args, "tagMode"),
+				Sort:         stringArg(args, "sort"),
+				After:        stringArg(args, "after"),
+				Before:       stringArg(args, "before"),
+				IncludeTotal: boolArg(args, "includeTotal"),
+				Page:         1,
+				Size:         intArg(args, "size", 10),
+			}
+			if filters, ok := args["customFields"].([]any); ok && len(filters) > 0 {
+				request.CustomFields = make(map[string]string, len(filters))
+				for _, filter := range filters {
+					filter := filter.(map[string]any)
+					request.CustomFields[filter["name"].(string)] = filter["value"].(string)
+				}
+			}
+			if near, ok := args["near"].(map[string]any); ok {
+				request.Near = &dto.GeoPoint{Latitude: near["latitude"].(float64), Longitude: near["longitude"].(float64)}
+				request.RadiusKm = floatArg(args, "radiusKm", defaultRadiusKm)
+			}
+
+			responses, metadata, err := contactUseCase.Search(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			return &contactConnection{
+				Nodes:    converter.ContactResponsesToV2(responses),
+				PageInfo: converter.PageMetadataToV2(metadata),
+			}, nil
+		},
+		Complexity: func(args map[string]any, childComplexity int) int {
+			return 1 + intArg(args, "size", 10)*childComplexity
+		},
+	}
+
+	user := &graphql.Object{Name: "User", Fields: []*graphql.FieldDefinition{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		{Name: "name", Type: nonNull(graphql.String)},
+		{Name: "createdAt", Type: graphql.DateTime},
+		{Name: "updatedAt", Type: graphql.DateTime},
+		contacts,
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.FieldDefinition{
+		{
+			Name:        "me",
+			Description: "The user the request is authenticated as",
+			Type:        nonNull(user),
+			Resolve: func(ctx context.Context, _ any, _ map[string]any) (any, error) {
+				response, err := userUseCase.Current(ctx, &dto.GetUserRequest{ID: graphQLRequestFrom(ctx).auth.ID})
+				if err != nil {
+					return nil, err
+				}
+				return converter.UserResponseToV2(response), nil
+			},
+		},
+		{
+			Name: "contact",
+			Type: contact,
+			Args: []*graphql.ArgumentDefinition{{Name: "id", Type: nonNull(graphql.ID)}},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				response, err := contactUseCase.Get(ctx, &dto.GetContactRequest{
+					UserId: graphQLRequestFrom(ctx).auth.ID,
+					ID:     stringArg(args, "id"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				return converter.ContactResponseToV2(response), nil
+			},
+		},
+		contacts,
+		{
+			Name: "address",
+			Type: address,
+			Args: []*graphql.ArgumentDefinition{
+				{Name: "contactId", Type: nonNull(graphql.ID)},
+				{Name: "id", Type: nonNull(graphql.ID)},
+			},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				response, err := addressUseCase.Get(ctx, &dto.GetAddressRequest{
+					UserId:    graphQLRequestFrom(ctx).auth.ID,
+					ContactId: stringArg(args, "contactId"),
+					ID:        stringArg(args, "id"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				return converter.AddressResponseToV2(response), nil
+			},
+		},
+	}}
+
+	contactDateInput := &graphql.InputObject{Name: "ContactDateInput", Fields: []*graphql.ArgumentDefinition{
+		{Name: "type", Type: nonNull(graphql.String), Description: "birthday, anniversary or custom"},
+		{Name: "label", Type: graphql.String},
+		{Name: "date", Type: nonNull(graphql.String), Description: "YYYY-MM-DD, or --MM-DD when the year is not known"},
+	}}
+	contactInput := &graphql.InputObject{Name: "ContactInput", Fields: []*graphql.ArgumentDefinition{
+		{Name: "firstName", Type: nonNull(graphql.String)},
+		{Name: "lastName", Type: graphql.String},
+		{Name: "email", Type: graphql.String},
+		{Name: "phone", Type: graphql.String},
+		{Name: "customFields", Type: graphql.JSON},
+		{Name: "dates", Type: listOf(nonNull(contactDateInput))},
+	}}
+	addressInput := &graphql.InputObject{Name: "AddressInput", Fields: []*graphql.ArgumentDefinition{
+		{Name: "street", Type: graphql.String},
+		{Name: "city", Type: graphql.String},
+		{Name: "province", Type: graphql.String},
+		{Name: "postalCode", Type: graphql.String},
+		{Name: "country", Type: graphql.String},
+		{Name: "label", Type: graphql.String, Description: "home, work, billing, shipping, other or custom"},
+		{Name: "customLabel", Type: graphql.String},
+	}}
+
+	ifMatch := &graphql.ArgumentDefinition{Name: "ifMatch", Type: graphql.Int,
+		Description: "Version the change is based on, as If-Match carries it"}
+	contactId := &graphql.ArgumentDefinition{Name: "contactId", Type: nonNull(graphql.ID)}
+	id := &graphql.ArgumentDefinition{Name: "id", Type: nonNull(graphql.ID)}
+	patch := &graphql.ArgumentDefinition{Name: "patch", Type: nonNull(graphql.JSON),
+		Description: "JSON merge patch against the REST update body"}
+
+	mutation := &graphql.Object{Name: "Mutation", Fields: []*graphql.FieldDefinition{
+		{
+			Name: "createContact",
+			Type: nonNull(contact),
+			Args: []*graphql.ArgumentDefinition{{Name: "input", Type: nonNull(contactInput)}},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				request := new(dto.CreateContactRequest)
+				if err := bindGraphQLInput(args["input"], request); err != nil {
+					return nil, err
+				}
+				request.UserId = graphQLRequestFrom(ctx).auth.ID
+				return contactResponse(contactUseCase.Create(ctx, request))
+			},
+		},
+		{
+			Name: "updateContact",
+			Type: nonNull(contact),
+			Args: []*graphql.ArgumentDefinition{id, {Name: "input", Type: nonNull(contactInput)}, ifMatch},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				request := new(dto.UpdateContactRequest)
+				if err := bindGraphQLInput(args["input"], request); err != nil {
+					return nil, err
+				}
+				request.UserId = graphQLRequestFrom(ctx).auth.ID
+				request.ID = stringArg(args, "id")
+				request.IfMatch = ifMatchArg(args)
+				return contactResponse(contactUseCase.Update(ctx, request))
+			},
+		},
+		{
+			Name: "patchContact",
+			Type: nonNull(contact),
+			Args: []*graphql.ArgumentDefinition{id, patch, ifMatch},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				body, err := json.Marshal(args["patch"])
+				if err != nil {
+					return nil, apperror.Invalid("patch must be a JSON object")
+				}
+				return contactResponse(contactUseCase.Patch(ctx, &dto.PatchContactRequest{
+					UserId:  graphQLRequestFrom(ctx).auth.ID,
+					ID:      stringArg(args, "id"),
+					IfMatch: ifMatchArg(args),
+					Format:  "merge",
+					Patch:   body,
+				}))
+			},
+		},
+		{
+			Name:        "deleteContact",
+			Description: "Move a contact to the trash",
+			Type:        nonNull(graphql.Boolean),
+			Args:        []*graphql.ArgumentDefinition{id, ifMatch},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				err := contactUseCase.Delete(ctx, &dto.DeleteContactRequest{
+					UserId:  graphQLRequestFrom(ctx).auth.ID,
+					ID:      stringArg(args, "id"),
+					IfMatch: ifMatchArg(args),
+				})
+				return err == nil, err
+			},
+		},
+		{
+			Name: "restoreContact",
+			Type: nonNull(contact),
+			Args: []*graphql.ArgumentDefinition{id},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				return contactResponse(contactUseCase.Restore(ctx, &dto.RestoreContactRequest{
+					UserId: graphQLRequestFrom(ctx).auth.ID,
+					ID:     stringArg(args, "id"),
+				}))
+			},
+		},
+		{
+			Name: "mergeContacts",
+			Type: nonNull(contact),
+			Args: []*graphql.ArgumentDefinition{
+				{Name: "targetId", Type: nonNull(graphql.ID)},
+				{Name: "sourceIds", Type: nonNull(listOf(nonNull(graphql.ID)))},
+				{Name: "fields", Type: graphql.JSON, Description: "Which contact each of first_name, last_name, email and phone comes from"},
+			},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				request := &dto.MergeContactRequest{
+					UserId:    graphQLRequestFrom(ctx).auth.ID,
+					TargetId:  stringArg(args, "targetId"),
+					SourceIds: stringsArg(args, "sourceIds"),
+				}
+				if fields, ok := args["fields"]; ok && fields != nil {
+					if err := bindGraphQLValue(fields, &request.Fields); err != nil {
+						return nil, err
+					}
+				}
+				return contactResponse(contactUseCase.Merge(ctx, request))
+			},
+		},
+		{
+			Name:        "revertContact",
+			Description: "Restore a contact to how it was at a version in its history",
+			Type:        nonNull(contact),
+			Args:        []*graphql.ArgumentDefinition{id, {Name: "version", Type: nonNull(graphql.Int)}, ifMatch},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				return contactResponse(contactUseCase.Revert(ctx, &dto.RevertContactRequest{
+					UserId:    graphQLRequestFrom(ctx).auth.ID,
+					ContactId: stringArg(args, "id"),
+					Version:   int64(intArg(args, "version", 0)),
+					IfMatch:   ifMatchArg(args),
+				}))
+			},
+		},
+		{
+			Name: "addContactTag",
+			Type: nonNull(contact),
+			Args: []*graphql.ArgumentDefinition{contactId, {Name: "tagId", Type: nonNull(graphql.ID)}},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				return contactResponse(contactUseCase.AddTag(ctx, &dto.ContactTagRequest{
+					UserId:    graphQLRequestFrom(ctx).auth.ID,
+					ContactId: stringArg(args, "contactId"),
+					TagId:     stringArg(args, "tagId"),
+				}))
+			},
+		},
+		{
+			Name: "removeContactTag",
+			Type: nonNull(contact),
+			Args: []*graphql.ArgumentDefinition{contactId, {Name: "tagId", Type: nonNull(graphql.ID)}},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				return contactResponse(contactUseCase.RemoveTag(ctx, &dto.ContactTagRequest{
+					UserId:    graphQLRequestFrom(ctx).auth.ID,
+					ContactId: stringArg(args, "contactId"),
+					TagId:     stringArg(args, "tagId"),
+				}))
+			},
+		},
+		{
+			Name: "createAddress",
+			Type: nonNull(address),
+			Args: []*graphql.ArgumentDefinition{
+				contactId,
+				{Name: "input", Type: nonNull(addressInput)},
+				{Name: "isPrimary", Type: graphql.Boolean, Default: false},
+			},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				request := new(dto.CreateAddressRequest)
+				if err := bindGraphQLInput(args["input"], request); err != nil {
+					return nil, err
+				}
+				request.UserId = graphQLRequestFrom(ctx).auth.ID
+				request.ContactId = stringArg(args, "contactId")
+				request.IsPrimary = boolArg(args, "isPrimary")
+				return addressResponse(addressUseCase.Create(ctx, request))
+			},
+		},
+		{
+			Name: "updateAddress",
+			Type: nonNull(address),
+			Args: []*graphql.ArgumentDefinition{contactId, id, {Name: "input", Type: nonNull(addressInput)}, ifMatch},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				request := new(dto.UpdateAddressRequest)
+				if err := bindGraphQLInput(args["input"], request); err != nil {
+					return nil, err
+				}
+				request.UserId = graphQLRequestFrom(ctx).auth.ID
+				request.ContactId = stringArg(args, "contactId")
+				request.ID = stringArg(args, "id")
+				request.IfMatch = ifMatchArg(args)
+				return addressResponse(addressUseCase.Update(ctx, request))
+			},
+		},
+		{
+			Name: "patchAddress",
+			Type: nonNull(address),
+			Args: []*graphql.ArgumentDefinition{contactId, id, patch, ifMatch},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				body, err := json.Marshal(args["patch"])
+				if err != nil {
+					return nil, apperror.Invalid("patch must be a JSON object")
+				}
+				return addressResponse(addressUseCase.Patch(ctx, &dto.PatchAddressRequest{
+					UserId:    graphQLRequestFrom(ctx).auth.ID,
+					ContactId: stringArg(args, "contactId"),
+					ID:        stringArg(args, "id"),
+					IfMatch:   ifMatchArg(args),
+					Format:    "merge",
+					Patch:     body,
+				}))
+			},
+		},
+		{
+			Name: "deleteAddress",
+			Type: nonNull(graphql.Boolean),
+			Args: []*graphql.ArgumentDefinition{contactId, id, ifMatch},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				err := addressUseCase.Delete(ctx, &dto.DeleteAddressRequest{
+					UserId:    graphQLRequestFrom(ctx).auth.ID,
+					ContactId: stringArg(args, "contactId"),
+					ID:        stringArg(args, "id"),
+					IfMatch:   ifMatchArg(args),
+				})
+				return err == nil, err
+			},
+		},
+		{
+			Name: "setPrimaryAddress",
+			Type: nonNull(address),
+			Args: []*graphql.ArgumentDefinition{contactId, id, ifMatch},
+			Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+				return addressResponse(addressUseCase.SetPrimary(ctx, &dto.SetPrimaryAddressRequest{
+					UserId:    graphQLRequestFrom(ctx).auth.ID,
+					ContactId: stringArg(args, "contactId"),
+					ID:        stringArg(args, "id"),
+					IfMatch:   ifMatchArg(args),
+				}))
+			},
+		},
+	}}
+
+	schema, err := graphql.NewSchema(query, mutation)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func contactResponse(response *dto.ContactResponse, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return converter.ContactResponseToV2(response), nil
+}
+
+func addressResponse(response *dto.AddressResponse, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return converter.AddressResponseToV2(response), nil
+}
+
+// graphQLContactId is the id of a Contact's source, which is a response in a
+// list or a pointer to one.
+func graphQLContactId(source any) string {
+	switch contact := source.(type) {
+	case dto.ContactResponseV2:
+		return contact.ID
+	case *dto.ContactResponseV2:
+		return contact.ID
+	}
+	return ""
+}
+
+// bindGraphQLInput fills a request from an input object, whose members are
+// the camelCase forms of the request's JSON names.
+func bindGraphQLInput(input any, request any) error {
+	object, _ := input.(map[string]any)
+	members := make(map[string]any, len(object))
+	for name, value := range object {
+		members[graphql.SnakeCase(name)] = value
+	}
+	return bindGraphQLValue(members, request)
+}
+
+func bindGraphQLValue(value any, target any) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return apperror.Invalid("input is invalid")
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return apperror.Invalid("input is invalid: " + err.Error())
+	}
+	return nil
+}
+
+func nonNull(t graphql.Type) graphql.Type {
+	return &graphql.NonNull{Of: t}
+}
+
+func listOf(t graphql.Type) graphql.Type {
+	return &graphql.List{Of: t}
+}
+
+func stringArg(args map[string]any, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func stringsArg(args map[string]any, name string) []string {
+	items, _ := args[name].([]any)
+	var values []string
+	for _, item := range items {
+		if value, ok := item.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func boolArg(args map[string]any, name string) bool {
+	value, _ := args[name].(bool)
+	return value
+}
+
+func intArg(args map[string]any, name string, fallback int) int {
+	if value, ok := args[name].(int); ok {
+		return value
+	}
+	return fallback
+}
+
+func floatArg(args map[string]any, name string, fallback float64) float64 {
+	if value, ok := args[name].(float64); ok {
+		return value
+	}
+	return fallback
+}
+
+func ifMatchArg(args map[string]any) []int64 {
+	if version, ok := args["ifMatch"].(int); ok {
+		return []int64{int64(version)}
+	}
+	return nil
+}
+
+func optionalString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+const maxImportFileSize = 10 << 20
+
+type ImportController struct {
+	UseCase *usecase.ImportUseCase
+	Log     *zap.Logger
+}
+
+func NewImportController(useCase *usecase.ImportUseCase, log *zap.Logger) *ImportController {
+	return &ImportController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *ImportController) Import(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error reading import file")
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+
+	if fileHeader.Size > maxImportFileSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "file is larger than 10MB")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error opening import file")
+		return echo.ErrBadRequest
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error reading import file")
+		return echo.ErrBadRequest
+	}
+
+	request := &dto.ImportContactRequest{
+		UserId:  auth.ID,
+		Format:  importFormat(ctx.FormValue("format"), fileHeader.Filename, fileHeader.Header.Get(echo.HeaderContentType)),
+		Content: content,
+	}
+
+	if mapping := ctx.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &request.Mapping); err != nil {
+			c.Log.With(zap.Error(err)).Error("error parsing import mapping")
+			return echo.NewHTTPError(http.StatusBadRequest, "mapping must be a JSON object")
+		}
+	}
+
+	if dryRun := ctx.FormValue("dry_run"); dryRun != "" {
+		if request.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "dry_run must be a boolean")
+		}
+	}
+
+	response, err := c.UseCase.Import(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error importing contacts")
+		return err
+	}
+
+	status := http.StatusOK
+	if response.ID != "" {
+		status = http.StatusAccepted
+	}
+
+	return ctx.JSON(status, dto.WebResponse[*dto.ImportJobResponse]{Data: response})
+}
+
+func (c *ImportController) Get(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.GetImportJobRequest{
+		UserId: auth.ID,
+		ID:     ctx.Param("jobId"),
+	}
+
+	response, err := c.UseCase.GetJob(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting import job")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ImportJobResponse]{Data: response})
+}
+
+// importFormat prefers an explicit format field and otherwise infers it from
+// the uploaded file's extension or content type.
+func importFormat(format string, filename string, contentType string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".vcf", ".vcard":
+		return "vcf"
+	}
+
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return "csv"
+	case strings.Contains(contentType, "vcard"):
+		return "vcf"
+	}
+
+	return ""
+}
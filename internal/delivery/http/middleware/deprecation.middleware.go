@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewDeprecation marks every response as coming from a deprecated version of
+// the API: Deprecation (RFC 9745) says since when, Sunset (RFC 8594) until
+// when it is served, and Link points at the version that replaces it. Zero
+// times leave their header out.
+func NewDeprecation(deprecatedAt, sunsetAt time.Time, successor string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			header := ctx.Response().Header()
+			if !deprecatedAt.IsZero() {
+				header.Set("Deprecation", "@"+strconv.FormatInt(deprecatedAt.Unix(), 10))
+			}
+			if !sunsetAt.IsZero() {
+				header.Set("Sunset", sunsetAt.UTC().Format(http.TimeFormat))
+			}
+			if successor != "" {
+				header.Add("Link", "<"+successor+`>; rel="successor-version"`)
+			}
+			return next(ctx)
+		}
+	}
+}
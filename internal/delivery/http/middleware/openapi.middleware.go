@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/openapi"
+	"go.uber.org/zap"
+)
+
+// maxValidatedBody is the largest body, request or response, whose JSON is
+// held to the document. Larger bodies are passed through unchecked.
+const maxValidatedBody = 1 << 20
+
+// NewOpenAPIValidation holds traffic to the OpenAPI document and logs every
+// request and response that breaks it. With reject, such requests are
+// refused with a 400 before they reach their handler; responses have been
+// sent by the time they are checked, so they are only ever logged.
+func NewOpenAPIValidation(validator *openapi.Validator, reject bool, log *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			request := ctx.Request()
+			operation, ok := validator.Operation(request.Method, ctx.Path())
+			if !ok {
+				return next(ctx)
+			}
+
+			body, complete, err := peekJSONBody(request)
+			if err != nil {
+				return err
+			}
+			if complete {
+				path := map[string]string{}
+				for i, name := range ctx.ParamNames() {
+					path[name] = ctx.ParamValues()[i]
+				}
+
+				violations := validator.ValidateRequest(operation, path, request.URL.Query(), request.Header.Get(echo.HeaderContentType), body)
+				if len(violations) > 0 {
+					log.Warn("request does not conform to the OpenAPI document",
+						zap.String("operation", operation.OperationID),
+						zap.String("method", request.Method),
+						zap.String("path", request.URL.Path),
+						zap.Strings("violations", violationStrings(violations)))
+					if reject {
+						return apperror.Invalid("request does not conform to the API description", violationFields(violations)...)
+					}
+				}
+			}
+
+			// Sparse fieldsets leave out members the document requires, and
+			// HEAD responses have no body to check.
+			if request.Method == http.MethodHead || request.URL.Query().Has("fields") {
+				return next(ctx)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: ctx.Response().Writer}
+			ctx.Response().Writer = recorder
+			defer func() { ctx.Response().Writer = recorder.ResponseWriter }()
+
+			// Errors are rendered here rather than after the middleware
+			// returns, so that their problem responses are checked as well.
+			if err := next(ctx); err != nil {
+				ctx.Error(err)
+			}
+
+			if recorder.truncated {
+				return nil
+			}
+			response := ctx.Response()
+			violations := validator.ValidateResponse(operation, response.Status, response.Header().Get(echo.HeaderContentType), recorder.body.Bytes())
+			if len(violations) > 0 {
+				log.Warn("response does not conform to the OpenAPI document",
+					zap.String("operation", operation.OperationID),
+					zap.String("method", request.Method),
+					zap.String("path", request.URL.Path),
+					zap.Int("status", response.Status),
+					zap.Strings("violations", violationStrings(violations)))
+			}
+			return nil
+		}
+	}
+}
+
+// peekJSONBody reads a JSON request body without taking it from the handler.
+// Other bodies are left unread and reported complete with no content, since
+// only their media type is checked; JSON bodies over maxValidatedBody are
+// reported incomplete.
+func peekJSONBody(request *http.Request) ([]byte, bool, error) {
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get(echo.HeaderContentType))
+	if request.Body == nil || !(mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")) {
+		return nil, true, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxValidatedBody+1))
+	if err != nil {
+		return nil, false, echo.ErrBadRequest
+	}
+	request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), request.Body), Closer: request.Body}
+	return body, len(body) <= maxValidatedBody, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseRecorder keeps a copy of the response body, up to
+// maxValidatedBody, as it is written.
+type responseRecorder struct {
+	http.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if !r.truncated {
+		if r.body.Len()+len(p) > maxValidatedBody {
+			r.truncated = true
+			r.body.Reset()
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func violationStrings(violations []openapi.Violation) []string {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+	}
+	return messages
+}
+
+func violationFields(violations []openapi.Violation) []dto.FieldError {
+	fields := make([]dto.FieldError, len(violations))
+	for i, violation := range violations {
+		fields[i] = dto.FieldError{Field: violation.Where, Message: violation.Message}
+	}
+	return fields
+}
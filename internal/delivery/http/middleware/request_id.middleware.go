@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+)
+
+// NewRequestId echoes X-Request-Id, generating one when the client sent none,
+// and makes it available to the use cases through the request context.
+func NewRequestId() echo.MiddlewareFunc {
+	return echomiddleware.RequestIDWithConfig(echomiddleware.RequestIDConfig{
+		RequestIDHandler: func(ctx echo.Context, id string) {
+			ctx.SetRequest(ctx.Request().WithContext(usecase.WithRequestId(ctx.Request().Context(), id)))
+		},
+	})
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type NoteController struct {
+	UseCase *usecase.NoteUseCase
+	Log     *zap.Logger
+}
+
+func NewNoteController(useCase *usecase.NoteUseCase, log *zap.Logger) *NoteController {
+	return &NoteController{
+		Log:     log,
+		UseCase: useCase,
+	}
+}
+
+func (c *NoteController) Create(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.CreateNoteRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to parse request body")
+		return echo.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	request.ContactId = ctx.Param("contactId")
+
+	response, err := c.UseCase.Create(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to create note")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.NoteResponse]{Data: response})
+}
+
+func (c *NoteController) List(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+	paging := parsePagingQuery(ctx)
+
+	request := &dto.ListNoteRequest{
+		UserId:       auth.ID,
+		ContactId:    ctx.Param("contactId"),
+		Type:         ctx.QueryParam("type"),
+		Sort:         paging.Sort,
+		After:        paging.After,
+		Before:       paging.Before,
+		IncludeTotal: paging.IncludeTotal,
+		Page:         paging.Page,
+		Size:         paging.Size,
+	}
+
+	responses, metadata, err := c.UseCase.List(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to list notes")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.NoteResponse]{
+		Data:   responses,
+		Paging: metadata,
+	})
+}
+
+func (c *NoteController) Get(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.GetNoteRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("noteId"),
+	}
+
+	response, err := c.UseCase.Get(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to get note")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.NoteResponse]{Data: response})
+}
+
+func (c *NoteController) Update(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.UpdateNoteRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to parse request body")
+		return echo.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	request.ContactId = ctx.Param("contactId")
+	request.ID = ctx.Param("noteId")
+
+	response, err := c.UseCase.Update(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to update note")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.NoteResponse]{Data: response})
+}
+
+func (c *NoteController) Delete(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.DeleteNoteRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		ID:        ctx.Param("noteId"),
+	}
+
+	if err := c.UseCase.Delete(ctx.Request().Context(), request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to delete note")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[bool]{Data: true})
+}
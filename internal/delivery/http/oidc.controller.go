@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type OIDCController struct {
+	Log     *zap.Logger
+	UseCase *usecase.OIDCUseCase
+}
+
+func NewOIDCController(useCase *usecase.OIDCUseCase, logger *zap.Logger) *OIDCController {
+	return &OIDCController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *OIDCController) Login(ctx echo.Context) error {
+	request := &dto.OIDCLoginRequest{
+		LoginHint: ctx.QueryParam("login_hint"),
+	}
+
+	response, err := c.UseCase.Login(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.Warn("Failed to start oidc login", zap.Error(err))
+		return err
+	}
+
+	return ctx.Redirect(http.StatusFound, response.AuthorizationURL)
+}
+
+// Link starts linking an identity provider login to the current user. The
+// client sends the user to the returned URL, and the callback links the
+// identity that logs in there.
+func (c *OIDCController) Link(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.OIDCLoginRequest{
+		UserId:    auth.ID,
+		LoginHint: ctx.QueryParam("login_hint"),
+	}
+
+	response, err := c.UseCase.Login(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.Warn("Failed to start linking oidc identity", zap.Error(err))
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.OIDCLoginResponse]{Data: response})
+}
+
+func (c *OIDCController) Callback(ctx echo.Context) error {
+	request := &dto.OIDCCallbackRequest{
+		State:            ctx.QueryParam("state"),
+		Code:             ctx.QueryParam("code"),
+		Error:            ctx.QueryParam("error"),
+		ErrorDescription: ctx.QueryParam("error_description"),
+	}
+
+	response, err := c.UseCase.Callback(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.Warn("Failed to complete oidc login", zap.Error(err))
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.UserResponse]{Data: response})
+}
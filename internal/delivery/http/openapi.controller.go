@@ -0,0 +1,36 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/openapi"
+	"go.uber.org/zap"
+)
+
+type OpenAPIController struct {
+	Log      *zap.Logger
+	Document []byte
+}
+
+// NewOpenAPIController encodes the document once; it does not change while
+// the server runs.
+func NewOpenAPIController(document *openapi.Document, logger *zap.Logger) *OpenAPIController {
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		logger.With(zap.Error(err)).Fatal("failed to encode OpenAPI document")
+	}
+	return &OpenAPIController{
+		Log:      logger,
+		Document: encoded,
+	}
+}
+
+func (c *OpenAPIController) Get(ctx echo.Context) error {
+	return ctx.Blob(http.StatusOK, echo.MIMEApplicationJSON, c.Document)
+}
+
+func (c *OpenAPIController) Docs(ctx echo.Context) error {
+	return ctx.HTMLBlob(http.StatusOK, openapi.DocsPage)
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+)
+
+type pagingQuery struct {
+	Sort         string
+	After        string
+	Before       string
+	IncludeTotal bool
+	Page         int
+	Size         int
+}
+
+func parsePagingQuery(ctx echo.Context) pagingQuery {
+	query := pagingQuery{
+		Sort:   ctx.QueryParam("sort"),
+		After:  ctx.QueryParam("after"),
+		Before: ctx.QueryParam("before"),
+	}
+
+	query.Page, _ = strconv.Atoi(ctx.QueryParam("page"))
+	if query.Page == 0 {
+		query.Page = 1
+	}
+
+	query.Size, _ = strconv.Atoi(ctx.QueryParam("size"))
+	if query.Size == 0 {
+		query.Size = 10
+	}
+
+	// Pages carry their totals unless include_total=false skips the count.
+	query.IncludeTotal = true
+	if includeTotal, err := strconv.ParseBool(ctx.QueryParam("include_total")); err == nil {
+		query.IncludeTotal = includeTotal
+	}
+
+	return query
+}
+
+// parseCursorQuery reads the paging query of a v2 list. v2 pages with
+// cursors only, so page is refused, and totals are only counted when
+// include_total asks for them.
+func parseCursorQuery(ctx echo.Context) (pagingQuery, error) {
+	if ctx.QueryParams().Has("page") {
+		return pagingQuery{}, apperror.Invalid("page is not supported", dto.FieldError{
+			Field:   "page",
+			Message: "page through the list with after and before instead",
+		})
+	}
+
+	query := pagingQuery{
+		Sort:   ctx.QueryParam("sort"),
+		After:  ctx.QueryParam("after"),
+		Before: ctx.QueryParam("before"),
+		Page:   1,
+	}
+
+	query.Size, _ = strconv.Atoi(ctx.QueryParam("size"))
+	if query.Size == 0 {
+		query.Size = 10
+	}
+
+	query.IncludeTotal, _ = strconv.ParseBool(ctx.QueryParam("include_total"))
+	return query, nil
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestParsePagingQueryIncludeTotal(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "", want: true},
+		{query: "include_total=false", want: false},
+		{query: "include_total=0", want: false},
+		{query: "include_total=true", want: true},
+		{query: "include_total=maybe", want: true},
+		{query: "page=2&include_total=false", want: false},
+		{query: "after=abc", want: true},
+		{query: "after=abc&include_total=false", want: false},
+		{query: "before=abc&include_total=false", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ctx := echo.New().NewContext(httptest.NewRequest("GET", "/contacts?"+tt.query, nil), httptest.NewRecorder())
+			if got := parsePagingQuery(ctx).IncludeTotal; got != tt.want {
+				t.Errorf("parsePagingQuery() IncludeTotal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCursorQuery(t *testing.T) {
+	tests := []struct {
+		query            string
+		wantIncludeTotal bool
+		wantErr          bool
+	}{
+		{query: "", wantIncludeTotal: false},
+		{query: "include_total=true", wantIncludeTotal: true},
+		{query: "after=abc&include_total=false", wantIncludeTotal: false},
+		{query: "page=2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ctx := echo.New().NewContext(httptest.NewRequest("GET", "/contacts?"+tt.query, nil), httptest.NewRecorder())
+			query, err := parseCursorQuery(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCursorQuery() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if query.IncludeTotal != tt.wantIncludeTotal {
+				t.Errorf("parseCursorQuery() IncludeTotal = %v, want %v", query.IncludeTotal, tt.wantIncludeTotal)
+			}
+		})
+	}
+}
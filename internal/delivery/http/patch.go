@@ -0,0 +1,37 @@
+package http
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxPatchSize = 1 << 20
+
+// readPatch reads a PATCH body and picks the patch format from its media type.
+// Plain JSON is read as a merge patch, which is what clients sending a partial
+// object expect.
+func readPatch(ctx echo.Context) (string, []byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+
+	var format string
+	switch mediaType {
+	case "application/merge-patch+json", echo.MIMEApplicationJSON:
+		format = "merge"
+	case "application/json-patch+json":
+		format = "json"
+	default:
+		return "", nil, echo.NewHTTPError(http.StatusUnsupportedMediaType, "use application/merge-patch+json or application/json-patch+json")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Request().Body, maxPatchSize+1))
+	if err != nil {
+		return "", nil, echo.ErrBadRequest
+	}
+	if len(body) > maxPatchSize {
+		return "", nil, echo.ErrStatusRequestEntityTooLarge
+	}
+	return format, body, nil
+}
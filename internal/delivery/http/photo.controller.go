@@ -0,0 +1,174 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// multipartOverhead is what a multipart form may add to the photo's size:
+// boundaries, part headers and other small fields.
+const multipartOverhead = 64 << 10
+
+type PhotoController struct {
+	UseCase *usecase.PhotoUseCase
+	Log     *zap.Logger
+}
+
+func NewPhotoController(useCase *usecase.PhotoUseCase, log *zap.Logger) *PhotoController {
+	return &PhotoController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *PhotoController) Put(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	data, err := c.readPhoto(ctx)
+	if err != nil {
+		return err
+	}
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.PutPhotoRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		IfMatch:   ifMatch,
+		Data:      data,
+	}
+
+	response, err := c.UseCase.Put(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error storing photo")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
+}
+
+func (c *PhotoController) Get(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.GetPhotoRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		Size:      ctx.QueryParam("size"),
+	}
+
+	response, err := c.UseCase.Get(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting photo")
+		return err
+	}
+	defer response.Body.Close()
+
+	// A photo never changes under its id, so a URL naming the current id
+	// can be cached for good; a bare URL has to be revalidated.
+	size := request.Size
+	if size == "" {
+		size = "original"
+	}
+
+	header := ctx.Response().Header()
+	tag := `"` + response.ID + "-" + size + `"`
+	header.Set("ETag", tag)
+	header.Set("X-Content-Type-Options", "nosniff")
+	if ctx.QueryParam("v") == response.ID {
+		header.Set("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		header.Set("Cache-Control", "private, no-cache")
+	}
+
+	if ctx.Request().Header.Get("If-None-Match") == tag {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return ctx.Stream(http.StatusOK, response.ContentType, response.Body)
+}
+
+func (c *PhotoController) Delete(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	ifMatch, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.DeletePhotoRequest{
+		UserId:    auth.ID,
+		ContactId: ctx.Param("contactId"),
+		IfMatch:   ifMatch,
+	}
+
+	response, err := c.UseCase.Delete(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting photo")
+		return err
+	}
+
+	ctx.Response().Header().Set("ETag", etag(response.Version))
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ContactResponse]{Data: response})
+}
+
+// readPhoto takes the photo from the "photo" field of a multipart form, or
+// else the raw request body, refusing anything over the size limit before
+// reading it whole. The body is capped too, so a form is not spooled to disk
+// past the limit either.
+func (c *PhotoController) readPhoto(ctx echo.Context) ([]byte, error) {
+	maxSize := c.UseCase.MaxSize
+	tooLarge := echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("photo is larger than %d bytes", maxSize))
+
+	ctx.Request().Body = http.MaxBytesReader(ctx.Response(), ctx.Request().Body, maxSize+multipartOverhead)
+	body := ctx.Request().Body
+	if mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType)); mediaType == echo.MIMEMultipartForm {
+		fileHeader, err := ctx.FormFile("photo")
+		if err != nil {
+			c.Log.With(zap.Error(err)).Error("error reading photo")
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return nil, tooLarge
+			}
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "photo is required")
+		}
+		if fileHeader.Size > maxSize {
+			return nil, tooLarge
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.Log.With(zap.Error(err)).Error("error opening photo")
+			return nil, echo.ErrBadRequest
+		}
+		defer file.Close()
+		body = file
+	} else if ctx.Request().ContentLength > maxSize {
+		return nil, tooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error reading photo")
+		return nil, echo.ErrBadRequest
+	}
+	if int64(len(data)) > maxSize {
+		return nil, tooLarge
+	}
+	if len(data) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "photo is required")
+	}
+	return data, nil
+}
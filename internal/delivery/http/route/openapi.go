@@ -0,0 +1,353 @@
+package route
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/openapi"
+)
+
+// Routes describes every route Setup registers, for the OpenAPI document.
+// TestRoutesDescribed reports the routes it is missing, so keep the two in step.
+var Routes = []openapi.Route{
+	{Method: http.MethodGet, Path: "/api/openapi.json", Tag: "meta", Summary: "This document", Public: true,
+		ResponseTypes: map[string]*openapi.Schema{"application/json": {Type: "object"}}},
+	{Method: http.MethodGet, Path: "/api/docs", Tag: "meta", Summary: "Reference pages for this document", Public: true,
+		ResponseTypes: map[string]*openapi.Schema{"text/html": {Type: "string"}}},
+
+	{Method: http.MethodPost, Path: "/api/graphql", Tag: "graphql", Summary: "Run a GraphQL query or mutation",
+		Body: dto.GraphQLRequest{}, ResponseTypes: map[string]*openapi.Schema{"application/json": graphQLResponse},
+		Statuses: []int{http.StatusOK, http.StatusBadRequest}},
+	{Method: http.MethodGet, Path: "/api/graphql/schema.graphql", Tag: "graphql", Summary: "The GraphQL schema", Public: true,
+		ResponseTypes: map[string]*openapi.Schema{"application/graphql": {Type: "string"}}},
+
+	{Method: http.MethodPost, Path: "/api/users", Tag: "users", Summary: "Register a user", Public: true,
+		Body: dto.RegisterUserRequest{}, Data: dto.UserResponse{}},
+	{Method: http.MethodPost, Path: "/api/users/_login", Tag: "users", Summary: "Log in and get a token", Public: true,
+		Body: dto.LoginUserRequest{}, Data: dto.UserResponse{}},
+	{Method: http.MethodGet, Path: "/api/calendar.ics", Tag: "calendar", Summary: "Calendar feed of contact dates", Public: true,
+		Query: dto.CalendarFeedRequest{}, ResponseTypes: map[string]*openapi.Schema{"text/calendar": {Type: "string"}}},
+	{Method: http.MethodGet, Path: "/api/auth/oidc/login", Tag: "auth", Summary: "Start logging in with the identity provider",
+		Public: true, Query: dto.OIDCLoginRequest{}, Redirect: true},
+	{Method: http.MethodGet, Path: "/api/auth/oidc/callback", Tag: "auth", Summary: "Finish logging in with the identity provider",
+		Public: true, Query: dto.OIDCCallbackRequest{}, Data: dto.UserResponse{}},
+
+	{Method: http.MethodDelete, Path: "/api/users", Tag: "users", Summary: "Log out", Data: true},
+	{Method: http.MethodPatch, Path: "/api/users/_current", Tag: "users", Summary: "Update the current user",
+		Body: dto.UpdateUserRequest{}, Data: dto.UserResponse{}},
+	{Method: http.MethodGet, Path: "/api/users/_current", Tag: "users", Summary: "Get the current user",
+		Params: []*openapi.Parameter{fieldsParam}, Data: dto.UserResponse{}},
+	{Method: http.MethodPost, Path: "/api/users/_current/calendar_token", Tag: "calendar", Summary: "Create or rotate the calendar feed token",
+		Data: dto.CalendarTokenResponse{}},
+	{Method: http.MethodDelete, Path: "/api/users/_current/calendar_token", Tag: "calendar", Summary: "Revoke the calendar feed token",
+		Data: true},
+	{Method: http.MethodPost, Path: "/api/users/_current/identities", Tag: "auth",
+		Summary: "Start linking an identity provider login to the current user", Query: dto.OIDCLoginRequest{},
+		Data: dto.OIDCLoginResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/contacts", Tag: "contacts", Summary: "Search contacts",
+		Query: dto.SearchContactRequest{}, Params: []*openapi.Parameter{fieldsParam, nearParam}, Data: []dto.ContactResponse{}, Paged: true},
+	{Method: http.MethodPost, Path: "/api/contacts", Tag: "contacts", Summary: "Create a contact",
+		Body: dto.CreateContactRequest{}, Data: dto.ContactResponse{}, ETag: true},
+	{Method: http.MethodGet, Path: "/api/contacts/_trash", Tag: "contacts", Summary: "List deleted contacts",
+		Query: dto.SearchTrashContactRequest{}, Data: []dto.ContactResponse{}, Paged: true},
+	{Method: http.MethodPost, Path: "/api/contacts/_import", Tag: "imports", Summary: "Import contacts from CSV or vCard",
+		BodyTypes: map[string]*openapi.Schema{"multipart/form-data": importForm}, Data: dto.ImportJobResponse{},
+		Statuses: []int{http.StatusOK, http.StatusAccepted}},
+	{Method: http.MethodGet, Path: "/api/contacts/_import/:jobId", Tag: "imports", Summary: "Get an import job",
+		Data: dto.ImportJobResponse{}},
+	{Method: http.MethodGet, Path: "/api/contacts/_export", Tag: "contacts", Summary: "Export contacts",
+		Query: dto.ExportContactRequest{}, Params: []*openapi.Parameter{exportFormatParam, nearParam},
+		ResponseTypes: map[string]*openapi.Schema{
+			"text/vcard":       {Type: "string"},
+			"text/csv":         {Type: "string"},
+			"application/json": {Type: "array", Items: &openapi.Schema{Ref: "#/components/schemas/ContactResponse"}},
+		}},
+	{Method: http.MethodGet, Path: "/api/contacts/_shared_with_me", Tag: "shares", Summary: "List contacts shared with the current user",
+		Query: dto.SearchSharedContactRequest{}, Data: []dto.SharedContactResponse{}, Paged: true},
+	{Method: http.MethodGet, Path: "/api/contacts/_duplicates", Tag: "contacts", Summary: "Find likely duplicate contacts",
+		Query: dto.FindDuplicateContactRequest{}, Data: []dto.DuplicateGroupResponse{}},
+	{Method: http.MethodGet, Path: "/api/contacts/_upcoming", Tag: "calendar", Summary: "List upcoming contact dates",
+		Query: dto.UpcomingDateRequest{}, Data: []dto.UpcomingDateResponse{}},
+	{Method: http.MethodPost, Path: "/api/contacts/_merge", Tag: "contacts", Summary: "Merge contacts into one",
+		Body: dto.MergeContactRequest{}, Data: dto.ContactResponse{}},
+	{Method: http.MethodPut, Path: "/api/contacts/:contactId", Tag: "contacts", Summary: "Replace a contact",
+		Params: []*openapi.Parameter{ifMatchParam}, Body: dto.UpdateContactRequest{}, Data: dto.ContactResponse{}, ETag: true},
+	{Method: http.MethodPatch, Path: "/api/contacts/:contactId", Tag: "contacts", Summary: "Patch a contact",
+		Params: []*openapi.Parameter{ifMatchParam}, BodyTypes: patchTypes, Data: dto.ContactResponse{}, ETag: true},
+	{Method: http.MethodGet, Path: "/api/contacts/:contactId", Tag: "contacts", Summary: "Get a contact, or its vCard",
+		Query: dto.GetContactRequest{}, Params: []*openapi.Parameter{contactIdOrVCardParam, fieldsParam}, Data: dto.ContactResponse{}, ETag: true,
+		ResponseTypes: map[string]*openapi.Schema{"text/vcard": {Type: "string"}}},
+	{Method: http.MethodDelete, Path: "/api/contacts/:contactId", Tag: "contacts", Summary: "Move a contact to the trash",
+		Params: []*openapi.Parameter{ifMatchParam}, Data: true},
+	{Method: http.MethodPost, Path: "/api/contacts/:contactId/_restore", Tag: "contacts", Summary: "Restore a contact from the trash",
+		Data: dto.ContactResponse{}},
+	{Method: http.MethodGet, Path: "/api/contacts/:contactId/history", Tag: "history", Summary: "List a contact's changes",
+		Query: dto.ListContactHistoryRequest{}, Data: []dto.ContactHistoryResponse{}, Paged: true},
+	{Method: http.MethodPost, Path: "/api/contacts/:contactId/history/:version/_restore", Tag: "history",
+		Summary: "Revert a contact to an earlier version", Params: []*openapi.Parameter{ifMatchParam, versionParam},
+		Data: dto.ContactResponse{}, ETag: true},
+	{Method: http.MethodPut, Path: "/api/contacts/:contactId/tags/:tagId", Tag: "tags", Summary: "Tag a contact",
+		Data: dto.ContactResponse{}},
+	{Method: http.MethodDelete, Path: "/api/contacts/:contactId/tags/:tagId", Tag: "tags", Summary: "Untag a contact",
+		Data: dto.ContactResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/contacts/:contactId/addresses", Tag: "addresses", Summary: "List a contact's addresses",
+		Query: dto.ListAddressRequest{}, Params: []*openapi.Parameter{fieldsParam}, Data: []dto.AddressResponse{}, Paged: true},
+	{Method: http.MethodPost, Path: "/api/contacts/:contactId/addresses", Tag: "addresses", Summary: "Add an address",
+		Body: dto.CreateAddressRequest{}, Data: dto.AddressResponse{}, ETag: true},
+	{Method: http.MethodPut, Path: "/api/contacts/:contactId/addresses/:addressId", Tag: "addresses", Summary: "Replace an address",
+		Params: []*openapi.Parameter{ifMatchParam}, Body: dto.UpdateAddressRequest{}, Data: dto.AddressResponse{}, ETag: true},
+	{Method: http.MethodPatch, Path: "/api/contacts/:contactId/addresses/:addressId", Tag: "addresses", Summary: "Patch an address",
+		Params: []*openapi.Parameter{ifMatchParam}, BodyTypes: patchTypes, Data: dto.AddressResponse{}, ETag: true},
+	{Method: http.MethodGet, Path: "/api/contacts/:contactId/addresses/:addressId", Tag: "addresses", Summary: "Get an address",
+		Params: []*openapi.Parameter{fieldsParam}, Data: dto.AddressResponse{}, ETag: true},
+	{Method: http.MethodDelete, Path: "/api/contacts/:contactId/addresses/:addressId", Tag: "addresses", Summary: "Delete an address",
+		Params: []*openapi.Parameter{ifMatchParam}, Data: true},
+	{Method: http.MethodPost, Path: "/api/contacts/:contactId/addresses/:addressId/_primary", Tag: "addresses",
+		Summary: "Make an address the contact's primary one", Params: []*openapi.Parameter{ifMatchParam},
+		Data: dto.AddressResponse{}, ETag: true},
+	{Method: http.MethodPost, Path: "/api/contacts/:contactId/addresses/:addressId/_restore", Tag: "addresses",
+		Summary: "Restore a deleted address", Data: dto.AddressResponse{}, ETag: true},
+
+	{Method: http.MethodPut, Path: "/api/contacts/:contactId/photo", Tag: "photos", Summary: "Upload a contact's photo",
+		Params: []*openapi.Parameter{ifMatchParam}, BodyTypes: photoTypes, Data: dto.ContactResponse{}, ETag: true},
+	{Method: http.MethodGet, Path: "/api/contacts/:contactId/photo", Tag: "photos", Summary: "Download a contact's photo",
+		Query: dto.GetPhotoRequest{}, Params: []*openapi.Parameter{photoVersionParam}, ETag: true,
+		ResponseTypes: map[string]*openapi.Schema{"image/*": {Type: "string", Format: "binary"}}},
+	{Method: http.MethodDelete, Path: "/api/contacts/:contactId/photo", Tag: "photos", Summary: "Remove a contact's photo",
+		Params: []*openapi.Parameter{ifMatchParam}, Data: dto.ContactResponse{}, ETag: true},
+
+	{Method: http.MethodGet, Path: "/api/contacts/:contactId/notes", Tag: "notes", Summary: "List a contact's notes",
+		Query: dto.ListNoteRequest{}, Data: []dto.NoteResponse{}, Paged: true},
+	{Method: http.MethodPost, Path: "/api/contacts/:contactId/notes", Tag: "notes", Summary: "Add a note",
+		Body: dto.CreateNoteRequest{}, Data: dto.NoteResponse{}},
+	{Method: http.MethodPut, Path: "/api/contacts/:contactId/notes/:noteId", Tag: "notes", Summary: "Replace a note",
+		Body: dto.UpdateNoteRequest{}, Data: dto.NoteResponse{}},
+	{Method: http.MethodGet, Path: "/api/contacts/:contactId/notes/:noteId", Tag: "notes", Summary: "Get a note",
+		Data: dto.NoteResponse{}},
+	{Method: http.MethodDelete, Path: "/api/contacts/:contactId/notes/:noteId", Tag: "notes", Summary: "Delete a note",
+		Data: true},
+
+	{Method: http.MethodGet, Path: "/api/tags", Tag: "tags", Summary: "List tags", Data: []dto.TagResponse{}},
+	{Method: http.MethodPost, Path: "/api/tags", Tag: "tags", Summary: "Create a tag", Body: dto.CreateTagRequest{}, Data: dto.TagResponse{}},
+	{Method: http.MethodPut, Path: "/api/tags/:tagId", Tag: "tags", Summary: "Rename a tag", Body: dto.UpdateTagRequest{}, Data: dto.TagResponse{}},
+	{Method: http.MethodGet, Path: "/api/tags/:tagId", Tag: "tags", Summary: "Get a tag", Data: dto.TagResponse{}},
+	{Method: http.MethodDelete, Path: "/api/tags/:tagId", Tag: "tags", Summary: "Delete a tag", Data: true},
+
+	{Method: http.MethodGet, Path: "/api/custom-fields", Tag: "custom fields", Summary: "List custom fields",
+		Data: []dto.CustomFieldResponse{}},
+	{Method: http.MethodPost, Path: "/api/custom-fields", Tag: "custom fields", Summary: "Define a custom field",
+		Body: dto.CreateCustomFieldRequest{}, Data: dto.CustomFieldResponse{}},
+	{Method: http.MethodPut, Path: "/api/custom-fields/:customFieldId", Tag: "custom fields", Summary: "Update a custom field",
+		Body: dto.UpdateCustomFieldRequest{}, Data: dto.CustomFieldResponse{}},
+	{Method: http.MethodGet, Path: "/api/custom-fields/:customFieldId", Tag: "custom fields", Summary: "Get a custom field",
+		Data: dto.CustomFieldResponse{}},
+	{Method: http.MethodDelete, Path: "/api/custom-fields/:customFieldId", Tag: "custom fields", Summary: "Delete a custom field",
+		Data: true},
+
+	{Method: http.MethodGet, Path: "/api/shares", Tag: "shares", Summary: "List shares", Data: []dto.ShareResponse{}},
+	{Method: http.MethodPost, Path: "/api/shares", Tag: "shares", Summary: "Share a contact or tag", Body: dto.CreateShareRequest{},
+		Data: dto.ShareResponse{}},
+	{Method: http.MethodPut, Path: "/api/shares/:shareId", Tag: "shares", Summary: "Change a share's permission",
+		Body: dto.UpdateShareRequest{}, Data: dto.ShareResponse{}},
+	{Method: http.MethodDelete, Path: "/api/shares/:shareId", Tag: "shares", Summary: "Revoke a share", Data: true},
+
+	{Method: http.MethodPost, Path: "/api/v2/users", Tag: "v2 users", Summary: "Register a user", Public: true,
+		Body: dto.RegisterUserRequest{}, Data: dto.UserResponseV2{}},
+	{Method: http.MethodPost, Path: "/api/v2/users/_login", Tag: "v2 users", Summary: "Log in and get a token", Public: true,
+		Body: dto.LoginUserRequest{}, Data: dto.UserResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v2/users", Tag: "v2 users", Summary: "Log out", Data: true},
+	{Method: http.MethodPatch, Path: "/api/v2/users/_current", Tag: "v2 users", Summary: "Update the current user",
+		Body: dto.UpdateUserRequest{}, Data: dto.UserResponseV2{}},
+	{Method: http.MethodGet, Path: "/api/v2/users/_current", Tag: "v2 users", Summary: "Get the current user",
+		Params: []*openapi.Parameter{fieldsParam}, Data: dto.UserResponseV2{}},
+
+	{Method: http.MethodGet, Path: "/api/v2/contacts", Tag: "v2 contacts", Summary: "Search contacts",
+		Query: dto.SearchContactRequest{}, Omit: cursorOmit, Params: []*openapi.Parameter{fieldsParam, nearParam},
+		Data: []dto.ContactResponseV2{}, Paged: true, Paging: dto.CursorPageMetadata{}},
+	{Method: http.MethodPost, Path: "/api/v2/contacts", Tag: "v2 contacts", Summary: "Create a contact",
+		Body: dto.CreateContactRequest{}, Data: dto.ContactResponseV2{}, ETag: true},
+	{Method: http.MethodPut, Path: "/api/v2/contacts/:contactId", Tag: "v2 contacts", Summary: "Replace a contact",
+		Params: []*openapi.Parameter{ifMatchParam}, Body: dto.UpdateContactRequest{}, Data: dto.ContactResponseV2{}, ETag: true},
+	{Method: http.MethodPatch, Path: "/api/v2/contacts/:contactId", Tag: "v2 contacts", Summary: "Patch a contact",
+		Params: []*openapi.Parameter{ifMatchParam}, BodyTypes: patchTypes, Data: dto.ContactResponseV2{}, ETag: true},
+	{Method: http.MethodGet, Path: "/api/v2/contacts/:contactId", Tag: "v2 contacts", Summary: "Get a contact",
+		Query: dto.GetContactRequest{}, Params: []*openapi.Parameter{fieldsParam}, Data: dto.ContactResponseV2{}, ETag: true},
+	{Method: http.MethodDelete, Path: "/api/v2/contacts/:contactId", Tag: "v2 contacts", Summary: "Move a contact to the trash",
+		Params: []*openapi.Parameter{ifMatchParam}, Data: true},
+
+	{Method: http.MethodGet, Path: "/api/v2/contacts/:contactId/addresses", Tag: "v2 addresses", Summary: "List a contact's addresses",
+		Query: dto.ListAddressRequest{}, Omit: cursorOmit, Params: []*openapi.Parameter{fieldsParam},
+		Data: []dto.AddressResponseV2{}, Paged: true, Paging: dto.CursorPageMetadata{}},
+	{Method: http.MethodPost, Path: "/api/v2/contacts/:contactId/addresses", Tag: "v2 addresses", Summary: "Add an address",
+		Body: dto.CreateAddressRequest{}, Data: dto.AddressResponseV2{}, ETag: true},
+	{Method: http.MethodPut, Path: "/api/v2/contacts/:contactId/addresses/:addressId", Tag: "v2 addresses", Summary: "Replace an address",
+		Params: []*openapi.Parameter{ifMatchParam}, Body: dto.UpdateAddressRequest{}, Data: dto.AddressResponseV2{}, ETag: true},
+	{Method: http.MethodPatch, Path: "/api/v2/contacts/:contactId/addresses/:addressId", Tag: "v2 addresses", Summary: "Patch an address",
+		Params: []*openapi.Parameter{ifMatchParam}, BodyTypes: patchTypes, Data: dto.AddressResponseV2{}, ETag: true},
+	{Method: http.MethodGet, Path: "/api/v2/contacts/:contactId/addresses/:addressId", Tag: "v2 addresses", Summary: "Get an address",
+		Params: []*openapi.Parameter{fieldsParam}, Data: dto.AddressResponseV2{}, ETag: true},
+	{Method: http.MethodDelete, Path: "/api/v2/contacts/:contactId/addresses/:addressId", Tag: "v2 addresses", Summary: "Delete an address",
+		Params: []*openapi.Parameter{ifMatchParam}, Data: true},
+	{Method: http.MethodPost, Path: "/api/v2/contacts/:contactId/addresses/:addressId/_primary", Tag: "v2 addresses",
+		Summary: "Make an address the contact's primary one", Params: []*openapi.Parameter{ifMatchParam},
+		Data: dto.AddressResponseV2{}, ETag: true},
+	{Method: http.MethodPost, Path: "/api/v2/contacts/:contactId/addresses/:addressId/_restore", Tag: "v2 addresses",
+		Summary: "Restore a deleted address", Data: dto.AddressResponseV2{}, ETag: true},
+
+	{Method: http.MethodGet, Path: "/api/v2/tags", Tag: "v2 tags", Summary: "List tags", Data: []dto.TagResponseV2{}},
+	{Method: http.MethodPost, Path: "/api/v2/tags", Tag: "v2 tags", Summary: "Create a tag", Body: dto.CreateTagRequest{},
+		Data: dto.TagResponseV2{}},
+	{Method: http.MethodPut, Path: "/api/v2/tags/:tagId", Tag: "v2 tags", Summary: "Rename a tag", Body: dto.UpdateTagRequest{},
+		Data: dto.TagResponseV2{}},
+	{Method: http.MethodGet, Path: "/api/v2/tags/:tagId", Tag: "v2 tags", Summary: "Get a tag", Data: dto.TagResponseV2{}},
+	{Method: http.MethodDelete, Path: "/api/v2/tags/:tagId", Tag: "v2 tags", Summary: "Delete a tag", Data: true},
+}
+
+// Types lists every dto type, so that the document describes the ones no
+// route reads or writes directly too.
+var Types = []any{
+	dto.AddressResponse{}, dto.ListAddressRequest{}, dto.ListContactsAddressesRequest{}, dto.CreateAddressRequest{}, dto.UpdateAddressRequest{},
+	dto.PatchAddressRequest{}, dto.GetAddressRequest{}, dto.DeleteAddressRequest{}, dto.SetPrimaryAddressRequest{},
+	dto.RestoreAddressRequest{},
+	dto.Auth{},
+	dto.UpcomingDateRequest{}, dto.UpcomingDateResponse{}, dto.CalendarTokenResponse{}, dto.CreateCalendarTokenRequest{},
+	dto.DeleteCalendarTokenRequest{}, dto.CalendarFeedRequest{}, dto.CalendarFeedResponse{}, dto.CalendarEventResponse{},
+	dto.ContactResponse{}, dto.CreateContactRequest{}, dto.UpdateContactRequest{}, dto.ContactDateRequest{},
+	dto.ContactDateResponse{}, dto.PatchContactRequest{}, dto.SearchContactRequest{}, dto.GeoPoint{},
+	dto.ExportContactRequest{}, dto.GetContactRequest{}, dto.DeleteContactRequest{}, dto.SearchTrashContactRequest{},
+	dto.RestoreContactRequest{},
+	dto.ListContactHistoryRequest{}, dto.RevertContactRequest{}, dto.FieldChange{}, dto.ContactHistoryResponse{},
+	dto.CustomFieldResponse{}, dto.ListCustomFieldRequest{}, dto.CreateCustomFieldRequest{}, dto.UpdateCustomFieldRequest{},
+	dto.GetCustomFieldRequest{}, dto.DeleteCustomFieldRequest{}, dto.CustomFieldFilter{},
+	dto.PageMetadata{}, dto.ProblemResponse{}, dto.FieldError{},
+	dto.FindDuplicateContactRequest{}, dto.DuplicateGroupResponse{}, dto.MergeContactRequest{},
+	dto.GraphQLRequest{},
+	dto.ImportContactRequest{}, dto.GetImportJobRequest{}, dto.ImportRowError{}, dto.ImportJobResponse{},
+	dto.NoteResponse{}, dto.ListNoteRequest{}, dto.CreateNoteRequest{}, dto.UpdateNoteRequest{}, dto.GetNoteRequest{},
+	dto.DeleteNoteRequest{},
+	dto.OIDCLoginRequest{}, dto.OIDCLoginResponse{}, dto.OIDCCallbackRequest{},
+	dto.PhotoResponse{}, dto.PutPhotoRequest{}, dto.GetPhotoRequest{}, dto.DeletePhotoRequest{},
+	dto.ShareResponse{}, dto.ListShareRequest{}, dto.CreateShareRequest{}, dto.UpdateShareRequest{},
+	dto.DeleteShareRequest{}, dto.SharedContactResponse{}, dto.SearchSharedContactRequest{},
+	dto.TagResponse{}, dto.ListTagRequest{}, dto.CreateTagRequest{}, dto.UpdateTagRequest{}, dto.GetTagRequest{},
+	dto.DeleteTagRequest{}, dto.ContactTagRequest{},
+	dto.UserResponse{}, dto.VerifyUserRequest{}, dto.RegisterUserRequest{}, dto.UpdateUserRequest{},
+	dto.LoginUserRequest{}, dto.LogoutUserRequest{}, dto.GetUserRequest{},
+	dto.CursorPageMetadata{}, dto.UserResponseV2{}, dto.ContactResponseV2{}, dto.AddressResponseV2{}, dto.TagResponseV2{},
+}
+
+var (
+	fieldsParam = &openapi.Parameter{Name: "fields", In: "query",
+		Description: "Comma separated response members to keep, such as id,first_name",
+		Schema:      &openapi.Schema{Type: "string"}}
+	nearParam = &openapi.Parameter{Name: "near", In: "query",
+		Description: "Latitude and longitude to search around, such as 52.52,13.405",
+		Schema:      &openapi.Schema{Type: "string", Pattern: `^-?[0-9.]+,\s*-?[0-9.]+$`}}
+	exportFormatParam = &openapi.Parameter{Name: "format", In: "query",
+		Schema: &openapi.Schema{Type: "string", Enum: []any{"vcf", "csv", "json"}}}
+	ifMatchParam = &openapi.Parameter{Name: "If-Match", In: "header",
+		Description: "ETag of the version the change is based on",
+		Schema:      &openapi.Schema{Type: "string"}}
+	contactIdOrVCardParam = &openapi.Parameter{Name: "contactId", In: "path",
+		Description: "Contact ID, with .vcf appended for the contact's vCard",
+		Schema:      &openapi.Schema{Type: "string", Pattern: `^[0-9a-fA-F-]{36}(\.vcf)?$`}}
+	versionParam = &openapi.Parameter{Name: "version", In: "path",
+		Schema: &openapi.Schema{Type: "integer", Minimum: &minVersion}}
+	photoVersionParam = &openapi.Parameter{Name: "v", In: "query",
+		Description: "Photo ID from photo_url, which makes the response cacheable for good",
+		Schema:      &openapi.Schema{Type: "string"}}
+
+	minVersion = 1.0
+
+	// cursorOmit are the paging members v2 lists leave out of their queries.
+	cursorOmit = []string{"page"}
+
+	patchTypes = map[string]*openapi.Schema{
+		"application/merge-patch+json": {Type: "object"},
+		"application/json-patch+json": {Type: "array", Items: &openapi.Schema{
+			Type:     "object",
+			Required: []string{"op", "path"},
+			Properties: map[string]*openapi.Schema{
+				"op":    {Type: "string", Enum: []any{"add", "remove", "replace", "move", "copy", "test"}},
+				"path":  {Type: "string"},
+				"from":  {Type: "string"},
+				"value": {},
+			},
+		}},
+	}
+	photoTypes = map[string]*openapi.Schema{
+		"multipart/form-data": {Type: "object", Required: []string{"photo"}, Properties: map[string]*openapi.Schema{
+			"photo": {Type: "string", Format: "binary"},
+		}},
+		"*/*": {Type: "string", Format: "binary"},
+	}
+	graphQLResponse = &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+		"data": {Type: "object", Description: "Absent when the request could not be run, as with a 400"},
+		"errors": {Type: "array", Items: &openapi.Schema{
+			Type:     "object",
+			Required: []string{"message"},
+			Properties: map[string]*openapi.Schema{
+				"message": {Type: "string"},
+				"locations": {Type: "array", Items: &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+					"line":   {Type: "integer"},
+					"column": {Type: "integer"},
+				}}},
+				"path":       {Type: "array", Items: &openapi.Schema{}},
+				"extensions": {Type: "object", Description: "code is the kind of problem, and fields the invalid input fields"},
+			},
+		}},
+	}}
+	importForm = &openapi.Schema{Type: "object", Required: []string{"file"}, Properties: map[string]*openapi.Schema{
+		"file":    {Type: "string", Format: "binary"},
+		"format":  {Type: "string", Enum: []any{"csv", "vcf"}},
+		"mapping": {Type: "string", Description: "JSON object from CSV column to contact field"},
+		"dry_run": {Type: "boolean"},
+	}}
+)
+
+// OpenAPI is the document describing Routes.
+func OpenAPI() *openapi.Document {
+	routes := make([]openapi.Route, len(Routes))
+	for i, route := range Routes {
+		route.Params = slices.Clone(route.Params)
+		for _, name := range pathParams(route.Path) {
+			if strings.HasSuffix(name, "Id") && !slices.ContainsFunc(route.Params, func(p *openapi.Parameter) bool { return p.Name == name }) {
+				route.Params = append(route.Params, &openapi.Parameter{Name: name, In: "path",
+					Schema: &openapi.Schema{Type: "string", Format: "uuid"}})
+			}
+		}
+		routes[i] = route
+	}
+
+	builder := &openapi.Builder{
+		Info: openapi.Info{
+			Title:   "Contacts API",
+			Version: "2.0.0",
+			Description: "Version 1 is served under both /api and /api/v1, and described under /api. " +
+				"Version 2, under /api/v2, answers with RFC 3339 timestamps and pages with cursors only.",
+		},
+		Paging:  dto.PageMetadata{},
+		Problem: dto.ProblemResponse{},
+		SecurityScheme: &openapi.SecurityScheme{
+			Type:        "apiKey",
+			In:          "header",
+			Name:        "Authorization",
+			Description: "Token from logging in, sent as is",
+		},
+	}
+	return builder.Build(routes, Types)
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,147 @@
+package route
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http"
+)
+
+// setup registers every route on a bare echo with placeholder handlers, as
+// only their methods and paths matter here.
+func setup(v1Middleware echo.MiddlewareFunc) *echo.Echo {
+	passThrough := func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	app := echo.New()
+	routeConfig := RouteConfig{
+		App:                   app,
+		UserController:        &http.UserController{},
+		ContactController:     &http.ContactController{},
+		AddressController:     &http.AddressController{},
+		TagController:         &http.TagController{},
+		ImportController:      &http.ImportController{},
+		NoteController:        &http.NoteController{},
+		CustomFieldController: &http.CustomFieldController{},
+		ShareController:       &http.ShareController{},
+		PhotoController:       &http.PhotoController{},
+		CalendarController:    &http.CalendarController{},
+		OIDCController:        &http.OIDCController{},
+		OpenAPIController:     &http.OpenAPIController{},
+		UserV2Controller:      &http.UserV2Controller{},
+		ContactV2Controller:   &http.ContactV2Controller{},
+		AddressV2Controller:   &http.AddressV2Controller{},
+		TagV2Controller:       &http.TagV2Controller{},
+		GraphQLController:     &http.GraphQLController{},
+		AuthMiddleware:        passThrough,
+		RequestIdMiddleware:   passThrough,
+		V1Middleware:          v1Middleware,
+	}
+	routeConfig.Setup()
+	return app
+}
+
+func TestRoutesDescribed(t *testing.T) {
+	registered := map[string]bool{}
+	for _, route := range setup(nil).Routes() {
+		if route.Method != echo.RouteNotFound {
+			registered[route.Method+" "+unalias(route.Path)] = true
+		}
+	}
+
+	described := map[string]bool{}
+	for _, route := range Routes {
+		key := route.Method + " " + route.Path
+		described[key] = true
+		if !registered[key] {
+			t.Errorf("Routes describes %s, which is not registered", key)
+		}
+	}
+
+	var undocumented []string
+	for key := range registered {
+		if !described[key] {
+			undocumented = append(undocumented, key)
+		}
+	}
+	slices.Sort(undocumented)
+	for _, key := range undocumented {
+		t.Errorf("route %s is missing from Routes", key)
+	}
+}
+
+func TestTypesDescribed(t *testing.T) {
+	packages, err := parser.ParseDir(token.NewFileSet(), "../../../domain/dto", nil, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatalf("cannot read the dto package: %v", err)
+	}
+
+	schemas := OpenAPI().Components.Schemas
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					if typeSpec.TypeParams != nil || !typeSpec.Name.IsExported() {
+						continue
+					}
+					if _, ok := schemas[typeSpec.Name.Name]; !ok {
+						t.Errorf("dto.%s is missing from Types", typeSpec.Name.Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestV1MiddlewareOnlyWithSuccessor(t *testing.T) {
+	marked := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Response().Header().Set("Deprecation", "@1")
+			return ctx.NoContent(204)
+		}
+	}
+	app := setup(marked)
+
+	tests := []struct {
+		method, path string
+		deprecated   bool
+	}{
+		{"GET", "/api/v1/tags", true},
+		{"GET", "/api/tags/1", true},
+		{"DELETE", "/api/v1/contacts/1/addresses/2", true},
+		{"GET", "/api/v1/shares", false},
+		{"GET", "/api/contacts/_trash", false},
+		{"PUT", "/api/v1/contacts/1/photo", false},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		func() {
+			// Unmarked routes reach a placeholder controller, which panics.
+			defer func() { _ = recover() }()
+			app.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+		}()
+		if got := recorder.Header().Get("Deprecation") != ""; got != test.deprecated {
+			t.Errorf("%s %s deprecated = %v, want %v", test.method, test.path, got, test.deprecated)
+		}
+	}
+}
+
+// unalias turns a path served under an alias into the one Routes describes
+// it under.
+func unalias(path string) string {
+	for prefix, target := range Aliases {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			return target + rest
+		}
+	}
+	return path
+}
@@ -1,44 +1,224 @@
 package route
 
 import (
+	"strings"
+
 	"github.com/labstack/echo/v4"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http"
 )
 
 type RouteConfig struct {
-	App               *echo.Echo
-	UserController    *http.UserController
-	ContactController *http.ContactController
-	AddressController *http.AddressController
-	AuthMiddleware    echo.MiddlewareFunc
+	App                   *echo.Echo
+	UserController        *http.UserController
+	ContactController     *http.ContactController
+	AddressController     *http.AddressController
+	TagController         *http.TagController
+	ImportController      *http.ImportController
+	NoteController        *http.NoteController
+	CustomFieldController *http.CustomFieldController
+	ShareController       *http.ShareController
+	PhotoController       *http.PhotoController
+	CalendarController    *http.CalendarController
+	OIDCController        *http.OIDCController
+	OpenAPIController     *http.OpenAPIController
+	UserV2Controller      *http.UserV2Controller
+	ContactV2Controller   *http.ContactV2Controller
+	AddressV2Controller   *http.AddressV2Controller
+	TagV2Controller       *http.TagV2Controller
+	GraphQLController     *http.GraphQLController
+	AuthMiddleware        echo.MiddlewareFunc
+	RequestIdMiddleware   echo.MiddlewareFunc
+	OpenAPIMiddleware     echo.MiddlewareFunc
+	// V1Middleware, when set, runs on the v1 routes that v2 also serves, as
+	// the deprecation notice does once v1 is on its way out. v1 routes with
+	// no successor are left alone.
+	V1Middleware echo.MiddlewareFunc
 }
 
+// V1Prefixes are where v1 of the API is served. /api predates versioning and
+// stays an alias of /api/v1.
+var V1Prefixes = []string{"/api", "/api/v1"}
+
+const V2Prefix = "/api/v2"
+
+// Aliases maps route paths onto the ones they alias, by prefix, for looking
+// them up in Routes.
+var Aliases = map[string]string{"/api/v1/": "/api/"}
+
 func (c *RouteConfig) Setup() {
-	c.SetupGuestRoute()
-	c.SetupAuthRoute()
+	c.App.Use(c.RequestIdMiddleware)
+	if c.OpenAPIMiddleware != nil {
+		c.App.Use(c.OpenAPIMiddleware)
+	}
+
+	c.SetupMetaRoute()
+
+	group := c.App.Group(V2Prefix)
+	c.SetupV2GuestRoute(group)
+	c.SetupV2AuthRoute(group.Group("", c.AuthMiddleware))
+
+	var v1Middleware []echo.MiddlewareFunc
+	if c.V1Middleware != nil {
+		v1Middleware = append(v1Middleware, c.withSuccessor(c.V1Middleware))
+	}
+	for _, prefix := range V1Prefixes {
+		group := c.App.Group(prefix, v1Middleware...)
+		c.SetupGuestRoute(group)
+		c.SetupAuthRoute(group.Group("", c.AuthMiddleware))
+	}
+
+	c.SetupGraphQLRoute()
 }
 
-func (c *RouteConfig) SetupGuestRoute() {
-	c.App.POST("/api/users", c.UserController.Register)
-	c.App.POST("/api/users/_login", c.UserController.Login)
+// withSuccessor runs middleware on the v1 routes whose method and path v2
+// also registers, and skips it on the rest. The v2 routes have to be set up
+// first.
+func (c *RouteConfig) withSuccessor(middleware echo.MiddlewareFunc) echo.MiddlewareFunc {
+	successors := map[string]bool{}
+	for _, r := range c.App.Routes() {
+		if path, ok := strings.CutPrefix(r.Path, V2Prefix); ok {
+			successors[r.Method+" "+path] = true
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		deprecated := middleware(next)
+		return func(ctx echo.Context) error {
+			for _, prefix := range V1Prefixes {
+				if path, ok := strings.CutPrefix(ctx.Path(), prefix); ok && successors[ctx.Request().Method+" "+path] {
+					return deprecated(ctx)
+				}
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// SetupMetaRoute registers the routes that describe the API, which belong to
+// no version of it.
+func (c *RouteConfig) SetupMetaRoute() {
+	c.App.GET("/api/openapi.json", c.OpenAPIController.Get)
+	c.App.GET("/api/docs", c.OpenAPIController.Docs)
+}
+
+// SetupGraphQLRoute registers the GraphQL endpoint, which evolves with its
+// schema rather than with the API's versions.
+func (c *RouteConfig) SetupGraphQLRoute() {
+	c.App.GET("/api/graphql/schema.graphql", c.GraphQLController.Schema)
+	c.App.POST("/api/graphql", c.GraphQLController.Query, c.AuthMiddleware)
+}
+
+func (c *RouteConfig) SetupGuestRoute(guestGroup *echo.Group) {
+	guestGroup.POST("/users", c.UserController.Register)
+	guestGroup.POST("/users/_login", c.UserController.Login)
+	guestGroup.GET("/calendar.ics", c.CalendarController.Feed)
+
+	if c.OIDCController != nil {
+		guestGroup.GET("/auth/oidc/login", c.OIDCController.Login)
+		guestGroup.GET("/auth/oidc/callback", c.OIDCController.Callback)
+	}
 }
 
-func (c *RouteConfig) SetupAuthRoute() {
-	authGroup := c.App.Group("/api", c.AuthMiddleware)
+func (c *RouteConfig) SetupAuthRoute(authGroup *echo.Group) {
 
 	authGroup.DELETE("/users", c.UserController.Logout)
 	authGroup.PATCH("/users/_current", c.UserController.Update)
 	authGroup.GET("/users/_current", c.UserController.Current)
+	authGroup.POST("/users/_current/calendar_token", c.CalendarController.CreateToken)
+	authGroup.DELETE("/users/_current/calendar_token", c.CalendarController.DeleteToken)
+
+	if c.OIDCController != nil {
+		authGroup.POST("/users/_current/identities", c.OIDCController.Link)
+	}
 
 	authGroup.GET("/contacts", c.ContactController.List)
 	authGroup.POST("/contacts", c.ContactController.Create)
+	authGroup.GET("/contacts/_trash", c.ContactController.Trash)
+	authGroup.POST("/contacts/_import", c.ImportController.Import)
+	authGroup.GET("/contacts/_import/:jobId", c.ImportController.Get)
+	authGroup.GET("/contacts/_export", c.ContactController.Export)
+	authGroup.GET("/contacts/_shared_with_me", c.ContactController.SharedWithMe)
+	authGroup.GET("/contacts/_duplicates", c.ContactController.Duplicates)
+	authGroup.GET("/contacts/_upcoming", c.ContactController.Upcoming)
+	authGroup.POST("/contacts/_merge", c.ContactController.Merge)
 	authGroup.PUT("/contacts/:contactId", c.ContactController.Update)
+	authGroup.PATCH("/contacts/:contactId", c.ContactController.Patch)
 	authGroup.GET("/contacts/:contactId", c.ContactController.Get)
 	authGroup.DELETE("/contacts/:contactId", c.ContactController.Delete)
+	authGroup.POST("/contacts/:contactId/_restore", c.ContactController.Restore)
+	authGroup.GET("/contacts/:contactId/history", c.ContactController.History)
+	authGroup.POST("/contacts/:contactId/history/:version/_restore", c.ContactController.Revert)
+	authGroup.PUT("/contacts/:contactId/tags/:tagId", c.ContactController.AddTag)
+	authGroup.DELETE("/contacts/:contactId/tags/:tagId", c.ContactController.RemoveTag)
 
 	authGroup.GET("/contacts/:contactId/addresses", c.AddressController.List)
 	authGroup.POST("/contacts/:contactId/addresses", c.AddressController.Create)
 	authGroup.PUT("/contacts/:contactId/addresses/:addressId", c.AddressController.Update)
+	authGroup.PATCH("/contacts/:contactId/addresses/:addressId", c.AddressController.Patch)
 	authGroup.GET("/contacts/:contactId/addresses/:addressId", c.AddressController.Get)
 	authGroup.DELETE("/contacts/:contactId/addresses/:addressId", c.AddressController.Delete)
+	authGroup.POST("/contacts/:contactId/addresses/:addressId/_primary", c.AddressController.SetPrimary)
+	authGroup.POST("/contacts/:contactId/addresses/:addressId/_restore", c.AddressController.Restore)
+
+	authGroup.PUT("/contacts/:contactId/photo", c.PhotoController.Put)
+	authGroup.GET("/contacts/:contactId/photo", c.PhotoController.Get)
+	authGroup.DELETE("/contacts/:contactId/photo", c.PhotoController.Delete)
+
+	authGroup.GET("/contacts/:contactId/notes", c.NoteController.List)
+	authGroup.POST("/contacts/:contactId/notes", c.NoteController.Create)
+	authGroup.PUT("/contacts/:contactId/notes/:noteId", c.NoteController.Update)
+	authGroup.GET("/contacts/:contactId/notes/:noteId", c.NoteController.Get)
+	authGroup.DELETE("/contacts/:contactId/notes/:noteId", c.NoteController.Delete)
+
+	authGroup.GET("/tags", c.TagController.List)
+	authGroup.POST("/tags", c.TagController.Create)
+	authGroup.PUT("/tags/:tagId", c.TagController.Update)
+	authGroup.GET("/tags/:tagId", c.TagController.Get)
+	authGroup.DELETE("/tags/:tagId", c.TagController.Delete)
+
+	authGroup.GET("/custom-fields", c.CustomFieldController.List)
+	authGroup.POST("/custom-fields", c.CustomFieldController.Create)
+	authGroup.PUT("/custom-fields/:customFieldId", c.CustomFieldController.Update)
+	authGroup.GET("/custom-fields/:customFieldId", c.CustomFieldController.Get)
+	authGroup.DELETE("/custom-fields/:customFieldId", c.CustomFieldController.Delete)
+
+	authGroup.GET("/shares", c.ShareController.List)
+	authGroup.POST("/shares", c.ShareController.Create)
+	authGroup.PUT("/shares/:shareId", c.ShareController.Update)
+	authGroup.DELETE("/shares/:shareId", c.ShareController.Delete)
+}
+
+// SetupV2GuestRoute and SetupV2AuthRoute register v2 of the API. Routes whose
+// responses v2 leaves as they were share their v1 handlers.
+func (c *RouteConfig) SetupV2GuestRoute(guestGroup *echo.Group) {
+	guestGroup.POST("/users", c.UserV2Controller.Register)
+	guestGroup.POST("/users/_login", c.UserController.Login)
+}
+
+func (c *RouteConfig) SetupV2AuthRoute(authGroup *echo.Group) {
+	authGroup.DELETE("/users", c.UserController.Logout)
+	authGroup.PATCH("/users/_current", c.UserV2Controller.Update)
+	authGroup.GET("/users/_current", c.UserV2Controller.Current)
+
+	authGroup.GET("/contacts", c.ContactV2Controller.List)
+	authGroup.POST("/contacts", c.ContactV2Controller.Create)
+	authGroup.PUT("/contacts/:contactId", c.ContactV2Controller.Update)
+	authGroup.PATCH("/contacts/:contactId", c.ContactV2Controller.Patch)
+	authGroup.GET("/contacts/:contactId", c.ContactV2Controller.Get)
+	authGroup.DELETE("/contacts/:contactId", c.ContactController.Delete)
+
+	authGroup.GET("/contacts/:contactId/addresses", c.AddressV2Controller.List)
+	authGroup.POST("/contacts/:contactId/addresses", c.AddressV2Controller.Create)
+	authGroup.PUT("/contacts/:contactId/addresses/:addressId", c.AddressV2Controller.Update)
+	authGroup.PATCH("/contacts/:contactId/addresses/:addressId", c.AddressV2Controller.Patch)
+	authGroup.GET("/contacts/:contactId/addresses/:addressId", c.AddressV2Controller.Get)
+	authGroup.DELETE("/contacts/:contactId/addresses/:addressId", c.AddressController.Delete)
+	authGroup.POST("/contacts/:contactId/addresses/:addressId/_primary", c.AddressV2Controller.SetPrimary)
+	authGroup.POST("/contacts/:contactId/addresses/:addressId/_restore", c.AddressV2Controller.Restore)
+
+	authGroup.GET("/tags", c.TagV2Controller.List)
+	authGroup.POST("/tags", c.TagV2Controller.Create)
+	authGroup.PUT("/tags/:tagId", c.TagV2Controller.Update)
+	authGroup.GET("/tags/:tagId", c.TagV2Controller.Get)
+	authGroup.DELETE("/tags/:tagId", c.TagController.Delete)
 }
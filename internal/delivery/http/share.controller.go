@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type ShareController struct {
+	UseCase *usecase.ShareUseCase
+	Log     *zap.Logger
+}
+
+func NewShareController(useCase *usecase.ShareUseCase, log *zap.Logger) *ShareController {
+	return &ShareController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *ShareController) Create(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.CreateShareRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Create(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating share")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ShareResponse]{Data: response})
+}
+
+func (c *ShareController) List(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.ListShareRequest{
+		UserId: auth.ID,
+	}
+
+	responses, err := c.UseCase.List(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error listing shares")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[[]dto.ShareResponse]{Data: responses})
+}
+
+func (c *ShareController) Update(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.UpdateShareRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	request.ID = ctx.Param("shareId")
+
+	response, err := c.UseCase.Update(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating share")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.ShareResponse]{Data: response})
+}
+
+func (c *ShareController) Delete(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.DeleteShareRequest{
+		UserId: auth.ID,
+		ID:     ctx.Param("shareId"),
+	}
+
+	if err := c.UseCase.Delete(ctx.Request().Context(), request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting share")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[bool]{Data: true})
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type TagV2Controller struct {
+	UseCase *usecase.TagUseCase
+	Log     *zap.Logger
+}
+
+func NewTagV2Controller(useCase *usecase.TagUseCase, log *zap.Logger) *TagV2Controller {
+	return &TagV2Controller{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *TagV2Controller) Create(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.CreateTagRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Create(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating tag")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.TagResponseV2]{Data: converter.TagResponseToV2(response)})
+}
+
+func (c *TagV2Controller) List(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.ListTagRequest{
+		UserId: auth.ID,
+	}
+
+	responses, err := c.UseCase.List(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error listing tags")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[[]dto.TagResponseV2]{Data: converter.TagResponsesToV2(responses)})
+}
+
+func (c *TagV2Controller) Get(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &dto.GetTagRequest{
+		UserId: auth.ID,
+		ID:     ctx.Param("tagId"),
+	}
+
+	response, err := c.UseCase.Get(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tag")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.TagResponseV2]{Data: converter.TagResponseToV2(response)})
+}
+
+func (c *TagV2Controller) Update(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.UpdateTagRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error parsing request body")
+		return echo.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	request.ID = ctx.Param("tagId")
+
+	response, err := c.UseCase.Update(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating tag")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.TagResponseV2]{Data: converter.TagResponseToV2(response)})
+}
@@ -58,6 +58,11 @@ func (c *UserController) Login(ctx echo.Context) error {
 func (c *UserController) Current(ctx echo.Context) error {
 	auth := middleware.GetUser(ctx)
 
+	fields, err := parseFields[dto.UserResponse](ctx)
+	if err != nil {
+		return err
+	}
+
 	request := &dto.GetUserRequest{
 		ID: auth.ID,
 	}
@@ -68,7 +73,13 @@ func (c *UserController) Current(ctx echo.Context) error {
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, dto.WebResponse[*dto.UserResponse]{Data: response})
+	data, err := fields.pick(response)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("Failed to pick user fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: data})
 }
 
 func (c *UserController) Logout(ctx echo.Context) error {
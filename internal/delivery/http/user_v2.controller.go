@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/delivery/http/middleware"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type UserV2Controller struct {
+	Log     *zap.Logger
+	UseCase *usecase.UserUseCase
+}
+
+func NewUserV2Controller(useCase *usecase.UserUseCase, logger *zap.Logger) *UserV2Controller {
+	return &UserV2Controller{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *UserV2Controller) Register(ctx echo.Context) error {
+	request := new(dto.RegisterUserRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.Warn("Failed to parse request body", zap.Error(err))
+		return echo.ErrBadRequest
+	}
+
+	response, err := c.UseCase.Create(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.Warn("Failed to register user", zap.Error(err))
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.UserResponseV2]{Data: converter.UserResponseToV2(response)})
+}
+
+func (c *UserV2Controller) Current(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	fields, err := parseFields[dto.UserResponseV2](ctx)
+	if err != nil {
+		return err
+	}
+
+	request := &dto.GetUserRequest{
+		ID: auth.ID,
+	}
+
+	response, err := c.UseCase.Current(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("Failed to get current user")
+		return err
+	}
+
+	data, err := fields.pick(converter.UserResponseToV2(response))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("Failed to pick user fields")
+		return echo.ErrInternalServerError
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[any]{Data: data})
+}
+
+func (c *UserV2Controller) Update(ctx echo.Context) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(dto.UpdateUserRequest)
+	if err := ctx.Bind(request); err != nil {
+		c.Log.Warn("Failed to parse request body", zap.Error(err))
+		return echo.ErrBadRequest
+	}
+
+	request.ID = auth.ID
+	response, err := c.UseCase.Update(ctx.Request().Context(), request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("Failed to update user")
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponseV2[*dto.UserResponseV2]{Data: converter.UserResponseToV2(response)})
+}
@@ -0,0 +1,44 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type ReminderJob struct {
+	UseCase  *usecase.ReminderUseCase
+	Log      *zap.Logger
+	Interval time.Duration
+}
+
+func NewReminderJob(useCase *usecase.ReminderUseCase, log *zap.Logger, interval time.Duration) *ReminderJob {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &ReminderJob{
+		UseCase:  useCase,
+		Log:      log,
+		Interval: interval,
+	}
+}
+
+func (j *ReminderJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.UseCase.Send(ctx); err != nil {
+			j.Log.With(zap.Error(err)).Error("sending reminders failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
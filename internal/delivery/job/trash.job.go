@@ -0,0 +1,46 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type TrashPurgeJob struct {
+	UseCase   *usecase.TrashUseCase
+	Log       *zap.Logger
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func NewTrashPurgeJob(useCase *usecase.TrashUseCase, log *zap.Logger, retention time.Duration, interval time.Duration) *TrashPurgeJob {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &TrashPurgeJob{
+		UseCase:   useCase,
+		Log:       log,
+		Retention: retention,
+		Interval:  interval,
+	}
+}
+
+func (j *TrashPurgeJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.UseCase.Purge(ctx, j.Retention); err != nil {
+			j.Log.With(zap.Error(err)).Error("trash purge failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
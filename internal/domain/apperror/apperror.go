@@ -0,0 +1,163 @@
+// Package apperror is how use cases report failures without knowing about
+// HTTP. Each Error has a Kind, which the delivery layer maps to a status code,
+// a Message that is safe to show clients and, for validation failures, the
+// offending fields. The cause, if any, is kept for logs only.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+)
+
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	// KindPrecondition is an If-Match precondition that no longer holds.
+	KindPrecondition Kind = "precondition_failed"
+	KindTooLarge     Kind = "too_large"
+	KindUnsupported  Kind = "unsupported_media_type"
+	// KindUpstream is a failure of a service the use case depends on, such
+	// as an identity provider.
+	KindUpstream Kind = "upstream"
+	KindInternal Kind = "internal"
+)
+
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  []dto.FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrNotFound     = &Error{Kind: KindNotFound, Message: "resource not found"}
+	ErrConflict     = &Error{Kind: KindConflict, Message: "resource already exists"}
+	ErrUnauthorized = &Error{Kind: KindUnauthorized, Message: "authentication required"}
+	ErrForbidden    = &Error{Kind: KindForbidden, Message: "not allowed"}
+	ErrUpstream     = &Error{Kind: KindUpstream, Message: "upstream service failed"}
+	ErrInternal     = &Error{Kind: KindInternal, Message: "internal error"}
+)
+
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Invalid is a validation failure found by hand rather than by the
+// validator, optionally naming the offending fields.
+func Invalid(message string, fields ...dto.FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+// Validation is the validation failure for an error from the validator,
+// listing each failed field.
+func Validation(err error) *Error {
+	return &Error{Kind: KindValidation, Message: "request is invalid", Fields: Fields(err), Err: err}
+}
+
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+// As finds the Error in err's chain.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	ok := errors.As(err, &appErr)
+	return appErr, ok
+}
+
+// Fields translates the validator's errors into field errors named by JSON
+// member, or returns nil when err does not come from the validator.
+func Fields(err error) []dto.FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := make([]dto.FieldError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		fields[i] = dto.FieldError{Field: fieldPath(fieldError), Message: Message(fieldError)}
+	}
+	return fields
+}
+
+// fieldPath is the field's path below the validated struct, such as
+// "dates[0].type".
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+	return path
+}
+
+// Message says in words what the field failed.
+func Message(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required", "required_if":
+		return "is required"
+	case "excluded_unless", "excluded_with":
+		return "must not be set here"
+	case "max":
+		return "must be at most " + bound(fieldError)
+	case "min":
+		return "must be at least " + bound(fieldError)
+	case "email":
+		return "must be a valid email address"
+	case "uuid":
+		return "must be a valid UUID"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fieldError.Param())
+	case "latitude":
+		return "must be a latitude between -90 and 90"
+	case "longitude":
+		return "must be a longitude between -180 and 180"
+	case "field_name":
+		return "must start with a lowercase letter and hold only lowercase letters, digits and underscores"
+	default:
+		return fmt.Sprintf("failed %q validation", fieldError.Tag())
+	}
+}
+
+// bound words the parameter of a min or max tag in what the field's kind
+// measures: characters of a string, items of a slice or map, or else the
+// number itself.
+func bound(fieldError validator.FieldError) string {
+	unit := ""
+	switch fieldError.Kind() {
+	case reflect.String:
+		unit = "character"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = "item"
+	default:
+		return fieldError.Param()
+	}
+	if fieldError.Param() != "1" {
+		unit += "s"
+	}
+	return fieldError.Param() + " " + unit
+}
@@ -3,6 +3,7 @@ package converter
 import (
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/postal"
 )
 
 func AddressToResponse(address *entity.Address) *dto.AddressResponse {
@@ -13,7 +14,20 @@ func AddressToResponse(address *entity.Address) *dto.AddressResponse {
 		Province:   address.Province,
 		PostalCode: address.PostalCode,
 		Country:    address.Country,
-		CreatedAt:  address.CreatedAt,
-		UpdatedAt:  address.UpdatedAt,
+		Formatted: postal.Format(postal.Address{
+			Street:     address.Street,
+			City:       address.City,
+			Province:   address.Province,
+			PostalCode: address.PostalCode,
+			Country:    address.Country,
+		}),
+		Label:       address.Label,
+		CustomLabel: address.CustomLabel,
+		IsPrimary:   address.IsPrimary,
+		Latitude:    address.Latitude,
+		Longitude:   address.Longitude,
+		CreatedAt:   address.CreatedAt,
+		UpdatedAt:   address.UpdatedAt,
+		Version:     address.Version,
 	}
 }
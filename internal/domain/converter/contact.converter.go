@@ -1,18 +1,93 @@
 package converter
 
 import (
+	"fmt"
+	"html"
+	"strings"
+
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
 )
 
 func ContactToResponse(contact *entity.Contact) *dto.ContactResponse {
-	return &dto.ContactResponse{
-		ID:        contact.ID,
-		FirstName: contact.FirstName,
-		LastName:  contact.LastName,
-		Email:     contact.Email,
-		Phone:     contact.Phone,
-		CreatedAt: contact.CreatedAt,
-		UpdatedAt: contact.UpdatedAt,
+	tags := make([]dto.TagResponse, len(contact.Tags))
+	for i, tag := range contact.Tags {
+		tags[i] = *TagToResponse(&tag)
+	}
+
+	var addresses []dto.AddressResponse
+	if contact.Addresses != nil {
+		addresses = make([]dto.AddressResponse, len(contact.Addresses))
+		for i, address := range contact.Addresses {
+			addresses[i] = *AddressToResponse(&address)
+		}
+	}
+
+	customFields := contact.CustomFields
+	if customFields == nil {
+		customFields = map[string]any{}
+	}
+
+	dates := make([]dto.ContactDateResponse, len(contact.Dates))
+	for i, date := range contact.Dates {
+		dates[i] = ContactDateToResponse(date)
+	}
+
+	response := &dto.ContactResponse{
+		ID:                contact.ID,
+		FirstName:         contact.FirstName,
+		LastName:          contact.LastName,
+		Email:             contact.Email,
+		Phone:             contact.Phone,
+		PhoneE164:         contact.PhoneE164,
+		CreatedAt:         contact.CreatedAt,
+		UpdatedAt:         contact.UpdatedAt,
+		DeletedAt:         int64(contact.DeletedAt),
+		Version:           contact.Version,
+		LastInteractionAt: contact.LastInteractionAt,
+		CustomFields:      customFields,
+		Dates:             dates,
+		Rank:              contact.Rank,
+		Highlight:         highlightHTML(contact.Highlight),
+		DistanceKm:        contact.Distance,
+		Tags:              tags,
+		Addresses:         addresses,
+	}
+
+	if contact.PrimaryAddress != nil {
+		response.PrimaryAddress = AddressToResponse(contact.PrimaryAddress)
 	}
+
+	// The photo id in the query changes with every upload, so clients and
+	// caches never hold on to a replaced photo.
+	if contact.PhotoId != "" {
+		photoURL := "/api/contacts/" + contact.ID + "/photo"
+		response.PhotoURL = photoURL + "?v=" + contact.PhotoId
+		response.ThumbnailURL = photoURL + "?size=thumbnail&v=" + contact.PhotoId
+	}
+
+	return response
+}
+
+// ContactDateToResponse writes a date without a year in the --MM-DD form
+// vCard uses.
+func ContactDateToResponse(date entity.ContactDate) dto.ContactDateResponse {
+	formatted := fmt.Sprintf("--%02d-%02d", date.Month, date.Day)
+	if date.Year != 0 {
+		formatted = fmt.Sprintf("%04d-%02d-%02d", date.Year, date.Month, date.Day)
+	}
+
+	return dto.ContactDateResponse{
+		Type:  date.Type,
+		Label: date.Label,
+		Date:  formatted,
+	}
+}
+
+var highlightMarks = strings.NewReplacer(entity.HighlightStart, "<mark>", entity.HighlightStop, "</mark>")
+
+// highlightHTML escapes a highlight snippet, so that <mark> is the only markup
+// in it.
+func highlightHTML(highlight string) string {
+	return highlightMarks.Replace(html.EscapeString(highlight))
 }
@@ -0,0 +1,25 @@
+package converter
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+func ContactHistoryToResponse(history *entity.ContactHistory) *dto.ContactHistoryResponse {
+	changes := make(map[string]dto.FieldChange, len(history.Changes))
+	for field, change := range history.Changes {
+		changes[field] = dto.FieldChange{Old: change.Old, New: change.New}
+	}
+
+	return &dto.ContactHistoryResponse{
+		ID:         history.ID,
+		EntityType: history.EntityType,
+		EntityId:   history.EntityId,
+		Action:     history.Action,
+		Version:    history.Version,
+		ActorId:    history.ActorId,
+		RequestId:  history.RequestId,
+		Changes:    changes,
+		CreatedAt:  history.CreatedAt,
+	}
+}
@@ -0,0 +1,18 @@
+package converter
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+func CustomFieldToResponse(field *entity.CustomField) *dto.CustomFieldResponse {
+	return &dto.CustomFieldResponse{
+		ID:        field.ID,
+		Name:      field.Name,
+		Type:      field.Type,
+		Required:  field.Required,
+		Options:   field.Options,
+		CreatedAt: field.CreatedAt,
+		UpdatedAt: field.UpdatedAt,
+	}
+}
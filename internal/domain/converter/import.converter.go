@@ -0,0 +1,30 @@
+package converter
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+func ImportJobToResponse(job *entity.ImportJob) *dto.ImportJobResponse {
+	errors := make([]dto.ImportRowError, len(job.Errors))
+	for i, rowError := range job.Errors {
+		errors[i] = dto.ImportRowError{
+			Row:     rowError.Row,
+			Field:   rowError.Field,
+			Message: rowError.Message,
+		}
+	}
+
+	return &dto.ImportJobResponse{
+		ID:           job.ID,
+		Status:       job.Status,
+		Format:       job.Format,
+		TotalRows:    job.TotalRows,
+		ImportedRows: job.ImportedRows,
+		FailedRows:   job.FailedRows,
+		Errors:       errors,
+		CreatedAt:    job.CreatedAt,
+		UpdatedAt:    job.UpdatedAt,
+		FinishedAt:   job.FinishedAt,
+	}
+}
@@ -0,0 +1,19 @@
+package converter
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+func NoteToResponse(note *entity.Note) *dto.NoteResponse {
+	return &dto.NoteResponse{
+		ID:         note.ID,
+		Type:       note.Type,
+		Body:       note.Body,
+		OccurredAt: note.OccurredAt,
+		CreatedBy:  note.CreatedBy,
+		UpdatedBy:  note.UpdatedBy,
+		CreatedAt:  note.CreatedAt,
+		UpdatedAt:  note.UpdatedAt,
+	}
+}
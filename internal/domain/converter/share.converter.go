@@ -0,0 +1,31 @@
+package converter
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+func ShareToResponse(share *entity.Share) *dto.ShareResponse {
+	response := &dto.ShareResponse{
+		ID:         share.ID,
+		GranteeId:  share.GranteeId,
+		Permission: share.Permission,
+		CreatedAt:  share.CreatedAt,
+		UpdatedAt:  share.UpdatedAt,
+	}
+	if share.ContactId != nil {
+		response.ContactId = *share.ContactId
+	}
+	if share.TagId != nil {
+		response.TagId = *share.TagId
+	}
+	return response
+}
+
+func SharedContactToResponse(contact *entity.Contact) *dto.SharedContactResponse {
+	return &dto.SharedContactResponse{
+		ContactResponse: *ContactToResponse(contact),
+		OwnerId:         contact.UserId,
+		Permission:      contact.SharePermission,
+	}
+}
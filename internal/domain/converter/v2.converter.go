@@ -0,0 +1,141 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+)
+
+// The use cases answer in v1 responses; these turn them into the v2 ones.
+
+func UserResponseToV2(user *dto.UserResponse) *dto.UserResponseV2 {
+	return &dto.UserResponseV2{
+		ID:        user.ID,
+		Name:      user.Name,
+		Token:     user.Token,
+		CreatedAt: optionalTime(user.CreatedAt),
+		UpdatedAt: optionalTime(user.UpdatedAt),
+	}
+}
+
+func ContactResponseToV2(contact *dto.ContactResponse) *dto.ContactResponseV2 {
+	tags := make([]dto.TagResponseV2, len(contact.Tags))
+	for i, tag := range contact.Tags {
+		tags[i] = *TagResponseToV2(&tag)
+	}
+
+	var addresses []dto.AddressResponseV2
+	if contact.Addresses != nil {
+		addresses = make([]dto.AddressResponseV2, len(contact.Addresses))
+		for i, address := range contact.Addresses {
+			addresses[i] = *AddressResponseToV2(&address)
+		}
+	}
+
+	response := &dto.ContactResponseV2{
+		ID:                contact.ID,
+		FirstName:         contact.FirstName,
+		LastName:          contact.LastName,
+		Email:             contact.Email,
+		Phone:             contact.Phone,
+		PhoneE164:         contact.PhoneE164,
+		CreatedAt:         millisToTime(contact.CreatedAt),
+		UpdatedAt:         millisToTime(contact.UpdatedAt),
+		DeletedAt:         optionalTime(contact.DeletedAt),
+		Version:           contact.Version,
+		CustomFields:      contact.CustomFields,
+		Dates:             contact.Dates,
+		LastInteractionAt: optionalTime(contact.LastInteractionAt),
+		PhotoURL:          contact.PhotoURL,
+		ThumbnailURL:      contact.ThumbnailURL,
+		Rank:              contact.Rank,
+		Highlight:         contact.Highlight,
+		DistanceKm:        contact.DistanceKm,
+		Tags:              tags,
+		Addresses:         addresses,
+	}
+
+	if contact.PrimaryAddress != nil {
+		response.PrimaryAddress = AddressResponseToV2(contact.PrimaryAddress)
+	}
+
+	return response
+}
+
+func ContactResponsesToV2(contacts []dto.ContactResponse) []dto.ContactResponseV2 {
+	responses := make([]dto.ContactResponseV2, len(contacts))
+	for i, contact := range contacts {
+		responses[i] = *ContactResponseToV2(&contact)
+	}
+	return responses
+}
+
+func AddressResponseToV2(address *dto.AddressResponse) *dto.AddressResponseV2 {
+	return &dto.AddressResponseV2{
+		ID:          address.ID,
+		Street:      address.Street,
+		City:        address.City,
+		Province:    address.Province,
+		PostalCode:  address.PostalCode,
+		Country:     address.Country,
+		Formatted:   address.Formatted,
+		Label:       address.Label,
+		CustomLabel: address.CustomLabel,
+		IsPrimary:   address.IsPrimary,
+		Latitude:    address.Latitude,
+		Longitude:   address.Longitude,
+		CreatedAt:   millisToTime(address.CreatedAt),
+		UpdatedAt:   millisToTime(address.UpdatedAt),
+		Version:     address.Version,
+	}
+}
+
+func AddressResponsesToV2(addresses []dto.AddressResponse) []dto.AddressResponseV2 {
+	responses := make([]dto.AddressResponseV2, len(addresses))
+	for i, address := range addresses {
+		responses[i] = *AddressResponseToV2(&address)
+	}
+	return responses
+}
+
+func TagResponseToV2(tag *dto.TagResponse) *dto.TagResponseV2 {
+	return &dto.TagResponseV2{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		CreatedAt: millisToTime(tag.CreatedAt),
+		UpdatedAt: millisToTime(tag.UpdatedAt),
+	}
+}
+
+func TagResponsesToV2(tags []dto.TagResponse) []dto.TagResponseV2 {
+	responses := make([]dto.TagResponseV2, len(tags))
+	for i, tag := range tags {
+		responses[i] = *TagResponseToV2(&tag)
+	}
+	return responses
+}
+
+func PageMetadataToV2(metadata *dto.PageMetadata) *dto.CursorPageMetadata {
+	if metadata == nil {
+		return nil
+	}
+	return &dto.CursorPageMetadata{
+		Size:       metadata.Size,
+		TotalItem:  metadata.TotalItem,
+		NextCursor: metadata.NextCursor,
+		PrevCursor: metadata.PrevCursor,
+	}
+}
+
+func millisToTime(millis int64) time.Time {
+	return time.UnixMilli(millis).UTC()
+}
+
+// optionalTime leaves out timestamps that are not set, which v1 sends as 0.
+func optionalTime(millis int64) *time.Time {
+	if millis == 0 {
+		return nil
+	}
+	t := millisToTime(millis)
+	return &t
+}
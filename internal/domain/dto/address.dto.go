@@ -1,40 +1,79 @@
 package dto
 
 type AddressResponse struct {
-	ID         string `json:"id"`
-	Street     string `json:"street"`
-	City       string `json:"city"`
-	Province   string `json:"province"`
-	PostalCode string `json:"postal_code"`
-	Country    string `json:"country"`
-	CreatedAt  int64  `json:"created_at"`
-	UpdatedAt  int64  `json:"updated_at"`
+	ID          string   `json:"id"`
+	Street      string   `json:"street"`
+	City        string   `json:"city"`
+	Province    string   `json:"province"`
+	PostalCode  string   `json:"postal_code"`
+	Country     string   `json:"country"`
+	Formatted   string   `json:"formatted"`
+	Label       string   `json:"label,omitempty"`
+	CustomLabel string   `json:"custom_label,omitempty"`
+	IsPrimary   bool     `json:"is_primary"`
+	Latitude    *float64 `json:"latitude,omitempty"`
+	Longitude   *float64 `json:"longitude,omitempty"`
+	CreatedAt   int64    `json:"created_at"`
+	UpdatedAt   int64    `json:"updated_at"`
+	Version     int64    `json:"version"`
 }
 
 type ListAddressRequest struct {
-	UserId    string `json:"-" validate:"required"`
-	ContactId string `json:"-" validate:"required,max=100,uuid"`
+	UserId       string `json:"-" validate:"required"`
+	ContactId    string `json:"-" validate:"required,max=100,uuid"`
+	Sort         string `json:"sort" validate:"max=200"`
+	After        string `json:"after" validate:"max=1024"`
+	Before       string `json:"before" validate:"max=1024,excluded_with=After"`
+	IncludeTotal bool   `json:"include_total"`
+	Page         int    `json:"page" validate:"min=1"`
+	Size         int    `json:"size" validate:"min=1,max=100"`
+}
+
+// ListContactsAddressesRequest reads the addresses of several contacts at
+// once, leaving out the contacts the user cannot read.
+type ListContactsAddressesRequest struct {
+	UserId     string   `json:"-" validate:"required"`
+	ContactIds []string `json:"-" validate:"max=1000,dive,max=100,uuid"`
 }
 
 type CreateAddressRequest struct {
-	UserId     string `json:"-" validate:"required"`
-	ContactId  string `json:"-" validate:"required,max=100,uuid"`
-	Street     string `json:"street" validate:"max=255"`
-	City       string `json:"city" validate:"max=255"`
-	Province   string `json:"province" validate:"max=255"`
-	PostalCode string `json:"postal_code" validate:"max=10"`
-	Country    string `json:"country" validate:"max=100"`
+	UserId      string `json:"-" validate:"required"`
+	ContactId   string `json:"-" validate:"required,max=100,uuid"`
+	Street      string `json:"street" validate:"max=255"`
+	City        string `json:"city" validate:"max=255"`
+	Province    string `json:"province" validate:"max=255"`
+	PostalCode  string `json:"postal_code" validate:"max=10"`
+	Country     string `json:"country" validate:"max=100"`
+	Label       string `json:"label" validate:"omitempty,oneof=home work billing shipping other custom"`
+	CustomLabel string `json:"custom_label" validate:"required_if=Label custom,excluded_unless=Label custom,max=50"`
+	// IsPrimary makes the new address the contact's primary one. A contact's
+	// first address is primary either way.
+	IsPrimary bool `json:"is_primary"`
 }
 
 type UpdateAddressRequest struct {
-	UserId     string `json:"-" validate:"required"`
-	ContactId  string `json:"-" validate:"required,max=100,uuid"`
-	ID         string `json:"-" validate:"required,max=100,uuid"`
-	Street     string `json:"street" validate:"max=255"`
-	City       string `json:"city" validate:"max=255"`
-	Province   string `json:"province" validate:"max=255"`
-	PostalCode string `json:"postal_code" validate:"max=10"`
-	Country    string `json:"country" validate:"max=100"`
+	UserId      string  `json:"-" validate:"required"`
+	ContactId   string  `json:"-" validate:"required,max=100,uuid"`
+	ID          string  `json:"-" validate:"required,max=100,uuid"`
+	IfMatch     []int64 `json:"-"`
+	Street      string  `json:"street" validate:"max=255"`
+	City        string  `json:"city" validate:"max=255"`
+	Province    string  `json:"province" validate:"max=255"`
+	PostalCode  string  `json:"postal_code" validate:"max=10"`
+	Country     string  `json:"country" validate:"max=100"`
+	Label       string  `json:"label" validate:"omitempty,oneof=home work billing shipping other custom"`
+	CustomLabel string  `json:"custom_label" validate:"required_if=Label custom,excluded_unless=Label custom,max=50"`
+}
+
+// PatchAddressRequest is PatchContactRequest for the UpdateAddressRequest
+// form of an address.
+type PatchAddressRequest struct {
+	UserId    string  `json:"-" validate:"required"`
+	ContactId string  `json:"-" validate:"required,max=100,uuid"`
+	ID        string  `json:"-" validate:"required,max=100,uuid"`
+	IfMatch   []int64 `json:"-"`
+	Format    string  `json:"-" validate:"required,oneof=merge json"`
+	Patch     []byte  `json:"-" validate:"required"`
 }
 
 type GetAddressRequest struct {
@@ -44,7 +83,23 @@ type GetAddressRequest struct {
 }
 
 type DeleteAddressRequest struct {
+	UserId    string  `json:"-" validate:"required"`
+	ContactId string  `json:"-" validate:"required,max=100,uuid"`
+	ID        string  `json:"-" validate:"required,max=100,uuid"`
+	IfMatch   []int64 `json:"-"`
+}
+
+type RestoreAddressRequest struct {
 	UserId    string `json:"-" validate:"required"`
 	ContactId string `json:"-" validate:"required,max=100,uuid"`
 	ID        string `json:"-" validate:"required,max=100,uuid"`
 }
+
+// SetPrimaryAddressRequest makes the address its contact's primary one;
+// IfMatch applies to the address being promoted.
+type SetPrimaryAddressRequest struct {
+	UserId    string  `json:"-" validate:"required"`
+	ContactId string  `json:"-" validate:"required,max=100,uuid"`
+	ID        string  `json:"-" validate:"required,max=100,uuid"`
+	IfMatch   []int64 `json:"-"`
+}
@@ -2,4 +2,4 @@ package dto
 
 type Auth struct {
 	ID string
-}
\ No newline at end of file
+}
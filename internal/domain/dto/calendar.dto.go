@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+type UpcomingDateRequest struct {
+	UserId string `json:"-" validate:"required"`
+	Days   int    `json:"days" validate:"min=1,max=366"`
+}
+
+// UpcomingDateResponse is the next occurrence of a contact date. Years is
+// the age or the number of years married, when the year is known.
+type UpcomingDateResponse struct {
+	Contact   ContactResponse `json:"contact"`
+	Type      string          `json:"type"`
+	Label     string          `json:"label,omitempty"`
+	Date      string          `json:"date"`
+	OccursOn  string          `json:"occurs_on"`
+	DaysUntil int             `json:"days_until"`
+	Years     int             `json:"years,omitempty"`
+}
+
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type CreateCalendarTokenRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+type DeleteCalendarTokenRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+type CalendarFeedRequest struct {
+	Token string `json:"token" validate:"required,max=100"`
+}
+
+type CalendarFeedResponse struct {
+	Name   string                  `json:"name"`
+	Events []CalendarEventResponse `json:"events"`
+}
+
+// CalendarEventResponse is a contact date as an all-day event that repeats
+// every year from Start.
+type CalendarEventResponse struct {
+	UID         string    `json:"uid"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+}
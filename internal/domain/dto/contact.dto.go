@@ -1,48 +1,143 @@
 package dto
 
 type ContactResponse struct {
-	ID        string            `json:"id"`
-	FirstName string            `json:"first_name"`
-	LastName  string            `json:"last_name"`
-	Email     string            `json:"email"`
-	Phone     string            `json:"phone"`
-	CreatedAt int64             `json:"created_at"`
-	UpdatedAt int64             `json:"updated_at"`
-	Addresses []AddressResponse `json:"addresses,omitempty"`
+	ID                string                `json:"id"`
+	FirstName         string                `json:"first_name"`
+	LastName          string                `json:"last_name"`
+	Email             string                `json:"email"`
+	Phone             string                `json:"phone"`
+	PhoneE164         string                `json:"phone_e164"`
+	CreatedAt         int64                 `json:"created_at"`
+	UpdatedAt         int64                 `json:"updated_at"`
+	DeletedAt         int64                 `json:"deleted_at,omitempty"`
+	Version           int64                 `json:"version"`
+	CustomFields      map[string]any        `json:"custom_fields"`
+	Dates             []ContactDateResponse `json:"dates"`
+	LastInteractionAt int64                 `json:"last_interaction_at,omitempty"`
+	PhotoURL          string                `json:"photo_url,omitempty"`
+	ThumbnailURL      string                `json:"thumbnail_url,omitempty"`
+	Rank              float64               `json:"rank,omitempty"`
+	Highlight         string                `json:"highlight,omitempty"`
+	DistanceKm        *float64              `json:"distance_km,omitempty"`
+	Tags              []TagResponse         `json:"tags"`
+	Addresses         []AddressResponse     `json:"addresses,omitempty"`
+	PrimaryAddress    *AddressResponse      `json:"primary_address,omitempty"`
 }
 
 type CreateContactRequest struct {
-	UserId    string `json:"-" validate:"required"`
-	FirstName string `json:"first_name" validate:"required,max=100"`
-	LastName  string `json:"last_name" validate:"max=100"`
-	Email     string `json:"email" validate:"max=200,email"`
-	Phone     string `json:"phone" validate:"max=20"`
+	UserId       string               `json:"-" validate:"required"`
+	FirstName    string               `json:"first_name" validate:"required,max=100"`
+	LastName     string               `json:"last_name" validate:"max=100"`
+	Email        string               `json:"email" validate:"max=200,email"`
+	Phone        string               `json:"phone" validate:"max=20"`
+	CustomFields map[string]any       `json:"custom_fields" validate:"max=100"`
+	Dates        []ContactDateRequest `json:"dates" validate:"max=50,dive"`
 }
 
 type UpdateContactRequest struct {
-	UserId    string `json:"-" validate:"required"`
-	ID        string `json:"-" validate:"required,max=100,uuid"`
-	FirstName string `json:"first_name" validate:"required,max=100"`
-	LastName  string `json:"last_name" validate:"max=100"`
-	Email     string `json:"email" validate:"max=200,email"`
-	Phone     string `json:"phone" validate:"max=20"`
+	UserId       string               `json:"-" validate:"required"`
+	ID           string               `json:"-" validate:"required,max=100,uuid"`
+	IfMatch      []int64              `json:"-"`
+	FirstName    string               `json:"first_name" validate:"required,max=100"`
+	LastName     string               `json:"last_name" validate:"max=100"`
+	Email        string               `json:"email" validate:"max=200,email"`
+	Phone        string               `json:"phone" validate:"max=20"`
+	CustomFields map[string]any       `json:"custom_fields" validate:"max=100"`
+	Dates        []ContactDateRequest `json:"dates" validate:"max=50,dive"`
+}
+
+// ContactDateRequest carries the date as YYYY-MM-DD, or as --MM-DD when the
+// year is not known.
+type ContactDateRequest struct {
+	Type  string `json:"type" validate:"required,oneof=birthday anniversary custom"`
+	Label string `json:"label,omitempty" validate:"required_if=Type custom,excluded_unless=Type custom,max=100"`
+	Date  string `json:"date" validate:"required,max=10"`
+}
+
+type ContactDateResponse struct {
+	Type  string `json:"type"`
+	Label string `json:"label,omitempty"`
+	Date  string `json:"date"`
+}
+
+// PatchContactRequest carries a merge patch (Format "merge", RFC 7396) or a
+// JSON Patch (Format "json", RFC 6902) against the UpdateContactRequest form
+// of the contact.
+type PatchContactRequest struct {
+	UserId  string  `json:"-" validate:"required"`
+	ID      string  `json:"-" validate:"required,max=100,uuid"`
+	IfMatch []int64 `json:"-"`
+	Format  string  `json:"-" validate:"required,oneof=merge json"`
+	Patch   []byte  `json:"-" validate:"required"`
 }
 
 type SearchContactRequest struct {
-	UserId string `json:"-" validate:"required"`
-	Name   string `json:"name" validate:"max=100"`
-	Email  string `json:"email" validate:"max=200"`
-	Phone  string `json:"phone" validate:"max=20"`
-	Page   int    `json:"page" validate:"min=1"`
-	Size   int    `json:"size" validate:"min=1,max=100"`
+	UserId             string              `json:"-" validate:"required"`
+	Query              string              `json:"q" validate:"max=200"`
+	Highlight          bool                `json:"highlight"`
+	Name               string              `json:"name" validate:"max=100"`
+	Email              string              `json:"email" validate:"max=200"`
+	Phone              string              `json:"phone" validate:"max=20"`
+	Tags               []string            `json:"tag" validate:"max=20,dive,max=50"`
+	TagMode            string              `json:"tag_mode" validate:"omitempty,oneof=any all"`
+	PhonePrefix        string              `json:"-"`
+	CustomFields       map[string]string   `json:"custom_fields" validate:"max=20,dive,keys,max=50,endkeys,max=200"`
+	CustomFieldFilters []CustomFieldFilter `json:"-"`
+	Near               *GeoPoint           `json:"near"`
+	RadiusKm           float64             `json:"radius_km" validate:"min=0,max=1000"`
+	Expand             []string            `json:"expand" validate:"max=5,dive,oneof=addresses"`
+	Sort               string              `json:"sort" validate:"max=200"`
+	After              string              `json:"after" validate:"max=1024"`
+	Before             string              `json:"before" validate:"max=1024,excluded_with=After"`
+	IncludeTotal       bool                `json:"include_total"`
+	Page               int                 `json:"page" validate:"min=1"`
+	Size               int                 `json:"size" validate:"min=1,max=100"`
+}
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude" validate:"latitude"`
+	Longitude float64 `json:"longitude" validate:"longitude"`
 }
 
+type ExportContactRequest struct {
+	UserId       string            `json:"-" validate:"required"`
+	Format       string            `json:"format" validate:"required,oneof=vcf csv json"`
+	Query        string            `json:"q" validate:"max=200"`
+	Name         string            `json:"name" validate:"max=100"`
+	Email        string            `json:"email" validate:"max=200"`
+	Phone        string            `json:"phone" validate:"max=20"`
+	Tags         []string          `json:"tag" validate:"max=20,dive,max=50"`
+	TagMode      string            `json:"tag_mode" validate:"omitempty,oneof=any all"`
+	CustomFields map[string]string `json:"custom_fields" validate:"max=20,dive,keys,max=50,endkeys,max=200"`
+	Near         *GeoPoint         `json:"near"`
+	RadiusKm     float64           `json:"radius_km" validate:"min=0,max=1000"`
+}
+
+// Expand names the related records to embed in the response; only
+// "addresses" can be expanded.
 type GetContactRequest struct {
-	UserId string `json:"-" validate:"required"`
-	ID     string `json:"-" validate:"required,max=100,uuid"`
+	UserId string   `json:"-" validate:"required"`
+	ID     string   `json:"-" validate:"required,max=100,uuid"`
+	Expand []string `json:"expand" validate:"max=5,dive,oneof=addresses"`
 }
 
 type DeleteContactRequest struct {
+	UserId  string  `json:"-" validate:"required"`
+	ID      string  `json:"-" validate:"required,max=100,uuid"`
+	IfMatch []int64 `json:"-"`
+}
+
+type SearchTrashContactRequest struct {
+	UserId       string `json:"-" validate:"required"`
+	Sort         string `json:"sort" validate:"max=200"`
+	After        string `json:"after" validate:"max=1024"`
+	Before       string `json:"before" validate:"max=1024,excluded_with=After"`
+	IncludeTotal bool   `json:"include_total"`
+	Page         int    `json:"page" validate:"min=1"`
+	Size         int    `json:"size" validate:"min=1,max=100"`
+}
+
+type RestoreContactRequest struct {
 	UserId string `json:"-" validate:"required"`
 	ID     string `json:"-" validate:"required,max=100,uuid"`
 }
@@ -0,0 +1,36 @@
+package dto
+
+type ListContactHistoryRequest struct {
+	UserId       string `json:"-" validate:"required"`
+	ContactId    string `json:"-" validate:"required,max=100,uuid"`
+	Sort         string `json:"sort" validate:"max=200"`
+	After        string `json:"after" validate:"max=1024"`
+	Before       string `json:"before" validate:"max=1024,excluded_with=After"`
+	IncludeTotal bool   `json:"include_total"`
+	Page         int    `json:"page" validate:"min=1"`
+	Size         int    `json:"size" validate:"min=1,max=100"`
+}
+
+type RevertContactRequest struct {
+	UserId    string  `json:"-" validate:"required"`
+	ContactId string  `json:"-" validate:"required,max=100,uuid"`
+	Version   int64   `json:"-" validate:"min=1"`
+	IfMatch   []int64 `json:"-"`
+}
+
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type ContactHistoryResponse struct {
+	ID         string                 `json:"id"`
+	EntityType string                 `json:"entity_type"`
+	EntityId   string                 `json:"entity_id"`
+	Action     string                 `json:"action"`
+	Version    int64                  `json:"version"`
+	ActorId    string                 `json:"actor_id"`
+	RequestId  string                 `json:"request_id,omitempty"`
+	Changes    map[string]FieldChange `json:"changes"`
+	CreatedAt  int64                  `json:"created_at"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+type CustomFieldResponse struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options,omitempty"`
+	CreatedAt int64    `json:"created_at"`
+	UpdatedAt int64    `json:"updated_at"`
+}
+
+type ListCustomFieldRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+type CreateCustomFieldRequest struct {
+	UserId   string   `json:"-" validate:"required"`
+	Name     string   `json:"name" validate:"required,max=50,field_name"`
+	Type     string   `json:"type" validate:"required,oneof=text number date url enum"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options" validate:"required_if=Type enum,excluded_unless=Type enum,max=100,unique,dive,required,max=100"`
+}
+
+// UpdateCustomFieldRequest leaves out the name and type, which existing
+// contact values depend on.
+type UpdateCustomFieldRequest struct {
+	UserId   string   `json:"-" validate:"required"`
+	ID       string   `json:"-" validate:"required,max=100,uuid"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options" validate:"max=100,unique,dive,required,max=100"`
+}
+
+type GetCustomFieldRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type DeleteCustomFieldRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+// CustomFieldFilter is a search condition on one custom field, with Value
+// already in its stored form. Partial filters match substrings.
+type CustomFieldFilter struct {
+	Name    string
+	Value   any
+	Partial bool
+}
@@ -3,7 +3,6 @@ package dto
 type WebResponse[T any] struct {
 	Data   T             `json:"data"`
 	Paging *PageMetadata `json:"paging,omitempty"`
-	Errors string        `json:"errors,omitempty"`
 }
 
 type PageResponse[T any] struct {
@@ -11,9 +10,31 @@ type PageResponse[T any] struct {
 	PageMetadata PageMetadata `json:"paging,omitempty"`
 }
 
+// PageMetadata has TotalItem and TotalPage unless include_total=false skipped
+// counting them. v2 lists leave them out unless include_total asks for them.
 type PageMetadata struct {
-	Page      int   `json:"page"`
-	Size      int   `json:"size"`
-	TotalItem int64 `json:"total_item"`
-	TotalPage int64 `json:"total_page"`
-}
\ No newline at end of file
+	Page       int    `json:"page,omitempty"`
+	Size       int    `json:"size"`
+	TotalItem  *int64 `json:"total_item,omitempty"`
+	TotalPage  *int64 `json:"total_page,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// ProblemResponse is the RFC 9457 application/problem+json body of a failed
+// request. Code names the kind of failure for clients to switch on, and Errors
+// lists what was wrong with each offending field when validation failed.
+type ProblemResponse struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+type FindDuplicateContactRequest struct {
+	UserId   string  `json:"-" validate:"required"`
+	MinScore float64 `json:"min_score" validate:"min=0,max=1"`
+}
+
+type DuplicateGroupResponse struct {
+	Score    float64           `json:"score"`
+	Reasons  []string          `json:"reasons"`
+	Contacts []ContactResponse `json:"contacts"`
+}
+
+// MergeContactRequest merges SourceIds into TargetId. Fields picks, per field,
+// the contact whose value survives; unlisted fields keep the target's value,
+// or the first non-empty source value when the target's is empty.
+type MergeContactRequest struct {
+	UserId    string            `json:"-" validate:"required"`
+	TargetId  string            `json:"target_id" validate:"required,max=100,uuid"`
+	SourceIds []string          `json:"source_ids" validate:"required,min=1,max=50,dive,required,max=100,uuid"`
+	Fields    map[string]string `json:"fields" validate:"dive,keys,oneof=first_name last_name email phone,endkeys,required,max=100,uuid"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// GraphQLRequest is the body of a GraphQL request. Its members are named as
+// GraphQL over HTTP names them, in camelCase.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+type ImportContactRequest struct {
+	UserId  string            `json:"-" validate:"required"`
+	Format  string            `json:"format" validate:"required,oneof=csv vcf"`
+	Mapping map[string]string `json:"mapping" validate:"max=20,dive,keys,oneof=first_name last_name email phone street city province postal_code country,endkeys,max=100"`
+	DryRun  bool              `json:"dry_run"`
+	Content []byte            `json:"-" validate:"required"`
+}
+
+type GetImportJobRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportJobResponse struct {
+	ID           string           `json:"id,omitempty"`
+	Status       string           `json:"status"`
+	Format       string           `json:"format"`
+	DryRun       bool             `json:"dry_run"`
+	TotalRows    int              `json:"total_rows"`
+	ImportedRows int              `json:"imported_rows"`
+	FailedRows   int              `json:"failed_rows"`
+	Errors       []ImportRowError `json:"errors"`
+	CreatedAt    int64            `json:"created_at,omitempty"`
+	UpdatedAt    int64            `json:"updated_at,omitempty"`
+	FinishedAt   int64            `json:"finished_at,omitempty"`
+}
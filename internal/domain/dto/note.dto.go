@@ -0,0 +1,55 @@
+package dto
+
+type NoteResponse struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Body       string `json:"body"`
+	OccurredAt int64  `json:"occurred_at"`
+	CreatedBy  string `json:"created_by"`
+	UpdatedBy  string `json:"updated_by"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+type ListNoteRequest struct {
+	UserId       string `json:"-" validate:"required"`
+	ContactId    string `json:"-" validate:"required,max=100,uuid"`
+	Type         string `json:"type" validate:"omitempty,oneof=note call meeting email"`
+	Sort         string `json:"sort" validate:"max=200"`
+	After        string `json:"after" validate:"max=1024"`
+	Before       string `json:"before" validate:"max=1024,excluded_with=After"`
+	IncludeTotal bool   `json:"include_total"`
+	Page         int    `json:"page" validate:"min=1"`
+	Size         int    `json:"size" validate:"min=1,max=100"`
+}
+
+// CreateNoteRequest takes a markdown body. OccurredAt is when the call,
+// meeting or email took place and defaults to now.
+type CreateNoteRequest struct {
+	UserId     string `json:"-" validate:"required"`
+	ContactId  string `json:"-" validate:"required,max=100,uuid"`
+	Type       string `json:"type" validate:"required,oneof=note call meeting email"`
+	Body       string `json:"body" validate:"required,max=20000"`
+	OccurredAt int64  `json:"occurred_at" validate:"min=0"`
+}
+
+type UpdateNoteRequest struct {
+	UserId     string `json:"-" validate:"required"`
+	ContactId  string `json:"-" validate:"required,max=100,uuid"`
+	ID         string `json:"-" validate:"required,max=100,uuid"`
+	Type       string `json:"type" validate:"required,oneof=note call meeting email"`
+	Body       string `json:"body" validate:"required,max=20000"`
+	OccurredAt int64  `json:"occurred_at" validate:"min=0"`
+}
+
+type GetNoteRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	ContactId string `json:"-" validate:"required,max=100,uuid"`
+	ID        string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type DeleteNoteRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	ContactId string `json:"-" validate:"required,max=100,uuid"`
+	ID        string `json:"-" validate:"required,max=100,uuid"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+type OIDCLoginRequest struct {
+	// UserId, when set, links the identity that logs in to this user.
+	UserId    string `json:"-"`
+	LoginHint string `json:"login_hint" validate:"max=255"`
+}
+
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type OIDCCallbackRequest struct {
+	State            string `json:"state" validate:"required,max=255"`
+	Code             string `json:"code" validate:"required_without=Error,max=2048"`
+	Error            string `json:"error" validate:"max=255"`
+	ErrorDescription string `json:"error_description" validate:"max=1024"`
+}
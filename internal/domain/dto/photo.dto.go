@@ -0,0 +1,31 @@
+package dto
+
+import "io"
+
+// PhotoResponse streams a stored photo; the caller closes Body.
+type PhotoResponse struct {
+	ID          string
+	ContentType string
+	Body        io.ReadCloser
+}
+
+// PutPhotoRequest carries the uploaded file as is. Its type is sniffed from
+// the content, whatever the client declared.
+type PutPhotoRequest struct {
+	UserId    string  `json:"-" validate:"required"`
+	ContactId string  `json:"-" validate:"required,max=100,uuid"`
+	IfMatch   []int64 `json:"-"`
+	Data      []byte  `json:"-" validate:"required"`
+}
+
+type GetPhotoRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	ContactId string `json:"-" validate:"required,max=100,uuid"`
+	Size      string `json:"size" validate:"omitempty,oneof=original thumbnail"`
+}
+
+type DeletePhotoRequest struct {
+	UserId    string  `json:"-" validate:"required"`
+	ContactId string  `json:"-" validate:"required,max=100,uuid"`
+	IfMatch   []int64 `json:"-"`
+}
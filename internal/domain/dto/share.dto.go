@@ -0,0 +1,52 @@
+package dto
+
+type ShareResponse struct {
+	ID         string `json:"id"`
+	GranteeId  string `json:"grantee_id"`
+	ContactId  string `json:"contact_id,omitempty"`
+	TagId      string `json:"tag_id,omitempty"`
+	Permission string `json:"permission"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+type ListShareRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+// CreateShareRequest shares either one contact or every contact carrying a
+// tag, so exactly one of ContactId and TagId must be set.
+type CreateShareRequest struct {
+	UserId     string `json:"-" validate:"required"`
+	GranteeId  string `json:"grantee_id" validate:"required,max=100"`
+	ContactId  string `json:"contact_id" validate:"required_without=TagId,excluded_with=TagId,omitempty,max=100,uuid"`
+	TagId      string `json:"tag_id" validate:"required_without=ContactId,omitempty,max=100,uuid"`
+	Permission string `json:"permission" validate:"required,oneof=read write"`
+}
+
+type UpdateShareRequest struct {
+	UserId     string `json:"-" validate:"required"`
+	ID         string `json:"-" validate:"required,max=100,uuid"`
+	Permission string `json:"permission" validate:"required,oneof=read write"`
+}
+
+type DeleteShareRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type SharedContactResponse struct {
+	ContactResponse
+	OwnerId    string `json:"owner_id"`
+	Permission string `json:"permission"`
+}
+
+type SearchSharedContactRequest struct {
+	UserId       string `json:"-" validate:"required"`
+	Sort         string `json:"sort" validate:"max=200"`
+	After        string `json:"after" validate:"max=1024"`
+	Before       string `json:"before" validate:"max=1024,excluded_with=After"`
+	IncludeTotal bool   `json:"include_total"`
+	Page         int    `json:"page" validate:"min=1"`
+	Size         int    `json:"size" validate:"min=1,max=100"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+type TagResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type ListTagRequest struct {
+	UserId string `json:"-" validate:"required"`
+}
+
+type CreateTagRequest struct {
+	UserId string `json:"-" validate:"required"`
+	Name   string `json:"name" validate:"required,max=50"`
+}
+
+type UpdateTagRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+	Name   string `json:"name" validate:"required,max=50"`
+}
+
+type GetTagRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type DeleteTagRequest struct {
+	UserId string `json:"-" validate:"required"`
+	ID     string `json:"-" validate:"required,max=100,uuid"`
+}
+
+type ContactTagRequest struct {
+	UserId    string `json:"-" validate:"required"`
+	ContactId string `json:"-" validate:"required,max=100,uuid"`
+	TagId     string `json:"-" validate:"required,max=100,uuid"`
+}
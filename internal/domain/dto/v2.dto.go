@@ -0,0 +1,78 @@
+package dto
+
+import "time"
+
+// The V2 responses are what /api/v2 answers with. They carry the same data
+// as their v1 counterparts, with RFC 3339 timestamps in place of
+// milliseconds since the epoch.
+
+type WebResponseV2[T any] struct {
+	Data   T                   `json:"data"`
+	Paging *CursorPageMetadata `json:"paging,omitempty"`
+}
+
+// CursorPageMetadata pages v2 lists with cursors only. TotalItem is only
+// counted when the request asks for it with include_total.
+type CursorPageMetadata struct {
+	Size       int    `json:"size"`
+	TotalItem  *int64 `json:"total_item,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+type UserResponseV2 struct {
+	ID        string     `json:"id,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	Token     string     `json:"token,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type ContactResponseV2 struct {
+	ID                string                `json:"id"`
+	FirstName         string                `json:"first_name"`
+	LastName          string                `json:"last_name"`
+	Email             string                `json:"email"`
+	Phone             string                `json:"phone"`
+	PhoneE164         string                `json:"phone_e164"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+	DeletedAt         *time.Time            `json:"deleted_at,omitempty"`
+	Version           int64                 `json:"version"`
+	CustomFields      map[string]any        `json:"custom_fields"`
+	Dates             []ContactDateResponse `json:"dates"`
+	LastInteractionAt *time.Time            `json:"last_interaction_at,omitempty"`
+	PhotoURL          string                `json:"photo_url,omitempty"`
+	ThumbnailURL      string                `json:"thumbnail_url,omitempty"`
+	Rank              float64               `json:"rank,omitempty"`
+	Highlight         string                `json:"highlight,omitempty"`
+	DistanceKm        *float64              `json:"distance_km,omitempty"`
+	Tags              []TagResponseV2       `json:"tags"`
+	Addresses         []AddressResponseV2   `json:"addresses,omitempty"`
+	PrimaryAddress    *AddressResponseV2    `json:"primary_address,omitempty"`
+}
+
+type AddressResponseV2 struct {
+	ID          string    `json:"id"`
+	Street      string    `json:"street"`
+	City        string    `json:"city"`
+	Province    string    `json:"province"`
+	PostalCode  string    `json:"postal_code"`
+	Country     string    `json:"country"`
+	Formatted   string    `json:"formatted"`
+	Label       string    `json:"label,omitempty"`
+	CustomLabel string    `json:"custom_label,omitempty"`
+	IsPrimary   bool      `json:"is_primary"`
+	Latitude    *float64  `json:"latitude,omitempty"`
+	Longitude   *float64  `json:"longitude,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int64     `json:"version"`
+}
+
+type TagResponseV2 struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,16 +1,36 @@
 package entity
 
+import "gorm.io/plugin/soft_delete"
+
+const (
+	AddressLabelHome     = "home"
+	AddressLabelWork     = "work"
+	AddressLabelBilling  = "billing"
+	AddressLabelShipping = "shipping"
+	AddressLabelOther    = "other"
+	AddressLabelCustom   = "custom"
+)
+
 type Address struct {
-	ID         string  `gorm:"column:id;primaryKey"`
-	ContactId  string  `gorm:"column:contact_id"`
-	Street     string  `gorm:"column:street"`
-	City       string  `gorm:"column:city"`
-	Province   string  `gorm:"column:province"`
-	PostalCode string  `gorm:"column:postal_code"`
-	Country    string  `gorm:"column:country"`
-	CreatedAt  int64   `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt  int64   `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	Contact    Contact `gorm:"foreignKey:contact_id;references:id"`
+	ID         string `gorm:"column:id;primaryKey"`
+	ContactId  string `gorm:"column:contact_id"`
+	Street     string `gorm:"column:street"`
+	City       string `gorm:"column:city"`
+	Province   string `gorm:"column:province"`
+	PostalCode string `gorm:"column:postal_code"`
+	Country    string `gorm:"column:country"`
+	// Label is empty for an unlabeled address; CustomLabel names the label
+	// when it is AddressLabelCustom.
+	Label       string                `gorm:"column:label"`
+	CustomLabel string                `gorm:"column:custom_label"`
+	IsPrimary   bool                  `gorm:"column:is_primary"`
+	Latitude    *float64              `gorm:"column:latitude"`
+	Longitude   *float64              `gorm:"column:longitude"`
+	CreatedAt   int64                 `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt   int64                 `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	DeletedAt   soft_delete.DeletedAt `gorm:"column:deleted_at;softDelete:milli"`
+	Version     int64                 `gorm:"column:version;default:1"`
+	Contact     Contact               `gorm:"foreignKey:contact_id;references:id"`
 }
 
 func (a *Address) TableName() string {
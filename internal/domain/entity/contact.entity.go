@@ -1,18 +1,48 @@
 package entity
 
+import "gorm.io/plugin/soft_delete"
+
 type Contact struct {
-	ID        string    `gorm:"column:id;primaryKey"`
-	FirstName string    `gorm:"column:first_name"`
-	LastName  string    `gorm:"column:last_name"`
-	Email     string    `gorm:"column:email"`
-	Phone     string    `gorm:"column:phone"`
-	UserId    string    `gorm:"column:user_id"`
-	CreatedAt int64     `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	User      User      `gorm:"foreignKey:user_id;references:id"`
-	Addresses []Address `gorm:"foreignKey:contact_id;references:id"`
+	ID           string                `gorm:"column:id;primaryKey"`
+	FirstName    string                `gorm:"column:first_name"`
+	LastName     string                `gorm:"column:last_name"`
+	Email        string                `gorm:"column:email"`
+	Phone        string                `gorm:"column:phone"`
+	PhoneE164    string                `gorm:"column:phone_e164"`
+	UserId       string                `gorm:"column:user_id"`
+	CreatedAt    int64                 `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt    int64                 `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	DeletedAt    soft_delete.DeletedAt `gorm:"column:deleted_at;softDelete:milli"`
+	Version      int64                 `gorm:"column:version;default:1"`
+	CustomFields map[string]any        `gorm:"column:custom_fields;serializer:json"`
+	Dates        []ContactDate         `gorm:"column:dates;serializer:json"`
+	// PhotoId names the photo and thumbnail blobs, see usecase.photoKey.
+	PhotoId          string `gorm:"column:photo_id"`
+	PhotoContentType string `gorm:"column:photo_content_type"`
+	// LastInteractionAt is maintained from the contact's notes, never
+	// written through the contact itself.
+	LastInteractionAt int64 `gorm:"column:last_interaction_at;->"`
+	// SharePermission is only selected when listing contacts shared with
+	// the current user.
+	SharePermission string  `gorm:"column:share_permission;->"`
+	Rank            float64 `gorm:"column:search_rank;->"`
+	// Highlight is a plain text snippet of the contact with the matches of a
+	// search between HighlightStart and HighlightStop.
+	Highlight      string    `gorm:"column:search_highlight;->"`
+	Distance       *float64  `gorm:"column:search_distance;->"`
+	User           User      `gorm:"foreignKey:user_id;references:id"`
+	Addresses      []Address `gorm:"foreignKey:contact_id;references:id"`
+	PrimaryAddress *Address  `gorm:"foreignKey:contact_id;references:id"`
+	Tags           []Tag     `gorm:"many2many:contact_tags;foreignKey:id;joinForeignKey:contact_id;references:id;joinReferences:tag_id"`
 }
 
 func (c *Contact) TableName() string {
 	return "contacts"
 }
+
+// HighlightStart and HighlightStop surround the matches in a Highlight. They
+// are private use characters, taken out of the fields before highlighting.
+const (
+	HighlightStart = "\uE000"
+	HighlightStop  = "\uE001"
+)
@@ -0,0 +1,26 @@
+package entity
+
+const (
+	DateTypeBirthday    = "birthday"
+	DateTypeAnniversary = "anniversary"
+	DateTypeCustom      = "custom"
+)
+
+// ContactDate is a date that comes back every year, stored in Contact.Dates.
+// Year is 0 when it is not known.
+type ContactDate struct {
+	Type  string `json:"type"`
+	Label string `json:"label,omitempty"`
+	Year  int    `json:"year,omitempty"`
+	Month int    `json:"month"`
+	Day   int    `json:"day"`
+}
+
+// Key identifies the date within its contact: a contact has at most one
+// birthday and one anniversary, and custom dates are told apart by label.
+func (d ContactDate) Key() string {
+	if d.Type == DateTypeCustom {
+		return d.Type + ":" + d.Label
+	}
+	return d.Type
+}
@@ -0,0 +1,44 @@
+package entity
+
+const (
+	HistoryCreate  = "create"
+	HistoryUpdate  = "update"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+	HistoryRevert  = "revert"
+)
+
+const (
+	HistoryContact = "contact"
+	HistoryAddress = "address"
+)
+
+// HistorySystemActor is the actor of the changes the server makes on its
+// own, such as data migrations.
+const HistorySystemActor = "system"
+
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// ContactHistory is one change to a contact or to one of its addresses.
+// Version is the changed entity's version after the change and Snapshot its
+// fields at that point, which is what a revert goes back to.
+type ContactHistory struct {
+	ID         string                 `gorm:"column:id;primaryKey"`
+	ContactId  string                 `gorm:"column:contact_id"`
+	EntityType string                 `gorm:"column:entity_type"`
+	EntityId   string                 `gorm:"column:entity_id"`
+	Action     string                 `gorm:"column:action"`
+	Version    int64                  `gorm:"column:version"`
+	ActorId    string                 `gorm:"column:actor_id"`
+	RequestId  string                 `gorm:"column:request_id"`
+	Changes    map[string]FieldChange `gorm:"column:changes;serializer:json"`
+	Snapshot   map[string]any         `gorm:"column:snapshot;serializer:json"`
+	CreatedAt  int64                  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (h *ContactHistory) TableName() string {
+	return "contact_history"
+}
@@ -0,0 +1,28 @@
+package entity
+
+const (
+	CustomFieldText   = "text"
+	CustomFieldNumber = "number"
+	CustomFieldDate   = "date"
+	CustomFieldURL    = "url"
+	CustomFieldEnum   = "enum"
+)
+
+// CustomField defines a field a user can fill on their contacts. Values live
+// in Contact.CustomFields keyed by Name; Options lists the allowed values of
+// an enum field.
+type CustomField struct {
+	ID        string   `gorm:"column:id;primaryKey"`
+	UserId    string   `gorm:"column:user_id"`
+	Name      string   `gorm:"column:name"`
+	Type      string   `gorm:"column:type"`
+	Required  bool     `gorm:"column:required"`
+	Options   []string `gorm:"column:options;serializer:json"`
+	CreatedAt int64    `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt int64    `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User      User     `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (f *CustomField) TableName() string {
+	return "custom_fields"
+}
@@ -0,0 +1,33 @@
+package entity
+
+const (
+	ImportJobPending   = "pending"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportJob struct {
+	ID           string           `gorm:"column:id;primaryKey"`
+	UserId       string           `gorm:"column:user_id"`
+	Status       string           `gorm:"column:status"`
+	Format       string           `gorm:"column:format"`
+	TotalRows    int              `gorm:"column:total_rows"`
+	ImportedRows int              `gorm:"column:imported_rows"`
+	FailedRows   int              `gorm:"column:failed_rows"`
+	Errors       []ImportRowError `gorm:"column:errors;serializer:json"`
+	CreatedAt    int64            `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt    int64            `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	FinishedAt   int64            `gorm:"column:finished_at"`
+	User         User             `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (j *ImportJob) TableName() string {
+	return "import_jobs"
+}
@@ -0,0 +1,25 @@
+package entity
+
+const (
+	NoteTypeNote    = "note"
+	NoteTypeCall    = "call"
+	NoteTypeMeeting = "meeting"
+	NoteTypeEmail   = "email"
+)
+
+type Note struct {
+	ID         string  `gorm:"column:id;primaryKey"`
+	ContactId  string  `gorm:"column:contact_id"`
+	Type       string  `gorm:"column:type"`
+	Body       string  `gorm:"column:body"`
+	OccurredAt int64   `gorm:"column:occurred_at"`
+	CreatedBy  string  `gorm:"column:created_by"`
+	UpdatedBy  string  `gorm:"column:updated_by"`
+	CreatedAt  int64   `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt  int64   `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	Contact    Contact `gorm:"foreignKey:contact_id;references:id"`
+}
+
+func (n *Note) TableName() string {
+	return "notes"
+}
@@ -0,0 +1,16 @@
+package entity
+
+type OIDCState struct {
+	State        string `gorm:"column:state;primaryKey"`
+	Nonce        string `gorm:"column:nonce"`
+	CodeVerifier string `gorm:"column:code_verifier"`
+	ExpiresAt    int64  `gorm:"column:expires_at"`
+	// UserId is the user the identity is being linked to, or nil when the
+	// state belongs to a login.
+	UserId    *string `gorm:"column:user_id"`
+	CreatedAt int64   `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (s *OIDCState) TableName() string {
+	return "oidc_states"
+}
@@ -0,0 +1,16 @@
+package entity
+
+// Reminder records that a notification went out for one occurrence of a
+// contact date, so it is sent only once.
+type Reminder struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	ContactId string `gorm:"column:contact_id"`
+	DateKey   string `gorm:"column:date_key"`
+	OccursOn  string `gorm:"column:occurs_on"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (r *Reminder) TableName() string {
+	return "reminders"
+}
@@ -0,0 +1,26 @@
+package entity
+
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+)
+
+// Share grants Grantee access to one of Owner's contacts, or to every
+// contact carrying one of Owner's tags. Exactly one of ContactId and TagId is
+// set. Write permission includes read and lets the grantee edit the contact
+// and its addresses, notes and photo; trashing it and changing its tags stay
+// with the owner.
+type Share struct {
+	ID         string  `gorm:"column:id;primaryKey"`
+	OwnerId    string  `gorm:"column:owner_id"`
+	GranteeId  string  `gorm:"column:grantee_id"`
+	ContactId  *string `gorm:"column:contact_id"`
+	TagId      *string `gorm:"column:tag_id"`
+	Permission string  `gorm:"column:permission"`
+	CreatedAt  int64   `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt  int64   `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (s *Share) TableName() string {
+	return "shares"
+}
@@ -0,0 +1,24 @@
+package entity
+
+type Tag struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Name      string `gorm:"column:name"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User      User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (t *Tag) TableName() string {
+	return "tags"
+}
+
+type ContactTag struct {
+	ContactId string `gorm:"column:contact_id;primaryKey"`
+	TagId     string `gorm:"column:tag_id;primaryKey"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (ct *ContactTag) TableName() string {
+	return "contact_tags"
+}
@@ -1,13 +1,16 @@
 package entity
 
 type User struct {
-	ID        string    `gorm:"column:id;primaryKey"`
-	Password  string    `gorm:"column:password"`
-	Name      string    `gorm:"column:name"`
-	Token     string    `gorm:"column:token"`
-	CreatedAt int64     `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	Contacts  []Contact `gorm:"foreignKey:user_id;references:id"`
+	ID       string `gorm:"column:id;primaryKey"`
+	Password string `gorm:"column:password"`
+	Name     string `gorm:"column:name"`
+	Token    string `gorm:"column:token"`
+	// CalendarToken authenticates the user's calendar feed URL; it is empty
+	// while the feed is disabled.
+	CalendarToken string    `gorm:"column:calendar_token"`
+	CreatedAt     int64     `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt     int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	Contacts      []Contact `gorm:"foreignKey:user_id;references:id"`
 }
 
 func (u *User) TableName() string {
@@ -0,0 +1,19 @@
+package entity
+
+type UserIdentity struct {
+	ID      string `gorm:"column:id;primaryKey"`
+	UserId  string `gorm:"column:user_id"`
+	Issuer  string `gorm:"column:issuer"`
+	Subject string `gorm:"column:subject"`
+	Email   string `gorm:"column:email"`
+	// EmailVerified is whether the identity provider vouched for Email; only
+	// verified emails link a new identity to an existing user.
+	EmailVerified bool  `gorm:"column:email_verified"`
+	CreatedAt     int64 `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt     int64 `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User          User  `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (u *UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,54 @@
+// Package fakegeocoder is a stand-in for a Nominatim compatible search
+// service, answering structured searches from the offline dataset. It is only
+// meant for local development and integration testing of geocode.HTTPGeocoder.
+package fakegeocoder
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/geocode"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/postal"
+)
+
+type Server struct {
+	geocoder *geocode.OfflineGeocoder
+}
+
+func New() *Server {
+	return &Server{geocoder: geocode.NewOfflineGeocoder()}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search", s.search)
+	return mux
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	point, err := s.geocoder.Geocode(r.Context(), postal.Address{
+		Street:     query.Get("street"),
+		City:       query.Get("city"),
+		Province:   query.Get("state"),
+		PostalCode: query.Get("postalcode"),
+		Country:    query.Get("countrycodes"),
+	})
+
+	results := []geocode.SearchResult{}
+	switch {
+	case err == nil:
+		results = append(results, geocode.SearchResult{
+			Latitude:  strconv.FormatFloat(point.Latitude, 'f', 7, 64),
+			Longitude: strconv.FormatFloat(point.Longitude, 'f', 7, 64),
+		})
+	case !errors.Is(err, geocode.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
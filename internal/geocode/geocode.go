@@ -0,0 +1,24 @@
+// Package geocode finds where addresses are on the map. Geocoder is the
+// extension point: the server looks addresses up offline in an embedded
+// dataset of postal code and city centroids by default, and can ask a
+// Nominatim compatible HTTP service instead.
+package geocode
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/postal"
+)
+
+// ErrNotFound is returned for addresses the geocoder cannot place.
+var ErrNotFound = errors.New("geocode: address not found")
+
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+type Geocoder interface {
+	Geocode(ctx context.Context, address postal.Address) (Point, error)
+}
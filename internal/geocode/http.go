@@ -0,0 +1,98 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/postal"
+	"golang.org/x/time/rate"
+)
+
+// HTTPGeocoder looks addresses up with the structured search of a Nominatim
+// compatible service, such as OpenStreetMap's or the fake-geocoder command.
+// Searches wait their turn under Limiter, as OpenStreetMap's usage policy
+// allows one a second.
+type HTTPGeocoder struct {
+	URL       string
+	UserAgent string
+	Client    *http.Client
+	Limiter   *rate.Limiter
+}
+
+func NewHTTPGeocoder(url string, userAgent string, requestsPerSecond float64) *HTTPGeocoder {
+	return &HTTPGeocoder{
+		URL:       url,
+		UserAgent: userAgent,
+		Client:    &http.Client{Timeout: 5 * time.Second},
+		Limiter:   rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+	}
+}
+
+// SearchResult is the part of a Nominatim search result the geocoder reads.
+// Nominatim writes coordinates as strings.
+type SearchResult struct {
+	Latitude  string `json:"lat"`
+	Longitude string `json:"lon"`
+}
+
+func (g *HTTPGeocoder) Geocode(ctx context.Context, address postal.Address) (Point, error) {
+	query := url.Values{}
+	query.Set("format", "jsonv2")
+	query.Set("limit", "1")
+	for name, value := range map[string]string{
+		"street":       address.Street,
+		"city":         address.City,
+		"state":        address.Province,
+		"postalcode":   address.PostalCode,
+		"countrycodes": address.Country,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+
+	if err := g.Limiter.Wait(ctx); err != nil {
+		return Point{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.URL+"?"+query.Encode(), nil)
+	if err != nil {
+		return Point{}, err
+	}
+	if g.UserAgent != "" {
+		req.Header.Set("User-Agent", g.UserAgent)
+	}
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return Point{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Point{}, fmt.Errorf("geocode: search responded %s", resp.Status)
+	}
+
+	var results []SearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return Point{}, fmt.Errorf("geocode: read search result: %w", err)
+	}
+	if len(results) == 0 {
+		return Point{}, ErrNotFound
+	}
+
+	latitude, err := strconv.ParseFloat(results[0].Latitude, 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("geocode: read search result: %w", err)
+	}
+	longitude, err := strconv.ParseFloat(results[0].Longitude, 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("geocode: read search result: %w", err)
+	}
+	return Point{Latitude: latitude, Longitude: longitude}, nil
+}
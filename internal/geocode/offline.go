@@ -0,0 +1,86 @@
+package geocode
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/postal"
+)
+
+//go:embed centroids.csv
+var centroidsCSV []byte
+
+// OfflineGeocoder places an address at the centroid of the longest postal
+// code prefix it knows for the address's country, or else at the centroid of
+// its city. The embedded dataset covers large cities only, so its points are
+// good for "near" searches at city scale and no finer.
+type OfflineGeocoder struct {
+	postalCodes map[string]map[string]Point
+	cities      map[string]map[string]Point
+}
+
+func NewOfflineGeocoder() *OfflineGeocoder {
+	g := &OfflineGeocoder{
+		postalCodes: map[string]map[string]Point{},
+		cities:      map[string]map[string]Point{},
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(centroidsCSV)).ReadAll()
+	if err != nil {
+		panic(fmt.Errorf("geocode: parse centroids.csv: %w", err))
+	}
+	for _, record := range records[1:] {
+		country, postalCode, city := record[0], record[1], record[2]
+		latitude, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			panic(fmt.Errorf("geocode: parse centroids.csv: %w", err))
+		}
+		longitude, err := strconv.ParseFloat(record[4], 64)
+		if err != nil {
+			panic(fmt.Errorf("geocode: parse centroids.csv: %w", err))
+		}
+		point := Point{Latitude: latitude, Longitude: longitude}
+
+		if postalCode != "" {
+			add(g.postalCodes, country, compactPostalCode(postalCode), point)
+		}
+		add(g.cities, country, strings.ToLower(city), point)
+	}
+	return g
+}
+
+// add keeps the first point listed for a key.
+func add(index map[string]map[string]Point, country string, key string, point Point) {
+	if index[country] == nil {
+		index[country] = map[string]Point{}
+	}
+	if _, ok := index[country][key]; !ok {
+		index[country][key] = point
+	}
+}
+
+func (g *OfflineGeocoder) Geocode(ctx context.Context, address postal.Address) (Point, error) {
+	country := postal.Code(address.Country)
+
+	if code := compactPostalCode(address.PostalCode); code != "" {
+		for n := len(code); n > 0; n-- {
+			if point, ok := g.postalCodes[country][code[:n]]; ok {
+				return point, nil
+			}
+		}
+	}
+
+	if point, ok := g.cities[country][strings.ToLower(strings.TrimSpace(address.City))]; ok {
+		return point, nil
+	}
+	return Point{}, ErrNotFound
+}
+
+func compactPostalCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
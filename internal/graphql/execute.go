@@ -0,0 +1,921 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Error is an error as it appears in a response.
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type Request struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any
+}
+
+// Response carries no data when the request could not be executed at all,
+// and null data when a non-null field at the root failed.
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Executor runs requests against a schema. Zero limits are not enforced.
+type Executor struct {
+	Schema        *Schema
+	MaxDepth      int
+	MaxComplexity int
+	// Present turns an error returned by a resolver into what the client
+	// sees. It defaults to the error's message.
+	Present func(err error) *Error
+}
+
+// Execute parses, validates and runs a request. The fields of a query run
+// level by level: every field at one depth is resolved, then the thunks they
+// returned are called, before any field below them. The top-level fields of a
+// mutation run one after the other.
+func (e *Executor) Execute(ctx context.Context, request Request) *Response {
+	document, err := Parse(request.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	operation, err := selectOperation(document, request.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	root := e.Schema.Query
+	switch operation.Operation {
+	case "mutation":
+		if e.Schema.Mutation == nil {
+			return &Response{Errors: []*Error{{Message: "the schema has no mutations", Locations: []Location{operation.Location}}}}
+		}
+		root = e.Schema.Mutation
+	case "subscription":
+		return &Response{Errors: []*Error{{Message: "subscriptions are not supported", Locations: []Location{operation.Location}}}}
+	}
+
+	p := &planner{
+		schema:        e.Schema,
+		document:      document,
+		variables:     map[string]any{},
+		defined:       map[string]Type{},
+		maxDepth:      e.MaxDepth,
+		maxComplexity: e.MaxComplexity,
+	}
+	if err := p.coerceVariables(operation, request.Variables); err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	fields, _, err := p.plan(root, operation.SelectionSet, 1)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	present := e.Present
+	if present == nil {
+		present = func(err error) *Error { return &Error{Message: err.Error()} }
+	}
+	ex := &execution{ctx: ctx, present: present}
+
+	result := ex.object(root, fields, nil, nil)
+	if operation.Operation == "mutation" {
+		fields := ex.queue
+		for _, field := range fields {
+			ex.queue = []*job{field}
+			ex.drain()
+		}
+	} else {
+		ex.drain()
+	}
+
+	response := &Response{Errors: ex.errors}
+	if data, _ := finalize(result, root); data != nil {
+		response.Data = data
+	} else {
+		response.Data = json.RawMessage("null")
+	}
+	return response
+}
+
+func asError(err error) *Error {
+	var graphqlErr *Error
+	if errors.As(err, &graphqlErr) {
+		return graphqlErr
+	}
+	return &Error{Message: err.Error()}
+}
+
+func selectOperation(document *Document, name string) (*OperationDefinition, error) {
+	if name == "" {
+		if len(document.Operations) > 1 {
+			return nil, &Error{Message: "the document has several operations, so operationName is required"}
+		}
+		return document.Operations[0], nil
+	}
+	for _, operation := range document.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("the document has no operation named %q", name)}
+}
+
+// plannedField is a field after fragments are flattened, directives applied
+// and arguments coerced. Definition is nil for __typename.
+type plannedField struct {
+	Key        string
+	Definition *FieldDefinition
+	Type       Type
+	Args       map[string]any
+	Fields     []*plannedField
+	Location   Location
+}
+
+// maxSelections bounds how many selections planning a request may visit, so
+// that fragments spread into each other cannot make it take exponential time.
+const maxSelections = 10000
+
+type planner struct {
+	schema        *Schema
+	document      *Document
+	variables     map[string]any
+	defined       map[string]Type
+	maxDepth      int
+	maxComplexity int
+	selections    int
+}
+
+func (p *planner) coerceVariables(operation *OperationDefinition, provided map[string]any) error {
+	for _, definition := range operation.Variables {
+		if _, ok := p.defined[definition.Name]; ok {
+			return &Error{Message: fmt.Sprintf("variable $%s is defined more than once", definition.Name), Locations: []Location{definition.Location}}
+		}
+		t, err := p.typeOf(definition.Type)
+		if err != nil {
+			return &Error{Message: fmt.Sprintf("variable $%s: %s", definition.Name, err), Locations: []Location{definition.Location}}
+		}
+		p.defined[definition.Name] = t
+
+		value, ok := provided[definition.Name]
+		if !ok {
+			if definition.Default.Kind != NoValue {
+				value, err := p.literal(definition.Default, t)
+				if err != nil {
+					return &Error{Message: fmt.Sprintf("variable $%s: %s", definition.Name, err), Locations: []Location{definition.Location}}
+				}
+				p.variables[definition.Name] = value
+			} else if _, ok := t.(*NonNull); ok {
+				return &Error{Message: fmt.Sprintf("variable $%s of type %s is required", definition.Name, t), Locations: []Location{definition.Location}}
+			}
+			continue
+		}
+
+		value, err = coerceInput(value, t)
+		if err != nil {
+			return &Error{Message: fmt.Sprintf("variable $%s: %s", definition.Name, err), Locations: []Location{definition.Location}}
+		}
+		p.variables[definition.Name] = value
+	}
+	return nil
+}
+
+func (p *planner) typeOf(ref TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := p.typeOf(*ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = &List{Of: elem}
+	} else {
+		t = p.schema.Type(ref.Name)
+		if t == nil {
+			return nil, fmt.Errorf("unknown type %s", ref.Name)
+		}
+		if !isInput(t) {
+			return nil, fmt.Errorf("%s is not an input type", ref.Name)
+		}
+	}
+	if ref.NonNull {
+		t = &NonNull{Of: t}
+	}
+	return t, nil
+}
+
+// plan turns selections on object into planned fields, along with their
+// complexity. It fails as soon as the complexity of the fields planned so far
+// goes over the limit, rather than once the whole query is planned.
+func (p *planner) plan(object *Object, selections []Selection, depth int) ([]*plannedField, int, error) {
+	if p.maxDepth > 0 && depth > p.maxDepth {
+		return nil, 0, &Error{Message: fmt.Sprintf("the query is nested more than %d levels deep", p.maxDepth)}
+	}
+
+	var keys []string
+	groups := map[string][]*Field{}
+	if err := p.collect(object, selections, map[string]bool{}, map[string]bool{}, &keys, groups); err != nil {
+		return nil, 0, err
+	}
+
+	fields := make([]*plannedField, 0, len(keys))
+	complexity := 0
+	for _, key := range keys {
+		group := groups[key]
+		first := group[0]
+		for _, other := range group[1:] {
+			if other.Name != first.Name {
+				return nil, 0, &Error{
+					Message:   fmt.Sprintf("%q selects both %s and %s", key, first.Name, other.Name),
+					Locations: []Location{first.Location, other.Location},
+				}
+			}
+		}
+
+		planned := &plannedField{Key: key, Location: first.Location}
+		if first.Name == "__typename" {
+			if len(first.SelectionSet) > 0 {
+				return nil, 0, &Error{Message: "__typename cannot have subfields", Locations: []Location{first.Location}}
+			}
+			planned.Type = &NonNull{Of: String}
+			fields = append(fields, planned)
+			continue
+		}
+
+		definition := object.Field(first.Name)
+		if definition == nil {
+			return nil, 0, &Error{Message: fmt.Sprintf("%s has no field %q", object.Name, first.Name), Locations: []Location{first.Location}}
+		}
+		args, err := p.arguments(definition, first)
+		if err != nil {
+			return nil, 0, err
+		}
+		planned.Definition = definition
+		planned.Type = definition.Type
+		planned.Args = args
+
+		var children []Selection
+		for _, field := range group {
+			children = append(children, field.SelectionSet...)
+		}
+		childComplexity := 0
+		if named, ok := namedType(definition.Type).(*Object); ok {
+			if len(children) == 0 {
+				return nil, 0, &Error{Message: fmt.Sprintf("field %q of type %s must select subfields", first.Name, definition.Type), Locations: []Location{first.Location}}
+			}
+			planned.Fields, childComplexity, err = p.plan(named, children, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+		} else if len(children) > 0 {
+			return nil, 0, &Error{Message: fmt.Sprintf("field %q of type %s cannot have subfields", first.Name, definition.Type), Locations: []Location{first.Location}}
+		}
+		fields = append(fields, planned)
+
+		if definition.Complexity != nil {
+			complexity += definition.Complexity(args, childComplexity)
+		} else {
+			complexity += 1 + childComplexity
+		}
+		// A negative complexity can only come from an overflow.
+		if p.maxComplexity > 0 && (complexity > p.maxComplexity || complexity < 0) {
+			return nil, 0, &Error{Message: fmt.Sprintf("the query is more complex than the %d allowed", p.maxComplexity)}
+		}
+	}
+	return fields, complexity, nil
+}
+
+// collect groups the fields selected on object by response key, in the order
+// they first appear, following fragments. A fragment spread again on the same
+// object adds nothing new, so it is only collected the first time.
+func (p *planner) collect(object *Object, selections []Selection, spread, spreading map[string]bool, keys *[]string, groups map[string][]*Field) error {
+	for _, selection := range selections {
+		p.selections++
+		if p.selections > maxSelections {
+			return &Error{Message: fmt.Sprintf("the query makes more than %d selections", maxSelections)}
+		}
+
+		switch selection := selection.(type) {
+		case *Field:
+			if include, err := p.include(selection.Directives); err != nil || !include {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			key := selection.Alias
+			if key == "" {
+				key = selection.Name
+			}
+			if _, ok := groups[key]; !ok {
+				*keys = append(*keys, key)
+			}
+			groups[key] = append(groups[key], selection)
+
+		case *InlineFragment:
+			if include, err := p.include(selection.Directives); err != nil || !include {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if selection.TypeCondition != "" && selection.TypeCondition != object.Name {
+				return &Error{Message: fmt.Sprintf("a fragment on %s cannot apply to %s", selection.TypeCondition, object.Name), Locations: []Location{selection.Location}}
+			}
+			if err := p.collect(object, selection.SelectionSet, spread, spreading, keys, groups); err != nil {
+				return err
+			}
+
+		case *FragmentSpread:
+			if include, err := p.include(selection.Directives); err != nil || !include {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			fragment := p.document.Fragments[selection.Name]
+			if fragment == nil {
+				return &Error{Message: fmt.Sprintf("unknown fragment %q", selection.Name), Locations: []Location{selection.Location}}
+			}
+			if spreading[selection.Name] {
+				return &Error{Message: fmt.Sprintf("fragment %q spreads itself", selection.Name), Locations: []Location{selection.Location}}
+			}
+			if fragment.TypeCondition != object.Name {
+				return &Error{Message: fmt.Sprintf("a fragment on %s cannot apply to %s", fragment.TypeCondition, object.Name), Locations: []Location{selection.Location}}
+			}
+			if spread[selection.Name] {
+				continue
+			}
+			spread[selection.Name] = true
+			spreading[selection.Name] = true
+			err := p.collect(object, fragment.SelectionSet, spread, spreading, keys, groups)
+			delete(spreading, selection.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// include applies @skip and @include.
+func (p *planner) include(directives []*Directive) (bool, error) {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			return false, &Error{Message: fmt.Sprintf("unknown directive @%s", directive.Name), Locations: []Location{directive.Location}}
+		}
+		if len(directive.Arguments) != 1 || directive.Arguments[0].Name != "if" {
+			return false, &Error{Message: fmt.Sprintf("@%s takes a single if argument", directive.Name), Locations: []Location{directive.Location}}
+		}
+		value, _, err := p.value(directive.Arguments[0].Value, &NonNull{Of: Boolean})
+		if err != nil {
+			return false, &Error{Message: fmt.Sprintf("@%s: %s", directive.Name, err), Locations: []Location{directive.Location}}
+		}
+		if value.(bool) == (directive.Name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (p *planner) arguments(definition *FieldDefinition, field *Field) (map[string]any, error) {
+	provided := map[string]*Argument{}
+	for _, argument := range field.Arguments {
+		if !slices.ContainsFunc(definition.Args, func(arg *ArgumentDefinition) bool { return arg.Name == argument.Name }) {
+			return nil, &Error{Message: fmt.Sprintf("field %q has no argument %q", field.Name, argument.Name), Locations: []Location{argument.Location}}
+		}
+		if _, ok := provided[argument.Name]; ok {
+			return nil, &Error{Message: fmt.Sprintf("argument %q is given more than once", argument.Name), Locations: []Location{argument.Location}}
+		}
+		provided[argument.Name] = argument
+	}
+
+	args := map[string]any{}
+	for _, arg := range definition.Args {
+		if argument, ok := provided[arg.Name]; ok {
+			value, present, err := p.value(argument.Value, arg.Type)
+			if err != nil {
+				return nil, &Error{Message: fmt.Sprintf("argument %q of field %q: %s", arg.Name, field.Name, err), Locations: []Location{argument.Location}}
+			}
+			if present {
+				args[arg.Name] = value
+				continue
+			}
+		}
+		if arg.Default != nil {
+			args[arg.Name] = arg.Default
+		} else if _, ok := arg.Type.(*NonNull); ok {
+			return nil, &Error{Message: fmt.Sprintf("argument %q of field %q is required", arg.Name, field.Name), Locations: []Location{field.Location}}
+		}
+	}
+	return args, nil
+}
+
+// value coerces a value written in the request to t. It reports a variable
+// that was not provided and has no default as absent rather than null.
+func (p *planner) value(value Value, t Type) (any, bool, error) {
+	if value.Kind != VariableValue {
+		coerced, err := p.literal(value, t)
+		return coerced, true, err
+	}
+	if _, ok := p.defined[value.Raw]; !ok {
+		return nil, false, fmt.Errorf("variable $%s is not defined", value.Raw)
+	}
+	variable, ok := p.variables[value.Raw]
+	if !ok {
+		if _, ok := t.(*NonNull); ok {
+			return nil, false, fmt.Errorf("variable $%s must not be null", value.Raw)
+		}
+		return nil, false, nil
+	}
+	coerced, err := coerceInput(variable, t)
+	return coerced, true, err
+}
+
+func (p *planner) literal(value Value, t Type) (any, error) {
+	if value.Kind == VariableValue {
+		coerced, _, err := p.value(value, t)
+		return coerced, err
+	}
+	if nonNull, ok := t.(*NonNull); ok {
+		if value.Kind == NullValue {
+			return nil, fmt.Errorf("must not be null")
+		}
+		return p.literal(value, nonNull.Of)
+	}
+	if value.Kind == NullValue {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		if value.Kind != ListValue {
+			item, err := p.literal(value, t.Of)
+			return []any{item}, err
+		}
+		items := make([]any, len(value.List))
+		for i, item := range value.List {
+			coerced, err := p.literal(item, t.Of)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			items[i] = coerced
+		}
+		return items, nil
+
+	case *InputObject:
+		if value.Kind != ObjectValue {
+			return nil, fmt.Errorf("must be a %s object", t.Name)
+		}
+		provided := map[string]Value{}
+		for _, field := range value.Fields {
+			if !slices.ContainsFunc(t.Fields, func(arg *ArgumentDefinition) bool { return arg.Name == field.Name }) {
+				return nil, fmt.Errorf("%s has no field %q", t.Name, field.Name)
+			}
+			provided[field.Name] = field.Value
+		}
+		object := map[string]any{}
+		for _, field := range t.Fields {
+			if value, ok := provided[field.Name]; ok {
+				coerced, present, err := p.value(value, field.Type)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", field.Name, err)
+				}
+				if present {
+					object[field.Name] = coerced
+					continue
+				}
+			}
+			if field.Default != nil {
+				object[field.Name] = field.Default
+			} else if _, ok := field.Type.(*NonNull); ok {
+				return nil, fmt.Errorf("%s is required", field.Name)
+			}
+		}
+		return object, nil
+
+	case *Enum:
+		if value.Kind != EnumValue || !slices.Contains(t.Values, value.Raw) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(t.Values, ", "))
+		}
+		return value.Raw, nil
+
+	case *Scalar:
+		if value.Kind == EnumValue && t != JSON {
+			return nil, fmt.Errorf("must be a %s", t.Name)
+		}
+		literal, err := p.goValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return t.ParseValue(literal)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// goValue turns a literal into the value JSON decoding would have produced,
+// with numbers kept as json.Number.
+func (p *planner) goValue(value Value) (any, error) {
+	switch value.Kind {
+	case IntValue, FloatValue:
+		return json.Number(value.Raw), nil
+	case StringValue, EnumValue:
+		return value.Raw, nil
+	case BooleanValue:
+		return value.Raw == "true", nil
+	case VariableValue:
+		if _, ok := p.defined[value.Raw]; !ok {
+			return nil, fmt.Errorf("variable $%s is not defined", value.Raw)
+		}
+		return p.variables[value.Raw], nil
+	case ListValue:
+		items := make([]any, len(value.List))
+		for i, item := range value.List {
+			converted, err := p.goValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = converted
+		}
+		return items, nil
+	case ObjectValue:
+		object := make(map[string]any, len(value.Fields))
+		for _, field := range value.Fields {
+			converted, err := p.goValue(field.Value)
+			if err != nil {
+				return nil, err
+			}
+			object[field.Name] = converted
+		}
+		return object, nil
+	}
+	return nil, nil
+}
+
+// coerceInput coerces a decoded JSON value, or one already coerced to
+// another type, to t.
+func coerceInput(value any, t Type) (any, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("must not be null")
+		}
+		return coerceInput(value, nonNull.Of)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]any)
+		if !ok {
+			item, err := coerceInput(value, t.Of)
+			return []any{item}, err
+		}
+		coerced := make([]any, len(items))
+		for i, item := range items {
+			value, err := coerceInput(item, t.Of)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			coerced[i] = value
+		}
+		return coerced, nil
+
+	case *InputObject:
+		provided, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("must be a %s object", t.Name)
+		}
+		for name := range provided {
+			if !slices.ContainsFunc(t.Fields, func(arg *ArgumentDefinition) bool { return arg.Name == name }) {
+				return nil, fmt.Errorf("%s has no field %q", t.Name, name)
+			}
+		}
+		object := map[string]any{}
+		for _, field := range t.Fields {
+			if value, ok := provided[field.Name]; ok {
+				coerced, err := coerceInput(value, field.Type)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", field.Name, err)
+				}
+				object[field.Name] = coerced
+			} else if field.Default != nil {
+				object[field.Name] = field.Default
+			} else if _, ok := field.Type.(*NonNull); ok {
+				return nil, fmt.Errorf("%s is required", field.Name)
+			}
+		}
+		return object, nil
+
+	case *Enum:
+		if s, ok := value.(string); ok && slices.Contains(t.Values, s) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(t.Values, ", "))
+
+	case *Scalar:
+		return t.ParseValue(value)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+type execution struct {
+	ctx     context.Context
+	present func(err error) *Error
+	errors  []*Error
+	queue   []*job
+}
+
+// job resolves one field of one object, storing the completed value in the
+// object's slot for it.
+type job struct {
+	object *resultObject
+	index  int
+	field  *plannedField
+	source any
+	path   []any
+	value  any
+	err    error
+}
+
+// resultObject is an object in the response whose fields may still be
+// resolving. A field that fails is left null; finalize then propagates the
+// null up to the nearest nullable field.
+type resultObject struct {
+	keys   []string
+	types  []Type
+	values []any
+}
+
+func (ex *execution) object(t *Object, fields []*plannedField, source any, path []any) *resultObject {
+	result := &resultObject{
+		keys:   make([]string, len(fields)),
+		types:  make([]Type, len(fields)),
+		values: make([]any, len(fields)),
+	}
+	for i, field := range fields {
+		result.keys[i] = field.Key
+		result.types[i] = field.Type
+		if field.Definition == nil {
+			result.values[i] = t.Name
+			continue
+		}
+		ex.queue = append(ex.queue, &job{object: result, index: i, field: field, source: source, path: appendPath(path, field.Key)})
+	}
+	return result
+}
+
+func (ex *execution) drain() {
+	for len(ex.queue) > 0 {
+		level := ex.queue
+		ex.queue = nil
+
+		if err := ex.ctx.Err(); err != nil {
+			for _, job := range level {
+				ex.fail(err, job.field, job.path)
+			}
+			return
+		}
+
+		for _, job := range level {
+			job.value, job.err = ex.resolve(job)
+		}
+		for _, job := range level {
+			if thunk, ok := job.value.(Thunk); ok && job.err == nil {
+				job.value, job.err = ex.call(job, thunk)
+			}
+		}
+		for _, job := range level {
+			if job.err != nil {
+				ex.fail(job.err, job.field, job.path)
+				continue
+			}
+			job.object.values[job.index] = ex.complete(job.value, job.field.Type, job.field, job.path)
+		}
+	}
+}
+
+func (ex *execution) resolve(job *job) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("graphql: resolving %s panicked: %v", job.field.Definition.Name, r)
+		}
+	}()
+	if job.field.Definition.Resolve == nil {
+		return defaultResolve(job.field.Definition.Name, job.source), nil
+	}
+	return job.field.Definition.Resolve(ex.ctx, job.source, job.field.Args)
+}
+
+func (ex *execution) call(job *job, thunk Thunk) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("graphql: resolving %s panicked: %v", job.field.Definition.Name, r)
+		}
+	}()
+	return thunk()
+}
+
+func (ex *execution) fail(err error, field *plannedField, path []any) {
+	presented := *ex.present(err)
+	presented.Locations = []Location{field.Location}
+	presented.Path = path
+	ex.errors = append(ex.errors, &presented)
+}
+
+func (ex *execution) complete(value any, t Type, field *plannedField, path []any) any {
+	if nonNull, ok := t.(*NonNull); ok {
+		failed := len(ex.errors)
+		completed := ex.complete(value, nonNull.Of, field, path)
+		if completed == nil && len(ex.errors) == failed {
+			ex.fail(&Error{Message: fmt.Sprintf("%s returned null for a non-null %s", field.Definition.Name, t)}, field, path)
+		}
+		return completed
+	}
+	if isNil(value) {
+		return nil
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		serialized, err := t.Serialize(value)
+		if err != nil {
+			ex.fail(&Error{Message: fmt.Sprintf("%s cannot be serialized as %s: %s", field.Definition.Name, t.Name, err)}, field, path)
+			return nil
+		}
+		return serialized
+	case *Enum:
+		s := fmt.Sprint(value)
+		if !slices.Contains(t.Values, s) {
+			ex.fail(&Error{Message: fmt.Sprintf("%s returned %q, which is not a %s", field.Definition.Name, s, t.Name)}, field, path)
+			return nil
+		}
+		return s
+	case *List:
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			ex.fail(&Error{Message: fmt.Sprintf("%s returned a %T rather than a list", field.Definition.Name, value)}, field, path)
+			return nil
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = ex.complete(v.Index(i).Interface(), t.Of, field, appendPath(path, i))
+		}
+		return items
+	case *Object:
+		return ex.object(t, field.Fields, value, path)
+	}
+	return nil
+}
+
+// finalize builds the JSON value of a completed result. It reports false
+// when the value is null but t does not allow it, so the caller has to null
+// itself in turn.
+func finalize(value any, t Type) (any, bool) {
+	if nonNull, ok := t.(*NonNull); ok {
+		finalized, ok := finalize(value, nonNull.Of)
+		return finalized, ok && finalized != nil
+	}
+	switch value := value.(type) {
+	case nil:
+		return nil, true
+	case []any:
+		of := t.(*List).Of
+		items := make([]any, len(value))
+		for i, item := range value {
+			finalized, ok := finalize(item, of)
+			if !ok {
+				return nil, true
+			}
+			items[i] = finalized
+		}
+		return items, true
+	case *resultObject:
+		object := &orderedObject{keys: value.keys, values: make([]any, len(value.values))}
+		for i, field := range value.values {
+			finalized, ok := finalize(field, value.types[i])
+			if !ok {
+				return nil, true
+			}
+			object.values[i] = finalized
+		}
+		return object, true
+	}
+	return value, true
+}
+
+// orderedObject keeps the fields of a response object in the order they were
+// selected.
+type orderedObject struct {
+	keys   []string
+	values []any
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		value, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+func appendPath(path []any, element any) []any {
+	return append(path[:len(path):len(path)], element)
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// defaultResolve reads the member of a struct or map named after the field,
+// either as is or in snake case.
+func defaultResolve(name string, source any) any {
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	key := SnakeCase(name)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		for _, k := range []string{name, key} {
+			if item := v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())); item.IsValid() {
+				return item.Interface()
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if tag == key || tag == name || (tag == "" && strings.EqualFold(field.Name, name)) {
+				return v.Field(i).Interface()
+			}
+		}
+	}
+	return nil
+}
+
+// SnakeCase turns a camelCase name into snake_case, such as lastInteractionAt
+// into last_interaction_at.
+func SnakeCase(name string) string {
+	var snake strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+				snake.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		snake.WriteRune(r)
+	}
+	return snake.String()
+}
@@ -0,0 +1,391 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentId string `json:"parent_id"`
+}
+
+// newTestSchema serves items whose ids are their names, each with a parent
+// loaded through a Loader that counts its fetches.
+func newTestSchema(fetches *int) *Schema {
+	item := &Object{Name: "Item"}
+	color := &Enum{Name: "Color", Values: []string{"RED", "GREEN"}}
+	filter := &InputObject{Name: "Filter", Fields: []*ArgumentDefinition{
+		{Name: "prefix", Type: &NonNull{Of: String}},
+		{Name: "limit", Type: Int, Default: 2},
+	}}
+
+	parents := NewLoader(func(ctx context.Context, ids []string) (map[string]*testItem, error) {
+		*fetches++
+		found := map[string]*testItem{}
+		for _, id := range ids {
+			if id != "" {
+				found[id] = &testItem{ID: id, Name: id}
+			}
+		}
+		return found, nil
+	})
+
+	item.Fields = []*FieldDefinition{
+		{Name: "id", Type: &NonNull{Of: ID}},
+		{Name: "name", Type: String},
+		{Name: "parent", Type: item, Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+			return parents.Load(ctx, source.(*testItem).ParentId), nil
+		}},
+		{Name: "broken", Type: &NonNull{Of: String}, Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+			return nil, errors.New("broken")
+		}},
+		{Name: "children", Type: &NonNull{Of: &List{Of: &NonNull{Of: item}}},
+			Args: []*ArgumentDefinition{{Name: "size", Type: Int, Default: 2}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				parent := source.(*testItem)
+				children := make([]*testItem, args["size"].(int))
+				for i := range children {
+					id := fmt.Sprintf("%s.%d", parent.ID, i)
+					children[i] = &testItem{ID: id, Name: id, ParentId: parent.ID}
+				}
+				return children, nil
+			},
+			Complexity: func(args map[string]any, child int) int { return 1 + args["size"].(int)*child },
+		},
+	}
+
+	query := &Object{Name: "Query", Fields: []*FieldDefinition{
+		{Name: "item", Type: item, Args: []*ArgumentDefinition{{Name: "id", Type: &NonNull{Of: ID}}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				id := args["id"].(string)
+				if id == "missing" {
+					return nil, nil
+				}
+				return &testItem{ID: id, Name: id, ParentId: "root"}, nil
+			}},
+		{Name: "echo", Type: JSON, Args: []*ArgumentDefinition{
+			{Name: "color", Type: color},
+			{Name: "colors", Type: &List{Of: &NonNull{Of: color}}},
+			{Name: "filter", Type: filter},
+			{Name: "at", Type: DateTime},
+			{Name: "count", Type: Int},
+			{Name: "ratio", Type: Float},
+		}, Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+			if at, ok := args["at"].(time.Time); ok {
+				args["at"] = at.UTC().Format(time.RFC3339)
+			}
+			return args, nil
+		}},
+		{Name: "panic", Type: String, Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+			panic("boom")
+		}},
+	}}
+
+	schema, err := NewSchema(query, nil)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     string
+		maxDepth      int
+		maxComplexity int
+		want          string
+		wantErr       string
+	}{
+		{
+			name:  "fields and aliases",
+			query: `{ item(id: "a") { id other: name __typename } }`,
+			want:  `{"item":{"id":"a","other":"a","__typename":"Item"}}`,
+		},
+		{
+			name:  "fields selected twice are merged",
+			query: `{ item(id: "a") { parent { id } parent { name } } }`,
+			want:  `{"item":{"parent":{"id":"root","name":"root"}}}`,
+		},
+		{
+			name:    "one key for two fields",
+			query:   `{ item(id: "a") { x: id x: name } }`,
+			wantErr: `"x" selects both id and name`,
+		},
+		{
+			name:  "fragment spreads and inline fragments",
+			query: `{ item(id: "a") { ...Names ... on Item { id } } } fragment Names on Item { name }`,
+			want:  `{"item":{"name":"a","id":"a"}}`,
+		},
+		{
+			name:    "fragment on the wrong type",
+			query:   `{ item(id: "a") { ...Q } } fragment Q on Query { echo }`,
+			wantErr: "a fragment on Query cannot apply to Item",
+		},
+		{
+			name:    "fragment that spreads itself",
+			query:   `{ item(id: "a") { ...A } } fragment A on Item { ...B } fragment B on Item { ...A }`,
+			wantErr: `fragment "A" spreads itself`,
+		},
+		{
+			name:    "unknown fragment",
+			query:   `{ item(id: "a") { ...Nope } }`,
+			wantErr: `unknown fragment "Nope"`,
+		},
+		{
+			name: "fragments spread many times are collected once",
+			query: `{ item(id: "a") { ...F0 } }
+				fragment F0 on Item { ...F1 ...F1 }
+				fragment F1 on Item { ...F2 ...F2 }
+				fragment F2 on Item { ...F3 ...F3 }
+				fragment F3 on Item { ...F4 ...F4 }
+				fragment F4 on Item { ...F5 ...F5 }
+				fragment F5 on Item { ...F6 ...F6 }
+				fragment F6 on Item { ...F7 ...F7 }
+				fragment F7 on Item { ...F8 ...F8 }
+				fragment F8 on Item { ...F9 ...F9 }
+				fragment F9 on Item { ...F10 ...F10 }
+				fragment F10 on Item { ...F11 ...F11 }
+				fragment F11 on Item { ...F12 ...F12 }
+				fragment F12 on Item { ...F13 ...F13 }
+				fragment F13 on Item { ...F14 ...F14 }
+				fragment F14 on Item { ...F15 ...F15 }
+				fragment F15 on Item { ...F16 ...F16 }
+				fragment F16 on Item { ...F17 ...F17 }
+				fragment F17 on Item { ...F18 ...F18 }
+				fragment F18 on Item { ...F19 ...F19 }
+				fragment F19 on Item { ...F20 ...F20 }
+				fragment F20 on Item { ...F21 ...F21 }
+				fragment F21 on Item { ...F22 ...F22 }
+				fragment F22 on Item { ...F23 ...F23 }
+				fragment F23 on Item { ...F24 ...F24 }
+				fragment F24 on Item { ...F25 ...F25 }
+				fragment F25 on Item { ...F26 ...F26 }
+				fragment F26 on Item { ...F27 ...F27 }
+				fragment F27 on Item { ...F28 ...F28 }
+				fragment F28 on Item { ...F29 ...F29 }
+				fragment F29 on Item { ...F30 ...F30 }
+				fragment F30 on Item { id }`,
+			want: `{"item":{"id":"a"}}`,
+		},
+		{
+			name: "too many selections",
+			query: `{ item(id: "a") { ...F0 } }
+				fragment F0 on Item { a: parent { ...F1 } b: parent { ...F1 } }
+				fragment F1 on Item { a: parent { ...F2 } b: parent { ...F2 } }
+				fragment F2 on Item { a: parent { ...F3 } b: parent { ...F3 } }
+				fragment F3 on Item { a: parent { ...F4 } b: parent { ...F4 } }
+				fragment F4 on Item { a: parent { ...F5 } b: parent { ...F5 } }
+				fragment F5 on Item { a: parent { ...F6 } b: parent { ...F6 } }
+				fragment F6 on Item { a: parent { ...F7 } b: parent { ...F7 } }
+				fragment F7 on Item { a: parent { ...F8 } b: parent { ...F8 } }
+				fragment F8 on Item { a: parent { ...F9 } b: parent { ...F9 } }
+				fragment F9 on Item { a: parent { ...F10 } b: parent { ...F10 } }
+				fragment F10 on Item { a: parent { ...F11 } b: parent { ...F11 } }
+				fragment F11 on Item { a: parent { ...F12 } b: parent { ...F12 } }
+				fragment F12 on Item { a: parent { ...F13 } b: parent { ...F13 } }
+				fragment F13 on Item { id }`,
+			wantErr: "more than 10000 selections",
+		},
+		{
+			name:      "skip and include",
+			query:     `query ($hide: Boolean!) { item(id: "a") { id @skip(if: $hide) name @include(if: false) ... @include(if: true) { parent { id } } } }`,
+			variables: `{"hide": true}`,
+			want:      `{"item":{"parent":{"id":"root"}}}`,
+		},
+		{
+			name:    "unknown directive",
+			query:   `{ item(id: "a") { id @deprecated } }`,
+			wantErr: "unknown directive @deprecated",
+		},
+		{
+			name:      "variables are coerced",
+			query:     `query ($id: ID!, $count: Int, $colors: [Color!], $filter: Filter, $at: DateTime) { item(id: $id) { id } echo(count: $count, colors: $colors, filter: $filter, at: $at) }`,
+			variables: `{"id": 7, "count": 3, "colors": "RED", "filter": {"prefix": "p"}, "at": "2024-01-02T03:04:05+01:00"}`,
+			want:      `{"item":{"id":"7"},"echo":{"at":"2024-01-02T02:04:05Z","colors":["RED"],"count":3,"filter":{"limit":2,"prefix":"p"}}}`,
+		},
+		{
+			name:  "literals are coerced",
+			query: `{ echo(color: GREEN, colors: [RED, GREEN], filter: {prefix: "p", limit: 5}, count: 2, ratio: 1) }`,
+			want:  `{"echo":{"color":"GREEN","colors":["RED","GREEN"],"count":2,"filter":{"limit":5,"prefix":"p"},"ratio":1}}`,
+		},
+		{
+			name:  "variable defaults",
+			query: `query ($count: Int = 4, $missing: Int) { echo(count: $count, ratio: $missing) }`,
+			want:  `{"echo":{"count":4}}`,
+		},
+		{
+			name:    "required variable missing",
+			query:   `query ($id: ID!) { item(id: $id) { id } }`,
+			wantErr: "variable $id of type ID! is required",
+		},
+		{
+			name:      "variable of the wrong type",
+			query:     `query ($count: Int) { echo(count: $count) }`,
+			variables: `{"count": 1.5}`,
+			wantErr:   "variable $count: must be an integer",
+		},
+		{
+			name:      "enum variable out of range",
+			query:     `query ($color: Color) { echo(color: $color) }`,
+			variables: `{"color": "BLUE"}`,
+			wantErr:   "must be one of RED, GREEN",
+		},
+		{
+			name:      "input object missing a required field",
+			query:     `query ($filter: Filter) { echo(filter: $filter) }`,
+			variables: `{"filter": {"limit": 1}}`,
+			wantErr:   "prefix is required",
+		},
+		{
+			name:      "input object with an unknown field",
+			query:     `query ($filter: Filter) { echo(filter: $filter) }`,
+			variables: `{"filter": {"prefix": "p", "other": 1}}`,
+			wantErr:   `Filter has no field "other"`,
+		},
+		{
+			name:    "undefined variable",
+			query:   `{ echo(count: $count) }`,
+			wantErr: "variable $count is not defined",
+		},
+		{
+			name:    "variable of an output type",
+			query:   `query ($item: Item) { echo }`,
+			wantErr: "Item is not an input type",
+		},
+		{
+			name:    "enum literal for a string",
+			query:   `{ item(id: RED) { id } }`,
+			wantErr: "must be a ID",
+		},
+		{
+			name:    "unknown field",
+			query:   `{ item(id: "a") { nope } }`,
+			wantErr: `Item has no field "nope"`,
+		},
+		{
+			name:    "object without subfields",
+			query:   `{ item(id: "a") }`,
+			wantErr: "must select subfields",
+		},
+		{
+			name:          "operation by name",
+			query:         `query A { item(id: "a") { id } } query B { item(id: "b") { id } }`,
+			operationName: "B",
+			want:          `{"item":{"id":"b"}}`,
+		},
+		{
+			name:    "several operations without a name",
+			query:   `query A { item(id: "a") { id } } query B { item(id: "b") { id } }`,
+			wantErr: "operationName is required",
+		},
+		{
+			name:    "mutation without mutations",
+			query:   `mutation { item(id: "a") { id } }`,
+			wantErr: "the schema has no mutations",
+		},
+		{
+			name:  "nullable field that is null",
+			query: `{ item(id: "missing") { id } }`,
+			want:  `{"item":null}`,
+		},
+		{
+			name:    "non-null error nulls the parent",
+			query:   `{ item(id: "a") { id broken } }`,
+			want:    `{"item":null}`,
+			wantErr: "broken",
+		},
+		{
+			name:    "panics become errors",
+			query:   `{ panic }`,
+			want:    `{"panic":null}`,
+			wantErr: "resolving panic panicked: boom",
+		},
+		{
+			name:     "within the depth limit",
+			query:    `{ item(id: "a") { parent { id } } }`,
+			maxDepth: 3,
+			want:     `{"item":{"parent":{"id":"root"}}}`,
+		},
+		{
+			name:     "over the depth limit",
+			query:    `{ item(id: "a") { parent { parent { id } } } }`,
+			maxDepth: 3,
+			wantErr:  "nested more than 3 levels deep",
+		},
+		{
+			name:          "within the complexity limit",
+			query:         `{ item(id: "a") { children(size: 3) { id } } }`,
+			maxComplexity: 5,
+			want:          `{"item":{"children":[{"id":"a.0"},{"id":"a.1"},{"id":"a.2"}]}}`,
+		},
+		{
+			name:          "over the complexity limit",
+			query:         `{ item(id: "a") { children(size: 3) { id name } } }`,
+			maxComplexity: 5,
+			wantErr:       "more complex than the 5 allowed",
+		},
+		{
+			name:          "complexity that overflows",
+			query:         `{ item(id: "a") { children(size: 9223372036854775807) { children(size: 9223372036854775807) { id } } } }`,
+			maxComplexity: 1000,
+			wantErr:       "more complex than the 1000 allowed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetches := 0
+			executor := &Executor{Schema: newTestSchema(&fetches), MaxDepth: test.maxDepth, MaxComplexity: test.maxComplexity}
+
+			var variables map[string]any
+			if test.variables != "" {
+				decoder := json.NewDecoder(strings.NewReader(test.variables))
+				decoder.UseNumber()
+				if err := decoder.Decode(&variables); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			done := make(chan *Response, 1)
+			go func() {
+				done <- executor.Execute(context.Background(), Request{Query: test.query, OperationName: test.operationName, Variables: variables})
+			}()
+			var response *Response
+			select {
+			case response = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Execute() did not finish")
+			}
+
+			if test.wantErr == "" && len(response.Errors) > 0 {
+				t.Fatalf("errors = %v", response.Errors[0].Message)
+			}
+			if test.wantErr != "" && (len(response.Errors) == 0 || !strings.Contains(response.Errors[0].Message, test.wantErr)) {
+				t.Fatalf("errors = %+v, want one containing %q", response.Errors, test.wantErr)
+			}
+			if test.want == "" {
+				if response.Data != nil {
+					t.Errorf("data = %v, want none", response.Data)
+				}
+				return
+			}
+			data, err := json.Marshal(response.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.want {
+				t.Errorf("data = %s, want %s", data, test.want)
+			}
+		})
+	}
+}
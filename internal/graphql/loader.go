@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader batches the keys loaded while one level of a query resolves into a
+// single fetch. Load queues a key and returns a Thunk; the first of those
+// thunks to be called fetches every key queued so far.
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+	mu    sync.Mutex
+	batch *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	seen   map[K]bool
+	once   sync.Once
+	values map[K]V
+	err    error
+}
+
+// NewLoader makes a loader over fetch, which returns the values it found by
+// key. A key it did not return loads as V's zero value.
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk {
+	l.mu.Lock()
+	if l.batch == nil {
+		l.batch = &batch[K, V]{seen: map[K]bool{}}
+	}
+	b := l.batch
+	if !b.seen[key] {
+		b.seen[key] = true
+		b.keys = append(b.keys, key)
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		b.once.Do(func() {
+			l.mu.Lock()
+			if l.batch == b {
+				l.batch = nil
+			}
+			l.mu.Unlock()
+			b.values, b.err = l.fetch(ctx, b.keys)
+		})
+		if b.err != nil {
+			return nil, b.err
+		}
+		return b.values[key], nil
+	}
+}
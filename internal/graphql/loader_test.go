@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoader(t *testing.T) {
+	var batches [][]int
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		if slices.Contains(keys, -1) {
+			return nil, errors.New("negative key")
+		}
+		values := map[int]string{}
+		for _, key := range keys {
+			if key != 0 {
+				values[key] = string(rune('a' + key - 1))
+			}
+		}
+		return values, nil
+	})
+	ctx := context.Background()
+
+	first := []Thunk{loader.Load(ctx, 1), loader.Load(ctx, 2), loader.Load(ctx, 1), loader.Load(ctx, 0)}
+	for i, want := range []string{"a", "b", "a", ""} {
+		value, err := first[i]()
+		if err != nil || value != want {
+			t.Errorf("thunk %d = %v, %v, want %q", i, value, err, want)
+		}
+	}
+	if len(batches) != 1 || !slices.Equal(batches[0], []int{1, 2, 0}) {
+		t.Fatalf("batches = %v, want one of [1 2 0]", batches)
+	}
+
+	if value, err := first[0](); err != nil || value != "a" {
+		t.Errorf("calling a thunk again = %v, %v", value, err)
+	}
+	if len(batches) != 1 {
+		t.Errorf("calling a thunk again fetched again: %v", batches)
+	}
+
+	second := loader.Load(ctx, 3)
+	if value, err := second(); err != nil || value != "c" {
+		t.Errorf("thunk after the first batch = %v, %v", value, err)
+	}
+	if len(batches) != 2 || !slices.Equal(batches[1], []int{3}) {
+		t.Errorf("batches = %v, want a second of [3]", batches)
+	}
+
+	failing := []Thunk{loader.Load(ctx, 4), loader.Load(ctx, -1)}
+	for i, thunk := range failing {
+		if _, err := thunk(); err == nil {
+			t.Errorf("thunk %d of a failed batch succeeded", i)
+		}
+	}
+}
+
+func TestLoaderBatchesALevel(t *testing.T) {
+	fetches := 0
+	executor := &Executor{Schema: newTestSchema(&fetches)}
+	response := executor.Execute(context.Background(), Request{
+		Query: `{ item(id: "a") { children(size: 5) { parent { id } children(size: 2) { parent { id } } } } }`,
+	})
+	if len(response.Errors) > 0 {
+		t.Fatalf("errors = %v", response.Errors[0].Message)
+	}
+	// One fetch for the parents of the children, one for theirs.
+	if fetches != 2 {
+		t.Errorf("fetches = %d, want 2", fetches)
+	}
+}
@@ -0,0 +1,817 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document is a parsed request: its operations and the fragments they
+// spread.
+type Document struct {
+	Operations []*OperationDefinition
+	Fragments  map[string]*FragmentDefinition
+}
+
+type OperationDefinition struct {
+	Operation    string
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+	Location     Location
+}
+
+type VariableDefinition struct {
+	Name     string
+	Type     TypeRef
+	Default  Value
+	Location Location
+}
+
+// TypeRef is a type as written in a variable definition, such as [ID!]!.
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+type FragmentDefinition struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Location      Location
+}
+
+// Selection is a *Field, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	selection()
+}
+
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Location     Location
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Location   Location
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Location      Location
+}
+
+func (*Field) selection()          {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+type Argument struct {
+	Name     string
+	Value    Value
+	Location Location
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Location  Location
+}
+
+// Value is a literal or variable in a request. Kind is one of the Kind
+// constants; Raw holds scalar literals as written, List the items of a list
+// and Fields the members of an object.
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []Value
+	Fields []*Argument
+}
+
+type ValueKind int
+
+const (
+	NoValue ValueKind = iota
+	VariableValue
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Parse reads a request document.
+func Parse(source string) (*Document, error) {
+	p := &parser{lexer: lexer{source: source, line: 1, lineStart: 0}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p.parseDocument()
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind     tokenKind
+	value    string
+	location Location
+}
+
+type lexer struct {
+	source    string
+	pos       int
+	line      int
+	lineStart int
+}
+
+func (l *lexer) location() Location {
+	return Location{Line: l.line, Column: l.pos - l.lineStart + 1}
+}
+
+func (l *lexer) errorf(location Location, format string, args ...any) error {
+	return &Error{Message: "syntax error: " + fmt.Sprintf(format, args...), Locations: []Location{location}}
+}
+
+// next reads the next token, skipping whitespace, commas and comments.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		switch {
+		case c == '\n':
+			l.pos++
+			l.line++
+			l.lineStart = l.pos
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.source[l.pos:], "\uFEFF"):
+			l.pos += len("\uFEFF")
+		default:
+			return l.read()
+		}
+	}
+	return token{kind: tokenEOF, location: l.location()}, nil
+}
+
+func (l *lexer) read() (token, error) {
+	start := l.location()
+	c := l.source[l.pos]
+	switch {
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunctuator, value: string(c), location: start}, nil
+	case strings.HasPrefix(l.source[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokenPunctuator, value: "...", location: start}, nil
+	case c == '_' || isLetter(c):
+		begin := l.pos
+		for l.pos < len(l.source) && (l.source[l.pos] == '_' || isLetter(l.source[l.pos]) || isDigit(l.source[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.source[begin:l.pos], location: start}, nil
+	case c == '-' || isDigit(c):
+		return l.readNumber(start)
+	case strings.HasPrefix(l.source[l.pos:], `"""`):
+		return l.readBlockString(start)
+	case c == '"':
+		return l.readString(start)
+	default:
+		r, _ := utf8.DecodeRuneInString(l.source[l.pos:])
+		return token{}, l.errorf(start, "unexpected character %q", r)
+	}
+}
+
+func (l *lexer) readNumber(start Location) (token, error) {
+	begin := l.pos
+	if l.source[l.pos] == '-' {
+		l.pos++
+	}
+	digits := l.pos
+	for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+		l.pos++
+	}
+	if l.pos == digits || (l.pos-digits > 1 && l.source[digits] == '0') {
+		return token{}, l.errorf(start, "invalid number")
+	}
+
+	kind := tokenInt
+	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		fraction := l.pos
+		for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+			l.pos++
+		}
+		if l.pos == fraction {
+			return token{}, l.errorf(start, "invalid number")
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.pos++
+		}
+		exponent := l.pos
+		for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+			l.pos++
+		}
+		if l.pos == exponent {
+			return token{}, l.errorf(start, "invalid number")
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == '_' || l.source[l.pos] == '.' || isLetter(l.source[l.pos])) {
+		return token{}, l.errorf(start, "invalid number")
+	}
+	return token{kind: kind, value: l.source[begin:l.pos], location: start}, nil
+}
+
+func (l *lexer) readString(start Location) (token, error) {
+	l.pos++
+	var value strings.Builder
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokenString, value: value.String(), location: start}, nil
+		case c == '\n' || c == '\r':
+			return token{}, l.errorf(start, "unterminated string")
+		case c == '\\':
+			if l.pos+1 >= len(l.source) {
+				return token{}, l.errorf(start, "unterminated string")
+			}
+			escape := l.source[l.pos+1]
+			l.pos += 2
+			switch escape {
+			case '"', '\\', '/':
+				value.WriteByte(escape)
+			case 'b':
+				value.WriteByte('\b')
+			case 'f':
+				value.WriteByte('\f')
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.source) {
+					return token{}, l.errorf(start, "invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.source[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, l.errorf(start, "invalid unicode escape")
+				}
+				value.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				return token{}, l.errorf(start, "invalid escape \\%c", escape)
+			}
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, l.errorf(start, "unterminated string")
+}
+
+// readBlockString reads a """block string""", with the common indentation
+// of its lines and its leading and trailing blank lines removed.
+func (l *lexer) readBlockString(start Location) (token, error) {
+	l.pos += 3
+	var raw strings.Builder
+	for l.pos < len(l.source) {
+		rest := l.source[l.pos:]
+		switch {
+		case strings.HasPrefix(rest, `"""`):
+			l.pos += 3
+			return token{kind: tokenString, value: blockStringValue(raw.String()), location: start}, nil
+		case strings.HasPrefix(rest, `\"""`):
+			raw.WriteString(`"""`)
+			l.pos += 4
+		default:
+			if rest[0] == '\n' {
+				l.line++
+				l.lineStart = l.pos + 1
+			}
+			raw.WriteByte(rest[0])
+			l.pos++
+		}
+	}
+	return token{}, l.errorf(start, "unterminated string")
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// maxNesting bounds how deeply selection sets, list types and values may be
+// nested, so that a document cannot exhaust the stack.
+const maxNesting = 64
+
+type parser struct {
+	lexer lexer
+	token token
+	depth int
+}
+
+// enter notes that the parser is one level deeper; leave undoes it.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxNesting {
+		return p.errorf("the document is nested more than %d levels deep", maxNesting)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) advance() error {
+	token, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = token
+	return nil
+}
+
+func (p *parser) peek(value string) bool {
+	return p.token.kind == tokenPunctuator && p.token.value == value
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return p.lexer.errorf(p.token.location, format, args...)
+}
+
+func (p *parser) expect(value string) error {
+	if !p.peek(value) {
+		return p.errorf("expected %q, found %s", value, p.describe())
+	}
+	return p.advance()
+}
+
+// skip consumes the punctuator value if it is next.
+func (p *parser) skip(value string) (bool, error) {
+	if !p.peek(value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) describe() string {
+	switch p.token.kind {
+	case tokenEOF:
+		return "end of document"
+	case tokenString:
+		return "string"
+	default:
+		return strconv.Quote(p.token.value)
+	}
+}
+
+func (p *parser) name() (string, error) {
+	if p.token.kind != tokenName {
+		return "", p.errorf("expected a name, found %s", p.describe())
+	}
+	name := p.token.value
+	return name, p.advance()
+}
+
+func (p *parser) keyword(name string) (bool, error) {
+	if p.token.kind != tokenName || p.token.value != name {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) parseDocument() (*Document, error) {
+	document := &Document{Fragments: map[string]*FragmentDefinition{}}
+	for p.token.kind != tokenEOF {
+		if p.token.kind == tokenName && p.token.value == "fragment" {
+			fragment, err := p.parseFragmentDefinition()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := document.Fragments[fragment.Name]; ok {
+				return nil, &Error{Message: fmt.Sprintf("fragment %q is defined more than once", fragment.Name),
+					Locations: []Location{fragment.Location}}
+			}
+			document.Fragments[fragment.Name] = fragment
+			continue
+		}
+		operation, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		document.Operations = append(document.Operations, operation)
+	}
+	if len(document.Operations) == 0 {
+		return nil, &Error{Message: "the document has no operation"}
+	}
+	return document, nil
+}
+
+func (p *parser) parseOperation() (*OperationDefinition, error) {
+	operation := &OperationDefinition{Operation: "query", Location: p.token.location}
+	if p.peek("{") {
+		selections, err := p.parseSelectionSet()
+		operation.SelectionSet = selections
+		return operation, err
+	}
+
+	if p.token.kind != tokenName || (p.token.value != "query" && p.token.value != "mutation" && p.token.value != "subscription") {
+		return nil, p.errorf("expected an operation, found %s", p.describe())
+	}
+	operation.Operation = p.token.value
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.token.kind == tokenName {
+		operation.Name = p.token.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			variable, err := p.parseVariableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			operation.Variables = append(operation.Variables, variable)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	operation.SelectionSet = selections
+	return operation, err
+}
+
+func (p *parser) parseVariableDefinition() (*VariableDefinition, error) {
+	variable := &VariableDefinition{Location: p.token.location}
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	variable.Name = name
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if variable.Type, err = p.parseTypeRef(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if variable.Default, err = p.parseValue(true); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	return variable, nil
+}
+
+func (p *parser) parseTypeRef() (TypeRef, error) {
+	var ref TypeRef
+	if err := p.enter(); err != nil {
+		return ref, err
+	}
+	defer p.leave()
+	if ok, err := p.skip("["); err != nil {
+		return ref, err
+	} else if ok {
+		elem, err := p.parseTypeRef()
+		if err != nil {
+			return ref, err
+		}
+		ref.Elem = &elem
+		if err := p.expect("]"); err != nil {
+			return ref, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return ref, err
+		}
+		ref.Name = name
+	}
+	nonNull, err := p.skip("!")
+	ref.NonNull = nonNull
+	return ref, err
+}
+
+func (p *parser) parseFragmentDefinition() (*FragmentDefinition, error) {
+	fragment := &FragmentDefinition{Location: p.token.location}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, p.errorf("a fragment cannot be named \"on\"")
+	}
+	fragment.Name = name
+	if ok, err := p.keyword("on"); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, p.errorf("expected \"on\", found %s", p.describe())
+	}
+	if fragment.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if fragment.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	fragment.SelectionSet, err = p.parseSelectionSet()
+	return fragment, err
+}
+
+func (p *parser) parseSelectionSet() ([]Selection, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []Selection
+	for !p.peek("}") {
+		if p.token.kind == tokenEOF {
+			return nil, p.errorf("expected \"}\", found %s", p.describe())
+		}
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, p.errorf("a selection set cannot be empty")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) parseSelection() (Selection, error) {
+	location := p.token.location
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		if p.token.kind == tokenName && p.token.value != "on" {
+			spread := &FragmentSpread{Name: p.token.value, Location: location}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			var err error
+			spread.Directives, err = p.parseDirectives()
+			return spread, err
+		}
+
+		fragment := &InlineFragment{Location: location}
+		if ok, err := p.keyword("on"); err != nil {
+			return nil, err
+		} else if ok {
+			if fragment.TypeCondition, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if fragment.Directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		fragment.SelectionSet, err = p.parseSelectionSet()
+		return fragment, err
+	}
+
+	field := &Field{Location: location}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		field.Alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	field.Name = name
+	if field.Arguments, err = p.parseArguments(false); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		field.SelectionSet, err = p.parseSelectionSet()
+	}
+	return field, err
+}
+
+func (p *parser) parseArguments(constant bool) ([]*Argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	var arguments []*Argument
+	for !p.peek(")") {
+		argument, err := p.parseArgument(constant)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+	}
+	if len(arguments) == 0 {
+		return nil, p.errorf("an argument list cannot be empty")
+	}
+	return arguments, p.advance()
+}
+
+func (p *parser) parseArgument(constant bool) (*Argument, error) {
+	argument := &Argument{Location: p.token.location}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	argument.Name = name
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	argument.Value, err = p.parseValue(constant)
+	return argument, err
+}
+
+func (p *parser) parseDirectives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		directive := &Directive{Location: p.token.location}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		directive.Name = name
+		if directive.Arguments, err = p.parseArguments(false); err != nil {
+			return nil, err
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// parseValue reads a value; constant ones, such as variable defaults, cannot
+// refer to variables.
+func (p *parser) parseValue(constant bool) (Value, error) {
+	if err := p.enter(); err != nil {
+		return Value{}, err
+	}
+	defer p.leave()
+
+	token := p.token
+	switch token.kind {
+	case tokenInt:
+		return Value{Kind: IntValue, Raw: token.value}, p.advance()
+	case tokenFloat:
+		return Value{Kind: FloatValue, Raw: token.value}, p.advance()
+	case tokenString:
+		return Value{Kind: StringValue, Raw: token.value}, p.advance()
+	case tokenName:
+		value := Value{Kind: EnumValue, Raw: token.value}
+		switch token.value {
+		case "true", "false":
+			value.Kind = BooleanValue
+		case "null":
+			value.Kind = NullValue
+		}
+		return value, p.advance()
+	}
+
+	switch {
+	case p.peek("$") && !constant:
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		name, err := p.name()
+		return Value{Kind: VariableValue, Raw: name}, err
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		value := Value{Kind: ListValue, List: []Value{}}
+		for !p.peek("]") {
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return Value{}, err
+			}
+			value.List = append(value.List, item)
+		}
+		return value, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		value := Value{Kind: ObjectValue}
+		for !p.peek("}") {
+			field, err := p.parseArgument(constant)
+			if err != nil {
+				return Value{}, err
+			}
+			value.Fields = append(value.Fields, field)
+		}
+		return value, p.advance()
+	default:
+		return Value{}, p.errorf("expected a value, found %s", p.describe())
+	}
+}
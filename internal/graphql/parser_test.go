@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+		check   func(t *testing.T, document *Document)
+	}{
+		{
+			name:   "shorthand query",
+			source: `{ me { id } }`,
+			check: func(t *testing.T, document *Document) {
+				if len(document.Operations) != 1 || document.Operations[0].Operation != "query" {
+					t.Fatalf("operations = %+v", document.Operations)
+				}
+			},
+		},
+		{
+			name:   "named operation with variables and defaults",
+			source: `query Find($id: ID!, $tags: [String!] = ["a", "b"], $size: Int = 10) { contact(id: $id) { id } }`,
+			check: func(t *testing.T, document *Document) {
+				operation := document.Operations[0]
+				if operation.Name != "Find" || len(operation.Variables) != 3 {
+					t.Fatalf("operation = %+v", operation)
+				}
+				if got := operation.Variables[1].Type.String(); got != "[String!]" {
+					t.Errorf("type of $tags = %s", got)
+				}
+				if got := operation.Variables[1].Default; got.Kind != ListValue || len(got.List) != 2 {
+					t.Errorf("default of $tags = %+v", got)
+				}
+			},
+		},
+		{
+			name:   "aliases, arguments and directives",
+			source: `{ first: contact(id: "1") @include(if: true) { id name @skip(if: $hide) } }`,
+			check: func(t *testing.T, document *Document) {
+				field := document.Operations[0].SelectionSet[0].(*Field)
+				if field.Alias != "first" || field.Name != "contact" || len(field.Arguments) != 1 || len(field.Directives) != 1 {
+					t.Fatalf("field = %+v", field)
+				}
+				name := field.SelectionSet[1].(*Field)
+				if name.Directives[0].Arguments[0].Value.Kind != VariableValue {
+					t.Errorf("directive argument = %+v", name.Directives[0].Arguments[0].Value)
+				}
+			},
+		},
+		{
+			name:   "fragments and inline fragments",
+			source: `query { me { ...UserFields ... on User { email } ... @include(if: true) { id } } } fragment UserFields on User { id }`,
+			check: func(t *testing.T, document *Document) {
+				selections := document.Operations[0].SelectionSet[0].(*Field).SelectionSet
+				if _, ok := selections[0].(*FragmentSpread); !ok {
+					t.Errorf("selection 0 = %T", selections[0])
+				}
+				if fragment, ok := selections[1].(*InlineFragment); !ok || fragment.TypeCondition != "User" {
+					t.Errorf("selection 1 = %+v", selections[1])
+				}
+				if fragment, ok := selections[2].(*InlineFragment); !ok || fragment.TypeCondition != "" {
+					t.Errorf("selection 2 = %+v", selections[2])
+				}
+				if document.Fragments["UserFields"] == nil {
+					t.Errorf("fragments = %+v", document.Fragments)
+				}
+			},
+		},
+		{
+			name:   "strings, numbers and objects",
+			source: "{ f(a: \"tab\\tand \\u00e9\", b: \"\"\"\n    block\n      indented\n  \"\"\", c: -1.5e3, d: {x: 1, y: [null, ENUM]}) }",
+			check: func(t *testing.T, document *Document) {
+				args := document.Operations[0].SelectionSet[0].(*Field).Arguments
+				if args[0].Value.Raw != "tab\tand é" {
+					t.Errorf("a = %q", args[0].Value.Raw)
+				}
+				if args[1].Value.Raw != "block\n  indented" {
+					t.Errorf("b = %q", args[1].Value.Raw)
+				}
+				if args[2].Value.Kind != FloatValue || args[2].Value.Raw != "-1.5e3" {
+					t.Errorf("c = %+v", args[2].Value)
+				}
+				if d := args[3].Value; d.Kind != ObjectValue || d.Fields[1].Value.List[1].Kind != EnumValue {
+					t.Errorf("d = %+v", d)
+				}
+			},
+		},
+		{
+			name:   "comments and commas are ignored",
+			source: "# leading\n{ a, b # trailing\n , c }",
+			check: func(t *testing.T, document *Document) {
+				if n := len(document.Operations[0].SelectionSet); n != 3 {
+					t.Errorf("selections = %d", n)
+				}
+			},
+		},
+		{name: "empty document", source: "", wantErr: "no operation"},
+		{name: "unterminated selection set", source: "{ a { b }", wantErr: `expected "}"`},
+		{name: "empty selection set", source: "{ }", wantErr: "selection set cannot be empty"},
+		{name: "unterminated string", source: `{ a(b: "open) }`, wantErr: "syntax error"},
+		{name: "variable in a default", source: `query ($a: Int = $b) { a }`, wantErr: "expected a value"},
+		{name: "fragment named on", source: `fragment on on User { id }`, wantErr: `cannot be named "on"`},
+		{name: "deeply nested list value", source: "{ a(b: " + strings.Repeat("[", 100) + strings.Repeat("]", 100) + ") }", wantErr: "nested more than"},
+		{name: "deeply nested object value", source: "{ a(b: " + strings.Repeat("{c: ", 100) + "1" + strings.Repeat("}", 100) + ") }", wantErr: "nested more than"},
+		{name: "deeply nested selections", source: strings.Repeat("{ a ", 100) + strings.Repeat("}", 100), wantErr: "nested more than"},
+		{name: "deeply nested list type", source: "query ($a: " + strings.Repeat("[", 100) + "Int" + strings.Repeat("]", 100) + ") { a }", wantErr: "nested more than"},
+		{name: "nesting within the bound", source: "{ a(b: " + strings.Repeat("[", maxNesting-2) + strings.Repeat("]", maxNesting-2) + ") }"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := Parse(test.source)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Parse() error = %v, want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if test.check != nil {
+				test.check(t, document)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is a GraphQL type: a *Scalar, *Enum, *Object or *InputObject, or a
+// *List or *NonNull wrapping one.
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type. Serialize turns a resolved Go value into its JSON
+// form; ParseValue turns a JSON variable or a literal's Go value into what
+// resolvers receive.
+type Scalar struct {
+	Name        string
+	Description string
+	Serialize   func(value any) (any, error)
+	ParseValue  func(value any) (any, error)
+}
+
+type Enum struct {
+	Name        string
+	Description string
+	Values      []string
+}
+
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*FieldDefinition
+}
+
+type InputObject struct {
+	Name        string
+	Description string
+	Fields      []*ArgumentDefinition
+}
+
+type List struct {
+	Of Type
+}
+
+type NonNull struct {
+	Of Type
+}
+
+func (t *Scalar) String() string      { return t.Name }
+func (t *Enum) String() string        { return t.Name }
+func (t *Object) String() string      { return t.Name }
+func (t *InputObject) String() string { return t.Name }
+func (t *List) String() string        { return "[" + t.Of.String() + "]" }
+func (t *NonNull) String() string     { return t.Of.String() + "!" }
+
+// ResolveFunc produces a field's value from its parent's. It may return a
+// Thunk to have the value produced later, once every field at the same depth
+// has been resolved, which is what lets a Loader batch them.
+type ResolveFunc func(ctx context.Context, source any, args map[string]any) (any, error)
+
+type Thunk func() (any, error)
+
+type FieldDefinition struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*ArgumentDefinition
+	// Resolve defaults to reading the source struct's member whose json name
+	// is the field's name in snake_case.
+	Resolve ResolveFunc
+	// Complexity is what the field adds to a query's complexity, given its
+	// arguments and the complexity of its own selections. It defaults to 1
+	// plus the selections'.
+	Complexity func(args map[string]any, childComplexity int) int
+}
+
+type ArgumentDefinition struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     any
+}
+
+func (o *Object) Field(name string) *FieldDefinition {
+	for _, field := range o.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// Schema is the set of types reachable from its root operation types.
+type Schema struct {
+	Query    *Object
+	Mutation *Object
+	types    map[string]Type
+}
+
+// NewSchema indexes the types reachable from query and mutation, and fails
+// when two different types share a name.
+func NewSchema(query, mutation *Object) (*Schema, error) {
+	schema := &Schema{Query: query, Mutation: mutation, types: map[string]Type{}}
+	for _, scalar := range []*Scalar{String, Int, Float, Boolean, ID} {
+		schema.types[scalar.Name] = scalar
+	}
+	roots := []Type{query}
+	if mutation != nil {
+		roots = append(roots, mutation)
+	}
+	for _, root := range roots {
+		if err := schema.collect(root); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+func (s *Schema) collect(t Type) error {
+	t = namedType(t)
+	name := t.String()
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: two types are named %s", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+
+	switch t := t.(type) {
+	case *Object:
+		for _, field := range t.Fields {
+			if err := s.collect(field.Type); err != nil {
+				return err
+			}
+			for _, arg := range field.Args {
+				if err := s.collect(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+	case *InputObject:
+		for _, field := range t.Fields {
+			if err := s.collect(field.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Type finds a named type.
+func (s *Schema) Type(name string) Type {
+	return s.types[name]
+}
+
+// String prints the schema in the schema definition language.
+func (s *Schema) String() string {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var sdl strings.Builder
+	sdl.WriteString("schema {\n  query: " + s.Query.Name + "\n")
+	if s.Mutation != nil {
+		sdl.WriteString("  mutation: " + s.Mutation.Name + "\n")
+	}
+	sdl.WriteString("}\n")
+
+	for _, name := range names {
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			if !slices.Contains([]*Scalar{String, Int, Float, Boolean, ID}, t) {
+				sdl.WriteString("\n" + description(t.Description, "") + "scalar " + t.Name + "\n")
+			}
+		case *Enum:
+			sdl.WriteString("\n" + description(t.Description, "") + "enum " + t.Name + " {\n")
+			for _, value := range t.Values {
+				sdl.WriteString("  " + value + "\n")
+			}
+			sdl.WriteString("}\n")
+		case *Object:
+			sdl.WriteString("\n" + description(t.Description, "") + "type " + t.Name + " {\n")
+			for _, field := range t.Fields {
+				sdl.WriteString(description(field.Description, "  ") + "  " + field.Name + arguments(field.Args) + ": " + field.Type.String() + "\n")
+			}
+			sdl.WriteString("}\n")
+		case *InputObject:
+			sdl.WriteString("\n" + description(t.Description, "") + "input " + t.Name + " {\n")
+			for _, field := range t.Fields {
+				sdl.WriteString(description(field.Description, "  ") + "  " + argument(field) + "\n")
+			}
+			sdl.WriteString("}\n")
+		}
+	}
+	return sdl.String()
+}
+
+func description(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	return indent + strconv.Quote(text) + "\n"
+}
+
+func arguments(args []*ArgumentDefinition) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = argument(arg)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func argument(arg *ArgumentDefinition) string {
+	s := arg.Name + ": " + arg.Type.String()
+	if arg.Default != nil {
+		value, _ := json.Marshal(arg.Default)
+		if _, ok := namedType(arg.Type).(*Enum); ok {
+			value = []byte(fmt.Sprint(arg.Default))
+		}
+		s += " = " + string(value)
+	}
+	return s
+}
+
+func namedType(t Type) Type {
+	for {
+		switch wrapper := t.(type) {
+		case *List:
+			t = wrapper.Of
+		case *NonNull:
+			t = wrapper.Of
+		default:
+			return t
+		}
+	}
+}
+
+func isInput(t Type) bool {
+	switch namedType(t).(type) {
+	case *Scalar, *Enum, *InputObject:
+		return true
+	}
+	return false
+}
+
+var (
+	String = &Scalar{
+		Name: "String",
+		Serialize: func(value any) (any, error) {
+			switch value := value.(type) {
+			case string:
+				return value, nil
+			case fmt.Stringer:
+				return value.String(), nil
+			}
+			return nil, fmt.Errorf("%T is not a string", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+			return nil, fmt.Errorf("must be a string")
+		},
+	}
+
+	Int = &Scalar{
+		Name: "Int",
+		Serialize: func(value any) (any, error) {
+			v := reflect.ValueOf(value)
+			switch {
+			case v.CanInt():
+				return v.Int(), nil
+			case v.CanUint():
+				return v.Uint(), nil
+			}
+			return nil, fmt.Errorf("%T is not an integer", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			var f float64
+			switch value := value.(type) {
+			case json.Number:
+				i, err := value.Int64()
+				if err != nil {
+					return nil, fmt.Errorf("must be an integer")
+				}
+				return int(i), nil
+			case float64:
+				f = value
+			case int:
+				return value, nil
+			case int64:
+				return int(value), nil
+			default:
+				return nil, fmt.Errorf("must be an integer")
+			}
+			if f != math.Trunc(f) || math.Abs(f) > math.MaxInt64 {
+				return nil, fmt.Errorf("must be an integer")
+			}
+			return int(f), nil
+		},
+	}
+
+	Float = &Scalar{
+		Name: "Float",
+		Serialize: func(value any) (any, error) {
+			v := reflect.ValueOf(value)
+			switch {
+			case v.CanFloat():
+				return v.Float(), nil
+			case v.CanInt():
+				return float64(v.Int()), nil
+			}
+			return nil, fmt.Errorf("%T is not a number", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			switch value := value.(type) {
+			case json.Number:
+				f, err := value.Float64()
+				if err != nil {
+					return nil, fmt.Errorf("must be a number")
+				}
+				return f, nil
+			case float64:
+				return value, nil
+			case int:
+				return float64(value), nil
+			case int64:
+				return float64(value), nil
+			}
+			return nil, fmt.Errorf("must be a number")
+		},
+	}
+
+	Boolean = &Scalar{
+		Name: "Boolean",
+		Serialize: func(value any) (any, error) {
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("%T is not a boolean", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("must be true or false")
+		},
+	}
+
+	ID = &Scalar{
+		Name: "ID",
+		Serialize: func(value any) (any, error) {
+			switch value := value.(type) {
+			case string:
+				return value, nil
+			case int, int64:
+				return fmt.Sprint(value), nil
+			}
+			return nil, fmt.Errorf("%T is not an ID", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			switch value := value.(type) {
+			case string:
+				return value, nil
+			case json.Number:
+				return value.String(), nil
+			case int:
+				return strconv.Itoa(value), nil
+			case int64:
+				return strconv.FormatInt(value, 10), nil
+			}
+			return nil, fmt.Errorf("must be an ID")
+		},
+	}
+
+	// DateTime is an RFC 3339 timestamp.
+	DateTime = &Scalar{
+		Name:        "DateTime",
+		Description: "RFC 3339 timestamp",
+		Serialize: func(value any) (any, error) {
+			switch t := value.(type) {
+			case time.Time:
+				return t.Format(time.RFC3339Nano), nil
+			case *time.Time:
+				return t.Format(time.RFC3339Nano), nil
+			}
+			return nil, fmt.Errorf("%T is not a time", value)
+		},
+		ParseValue: func(value any) (any, error) {
+			if t, ok := value.(time.Time); ok {
+				return t, nil
+			}
+			if s, ok := value.(string); ok {
+				if t, err := time.Parse(time.RFC3339, s); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("must be an RFC 3339 timestamp")
+		},
+	}
+
+	// JSON is any JSON value, passed through as is.
+	JSON = &Scalar{
+		Name:        "JSON",
+		Description: "Any JSON value",
+		Serialize:   func(value any) (any, error) { return value, nil },
+		ParseValue:  func(value any) (any, error) { return value, nil },
+	}
+)
@@ -0,0 +1,101 @@
+// Package ical writes the subset of iCalendar (RFC 5545) a subscribed
+// calendar of all-day events needs.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the folding limit from RFC 5545 section 3.1, excluding the
+// line break.
+const maxLineOctets = 75
+
+const dateLayout = "20060102"
+
+// Event is an all-day event on the day of Start.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	// Yearly repeats the event every year. An event on Feb 29 repeats on the
+	// last day of February, so common years do not skip it.
+	Yearly bool
+}
+
+type Writer struct {
+	w     *bufio.Writer
+	stamp string
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:     bufio.NewWriter(w),
+		stamp: time.Now().UTC().Format("20060102T150405Z"),
+	}
+}
+
+// Begin opens the calendar; name is what calendar apps show for it.
+func (w *Writer) Begin(name string) {
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//web-server//contacts//EN")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.line("X-WR-CALNAME:" + escape(name))
+}
+
+func (w *Writer) Write(event *Event) {
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + escape(event.UID))
+	w.line("DTSTAMP:" + w.stamp)
+	w.line("DTSTART;VALUE=DATE:" + event.Start.Format(dateLayout))
+	w.line("DURATION:P1D")
+	if event.Yearly {
+		if event.Start.Month() == time.February && event.Start.Day() == 29 {
+			w.line("RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1")
+		} else {
+			w.line("RRULE:FREQ=YEARLY")
+		}
+	}
+	w.line("SUMMARY:" + escape(event.Summary))
+	if event.Description != "" {
+		w.line("DESCRIPTION:" + escape(event.Description))
+	}
+	// Birthdays and the like should not show the user as busy.
+	w.line("TRANSP:TRANSPARENT")
+	w.line("END:VEVENT")
+}
+
+// End closes the calendar and flushes everything written.
+func (w *Writer) End() error {
+	w.line("END:VCALENDAR")
+	return w.w.Flush()
+}
+
+// line writes a content line, folding it so no physical line exceeds the
+// octet limit and no UTF-8 sequence is split.
+func (w *Writer) line(content string) {
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		w.w.WriteString(content[:cut])
+		w.w.WriteString("\r\n ")
+		content = content[cut:]
+		// The leading space of a continuation line counts towards its length.
+		limit = maxLineOctets - 1
+	}
+	w.w.WriteString(content)
+	w.w.WriteString("\r\n")
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,47 @@
+// Package notify delivers notifications to users. Notifier is the extension
+// point: the server logs notifications by default and can post them to a
+// webhook instead.
+package notify
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+const KindReminder = "reminder"
+
+type Notification struct {
+	UserId    string `json:"user_id"`
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	ContactId string `json:"contact_id,omitempty"`
+	// OccursOn is the YYYY-MM-DD day a reminder is about.
+	OccursOn string `json:"occurs_on,omitempty"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification *Notification) error
+}
+
+// LogNotifier writes notifications to the log, for development and for
+// deployments that pick them up from there.
+type LogNotifier struct {
+	Log *zap.Logger
+}
+
+func NewLogNotifier(log *zap.Logger) *LogNotifier {
+	return &LogNotifier{Log: log}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification *Notification) error {
+	n.Log.Info("notification",
+		zap.String("user_id", notification.UserId),
+		zap.String("kind", notification.Kind),
+		zap.String("title", notification.Title),
+		zap.String("body", notification.Body),
+		zap.String("contact_id", notification.ContactId),
+		zap.String("occurs_on", notification.OccursOn))
+	return nil
+}
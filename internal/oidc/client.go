@@ -0,0 +1,202 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDiscovery     = errors.New("oidc: discovery failed")
+	ErrTokenExchange = errors.New("oidc: token exchange failed")
+)
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+}
+
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint,omitempty"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type Client struct {
+	Config Config
+
+	mu        sync.Mutex
+	discovery *Discovery
+	keys      *keySet
+}
+
+func NewClient(config Config) *Client {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	return &Client{Config: config}
+}
+
+func (c *Client) Discover(ctx context.Context) (*Discovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(c.Config.Issuer, "/") + "/.well-known/openid-configuration"
+	discovery := new(Discovery)
+	if err := c.getJSON(ctx, wellKnown, discovery); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	if discovery.Issuer != c.Config.Issuer {
+		return nil, fmt.Errorf("%w: issuer mismatch, expected %q got %q", ErrDiscovery, c.Config.Issuer, discovery.Issuer)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete provider metadata", ErrDiscovery)
+	}
+
+	c.discovery = discovery
+	c.keys = newKeySet(discovery.JWKSURI, c.getJSON)
+	return discovery, nil
+}
+
+func (c *Client) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string, extra url.Values) (string, error) {
+	discovery, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	for key, values := range extra {
+		query[key] = values
+	}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.Config.ClientID)
+	query.Set("redirect_uri", c.Config.RedirectURL)
+	query.Set("scope", strings.Join(c.Config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (c *Client) Exchange(ctx context.Context, code string, codeVerifier string) (*TokenResponse, error) {
+	discovery, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.Config.RedirectURL)
+	form.Set("client_id", c.Config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if c.Config.ClientSecret != "" {
+		form.Set("client_secret", c.Config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.Config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d: %s", ErrTokenExchange, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	token := new(TokenResponse)
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: response has no id_token", ErrTokenExchange)
+	}
+
+	return token, nil
+}
+
+func (c *Client) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*Claims, error) {
+	if _, err := c.Discover(ctx); err != nil {
+		return nil, err
+	}
+
+	claims := new(Claims)
+	if err := verifyJWT(ctx, c.keys, rawIDToken, claims); err != nil {
+		return nil, err
+	}
+
+	if err := claims.validate(c.Config.Issuer, c.Config.ClientID, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, target string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.Config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", target, res.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(out)
+}
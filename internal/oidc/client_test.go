@@ -0,0 +1,382 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/oidc"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/oidc/fakeissuer"
+)
+
+const (
+	clientID     = "web-server"
+	clientSecret = "web-server-secret"
+	redirectURL  = "http://localhost:3000/api/auth/oidc/callback"
+)
+
+type testIssuer struct {
+	*fakeissuer.Server
+	URL         string
+	HTTPClient  *http.Client
+	JWKSFetches atomic.Int32
+}
+
+// newIssuer serves a fake issuer and returns a client registered with it.
+func newIssuer(t *testing.T) (*testIssuer, *oidc.Client) {
+	t.Helper()
+	issuer := new(testIssuer)
+
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jwks" {
+			issuer.JWKSFetches.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	fake, err := fakeissuer.New(fakeissuer.Config{Issuer: server.URL, ClientID: clientID, ClientSecret: clientSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler = fake.Handler()
+
+	issuer.Server = fake
+	issuer.URL = server.URL
+	issuer.HTTPClient = server.Client()
+
+	client := oidc.NewClient(oidc.Config{
+		Issuer:       server.URL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		HTTPClient:   server.Client(),
+	})
+	return issuer, client
+}
+
+func (i *testIssuer) claims() oidc.Claims {
+	now := time.Now()
+	return oidc.Claims{
+		Issuer:    i.URL,
+		Subject:   "ada",
+		Audience:  oidc.Audience{clientID},
+		ExpiresAt: now.Add(time.Hour).Unix(),
+		IssuedAt:  now.Unix(),
+		Nonce:     "nonce",
+	}
+}
+
+func (i *testIssuer) sign(t *testing.T, claims oidc.Claims) string {
+	t.Helper()
+	token, err := i.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer, client := newIssuer(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			token: func() string { return issuer.sign(t, issuer.claims()) },
+		},
+		{
+			name: "several audiences with client as authorized party",
+			token: func() string {
+				claims := issuer.claims()
+				claims.Audience = oidc.Audience{"other", clientID}
+				claims.AuthorizedParty = clientID
+				return issuer.sign(t, claims)
+			},
+		},
+		{
+			name: "expired within clock skew",
+			token: func() string {
+				claims := issuer.claims()
+				claims.ExpiresAt = time.Now().Add(-30 * time.Second).Unix()
+				return issuer.sign(t, claims)
+			},
+		},
+		{
+			name: "bad signature",
+			token: func() string {
+				token := issuer.sign(t, issuer.claims())
+				claims := issuer.claims()
+				claims.Subject = "mallory"
+				payload, _ := json.Marshal(claims)
+				parts := strings.Split(token, ".")
+				return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+			},
+			wantErr: true,
+		},
+		{
+			name: "signed by another key under an unknown key id",
+			token: func() string {
+				token, err := oidc.SignJWT(otherKey, "other", issuer.claims())
+				if err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				claims := issuer.claims()
+				claims.Issuer = "https://evil.example.com"
+				return issuer.sign(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				claims := issuer.claims()
+				claims.Audience = oidc.Audience{"other"}
+				return issuer.sign(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "several audiences without authorized party",
+			token: func() string {
+				claims := issuer.claims()
+				claims.Audience = oidc.Audience{clientID, "other"}
+				return issuer.sign(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong authorized party",
+			token: func() string {
+				claims := issuer.claims()
+				claims.Audience = oidc.Audience{clientID, "other"}
+				claims.AuthorizedParty = "other"
+				return issuer.sign(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := issuer.claims()
+				claims.ExpiresAt = time.Now().Add(-2 * time.Minute).Unix()
+				return issuer.sign(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "issued in the future",
+			token: func() string {
+				claims := issuer.claims()
+				claims.IssuedAt = time.Now().Add(5 * time.Minute).Unix()
+				return issuer.sign(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "nonce mismatch",
+			token: func() string {
+				claims := issuer.claims()
+				claims.Nonce = "other"
+				return issuer.sign(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing subject",
+			token: func() string {
+				claims := issuer.claims()
+				claims.Subject = ""
+				return issuer.sign(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "alg none",
+			token: func() string {
+				return withHeader(t, issuer.sign(t, issuer.claims()), `{"alg":"none"}`, "")
+			},
+			wantErr: true,
+		},
+		{
+			name: "alg HS256",
+			token: func() string {
+				token := issuer.sign(t, issuer.claims())
+				return withHeader(t, token, `{"alg":"HS256","kid":"`+keyID(t, token)+`"}`, strings.Split(token, ".")[2])
+			},
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			token:   func() string { return "not.a-token" },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := client.VerifyIDToken(context.Background(), tt.token(), "nonce")
+			if tt.wantErr {
+				if !errors.Is(err, oidc.ErrInvalidToken) {
+					t.Fatalf("VerifyIDToken() error = %v, want %v", err, oidc.ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error = %v", err)
+			}
+			if claims.Subject != "ada" {
+				t.Errorf("VerifyIDToken() subject = %q, want %q", claims.Subject, "ada")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRefetchesKeysOnUnknownKeyId(t *testing.T) {
+	issuer, client := newIssuer(t)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := client.VerifyIDToken(ctx, issuer.sign(t, issuer.claims()), "nonce"); err != nil {
+			t.Fatalf("VerifyIDToken() error = %v", err)
+		}
+	}
+	if fetches := issuer.JWKSFetches.Load(); fetches != 1 {
+		t.Fatalf("fetched the key set %d times for one key, want once", fetches)
+	}
+
+	if err := issuer.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.VerifyIDToken(ctx, issuer.sign(t, issuer.claims()), "nonce"); err != nil {
+		t.Fatalf("VerifyIDToken() after rotation error = %v", err)
+	}
+	if fetches := issuer.JWKSFetches.Load(); fetches != 2 {
+		t.Errorf("fetched the key set %d times after rotation, want twice", fetches)
+	}
+}
+
+func TestLoginRoundTrip(t *testing.T) {
+	issuer, client := newIssuer(t)
+	ctx := context.Background()
+
+	verifier := oidc.NewCodeVerifier()
+	authorizationURL, err := client.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallengeS256(verifier),
+		url.Values{"login_hint": {"grace"}})
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	callback := authorize(t, issuer, authorizationURL)
+	if !strings.HasPrefix(callback.String(), redirectURL+"?") {
+		t.Fatalf("redirected to %s, want %s", callback, redirectURL)
+	}
+	if state := callback.Query().Get("state"); state != "state" {
+		t.Fatalf("callback state = %q, want %q", state, "state")
+	}
+
+	code := callback.Query().Get("code")
+	if _, err := client.Exchange(ctx, code, oidc.NewCodeVerifier()); !errors.Is(err, oidc.ErrTokenExchange) {
+		t.Fatalf("Exchange() with another verifier error = %v, want %v", err, oidc.ErrTokenExchange)
+	}
+
+	// The failed exchange used up the code, so log in again.
+	code = authorize(t, issuer, authorizationURL).Query().Get("code")
+	token, err := client.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if _, err := client.Exchange(ctx, code, verifier); !errors.Is(err, oidc.ErrTokenExchange) {
+		t.Fatalf("Exchange() reusing the code error = %v, want %v", err, oidc.ErrTokenExchange)
+	}
+
+	if _, err := client.VerifyIDToken(ctx, token.IDToken, "other"); !errors.Is(err, oidc.ErrInvalidToken) {
+		t.Fatalf("VerifyIDToken() with another nonce error = %v, want %v", err, oidc.ErrInvalidToken)
+	}
+
+	claims, err := client.VerifyIDToken(ctx, token.IDToken, "nonce")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if claims.Subject != "grace" || claims.PreferredUsername != "grace" || claims.Email != "grace@example.com" ||
+		!claims.EmailVerified {
+		t.Errorf("VerifyIDToken() = %+v, want the login_hint identity", claims)
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	issuer, _ := newIssuer(t)
+	client := oidc.NewClient(oidc.Config{Issuer: issuer.URL + "/", ClientID: clientID, HTTPClient: issuer.HTTPClient})
+
+	if _, err := client.Discover(context.Background()); !errors.Is(err, oidc.ErrDiscovery) {
+		t.Errorf("Discover() error = %v, want %v", err, oidc.ErrDiscovery)
+	}
+}
+
+// authorize follows the authorization URL to the redirect back to the client.
+func authorize(t *testing.T, issuer *testIssuer, authorizationURL string) *url.URL {
+	t.Helper()
+	httpClient := *issuer.HTTPClient
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	res, err := httpClient.Get(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", res.StatusCode, http.StatusFound)
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+// withHeader replaces the header of token, keeping its payload.
+func withHeader(t *testing.T, token string, header string, signature string) string {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	return base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + parts[1] + "." + signature
+}
+
+func keyID(t *testing.T, token string) string {
+	t.Helper()
+	header, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(header, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded.Kid
+}
@@ -0,0 +1,261 @@
+// Package fakeissuer is a minimal OpenID Connect provider for local
+// development and integration testing. It auto-approves every authorization
+// request, so it must never be exposed outside a development environment.
+package fakeissuer
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/oidc"
+)
+
+type Identity struct {
+	Subject           string
+	PreferredUsername string
+	Name              string
+	Email             string
+}
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// Identity is issued when the authorization request carries no login_hint.
+	// With a login_hint the hint becomes both subject and username.
+	Identity Identity
+	TokenTTL time.Duration
+}
+
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	identity      Identity
+	expiresAt     time.Time
+}
+
+type Server struct {
+	Config Config
+
+	mu    sync.Mutex
+	key   *rsa.PrivateKey
+	keyID string
+	codes map[string]authorization
+}
+
+func New(config Config) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.TokenTTL == 0 {
+		config.TokenTTL = time.Hour
+	}
+
+	if config.Identity.Subject == "" {
+		config.Identity = Identity{
+			Subject:           "fake-user",
+			PreferredUsername: "fake-user",
+			Name:              "Fake User",
+			Email:             "fake-user@example.com",
+		}
+	}
+
+	return &Server{
+		Config: config,
+		key:    key,
+		keyID:  oidc.RandomString(8),
+		codes:  map[string]authorization{},
+	}, nil
+}
+
+// Sign signs claims with the current key, for tests that need ID tokens the
+// token endpoint would not issue.
+func (s *Server) Sign(claims any) (string, error) {
+	s.mu.Lock()
+	key, keyID := s.key, s.keyID
+	s.mu.Unlock()
+	return oidc.SignJWT(key, keyID, claims)
+}
+
+// RotateKey replaces the signing key with a new one under a new key id, as
+// providers do when they roll their keys.
+func (s *Server) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.key, s.keyID = key, oidc.RandomString(8)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+	return mux
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := strings.TrimSuffix(s.Config.Issuer, "/")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.Config.Issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_post", "client_secret_basic"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	set := oidc.NewJSONWebKeySet(s.keyID, &s.key.PublicKey)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, set)
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("response_type") != "code" {
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("client_id") != s.Config.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	identity := s.Config.Identity
+	if hint := query.Get("login_hint"); hint != "" {
+		identity = Identity{
+			Subject:           hint,
+			PreferredUsername: hint,
+			Name:              hint,
+			Email:             hint + "@example.com",
+		}
+	}
+
+	code := oidc.RandomString(24)
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		clientID:      s.Config.ClientID,
+		redirectURI:   redirectURI.String(),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		identity:      identity,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	s.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+
+	if clientID != s.Config.ClientID ||
+		subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.Config.ClientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	grant, found := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	if !found || time.Now().After(grant.expiresAt) ||
+		grant.redirectURI != r.PostForm.Get("redirect_uri") ||
+		grant.codeChallenge != oidc.CodeChallengeS256(r.PostForm.Get("code_verifier")) {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idToken, err := s.Sign(oidc.Claims{
+		Issuer:            s.Config.Issuer,
+		Subject:           grant.identity.Subject,
+		Audience:          oidc.Audience{grant.clientID},
+		ExpiresAt:         now.Add(s.Config.TokenTTL).Unix(),
+		IssuedAt:          now.Unix(),
+		Nonce:             grant.nonce,
+		Name:              grant.identity.Name,
+		PreferredUsername: grant.identity.PreferredUsername,
+		Email:             grant.identity.Email,
+		EmailVerified:     true,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, oidc.TokenResponse{
+		AccessToken: oidc.RandomString(24),
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		ExpiresIn:   int64(s.Config.TokenTTL.Seconds()),
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
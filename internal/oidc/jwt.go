@@ -0,0 +1,248 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("oidc: invalid id token")
+
+const clockSkew = time.Minute
+
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a Audience) contains(value string) bool {
+	for _, aud := range a {
+		if aud == value {
+			return true
+		}
+	}
+	return false
+}
+
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          Audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp,omitempty"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	PreferredUsername string   `json:"preferred_username,omitempty"`
+	Email             string   `json:"email,omitempty"`
+	EmailVerified     bool     `json:"email_verified,omitempty"`
+}
+
+func (c *Claims) validate(issuer string, clientID string, nonce string, now time.Time) error {
+	if c.Issuer != issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, c.Issuer)
+	}
+
+	if c.Subject == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	if !c.Audience.contains(clientID) {
+		return fmt.Errorf("%w: client is not in audience", ErrInvalidToken)
+	}
+
+	if len(c.Audience) > 1 && c.AuthorizedParty != clientID {
+		return fmt.Errorf("%w: unexpected authorized party %q", ErrInvalidToken, c.AuthorizedParty)
+	}
+
+	if now.Add(-clockSkew).Unix() >= c.ExpiresAt {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+
+	if c.IssuedAt > now.Add(clockSkew).Unix() {
+		return fmt.Errorf("%w: token issued in the future", ErrInvalidToken)
+	}
+
+	if c.Nonce != nonce {
+		return fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+func NewJSONWebKeySet(kid string, key *rsa.PublicKey) JSONWebKeySet {
+	return JSONWebKeySet{Keys: []jsonWebKey{{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+}
+
+type keySet struct {
+	uri   string
+	fetch func(ctx context.Context, target string, out any) error
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+func newKeySet(uri string, fetch func(ctx context.Context, target string, out any) error) *keySet {
+	return &keySet{uri: uri, fetch: fetch}
+}
+
+func (s *keySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	// Unknown kid usually means the provider rotated its keys, so refresh once.
+	set := new(JSONWebKeySet)
+	if err := s.fetch(ctx, s.uri, set); err != nil {
+		return nil, fmt.Errorf("%w: fetch jwks: %v", ErrInvalidToken, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	s.keys = keys
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ,omitempty"`
+}
+
+func verifyJWT(ctx context.Context, keys *keySet, raw string, claims any) error {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	header := new(jwtHeader)
+	if err := json.Unmarshal(headerJSON, header); err != nil {
+		return fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	if header.Alg != "RS256" {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	key, err := keys.key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("%w: malformed payload", ErrInvalidToken)
+	}
+
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return fmt.Errorf("%w: malformed payload", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func SignJWT(key *rsa.PrivateKey, kid string, claims any) (string, error) {
+	headerJSON, err := json.Marshal(jwtHeader{Alg: "RS256", Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
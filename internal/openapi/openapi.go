@@ -0,0 +1,355 @@
+// Package openapi describes the HTTP API as an OpenAPI 3.1 document and holds
+// traffic to that description. The document is generated: each Route names
+// the Go types it reads and writes, and their schemas are reflected from the
+// types' json and validate tags, so they cannot drift from the code.
+package openapi
+
+import (
+	_ "embed"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Route describes one operation for the document. Query is a struct whose
+// JSON members are the query parameters and Body the JSON request body;
+// Data is what the operation wraps in its {"data": ...} envelope. Bodies
+// that are not JSON are described by media type in BodyTypes and
+// ResponseTypes instead.
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+	Tag     string
+	Public  bool
+	Params  []*Parameter
+	Query   any
+	// Omit names members of Query the route does not take.
+	Omit      []string
+	Body      any
+	BodyTypes map[string]*Schema
+	Data      any
+	Paged     bool
+	// Paging is what Paged adds to the envelope, when it differs from the
+	// Builder's.
+	Paging        any
+	ResponseTypes map[string]*Schema
+	// Statuses are the success statuses, 200 unless set.
+	Statuses []int
+	// Redirect is an operation that answers with a redirect instead.
+	Redirect bool
+	ETag     bool
+}
+
+// Builder puts a Document together from Routes.
+type Builder struct {
+	Info Info
+	// Paging is what paged responses add to the {"data": ...} envelope.
+	Paging any
+	// Problem is the body of every failed request.
+	Problem any
+	// SecurityScheme authenticates every route that is not Public.
+	SecurityScheme *SecurityScheme
+
+	schemas *Schemas
+}
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// Path turns an echo route path into an OpenAPI one, as in
+// /contacts/:contactId to /contacts/{contactId}.
+func Path(path string) string {
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+// Build describes routes, together with every type in types even when no
+// route uses it.
+func (b *Builder) Build(routes []Route, types []any) *Document {
+	b.schemas = NewSchemas()
+	document := &Document{
+		OpenAPI: Version,
+		Info:    b.Info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: b.schemas.Components,
+		},
+	}
+	if b.SecurityScheme != nil {
+		document.Components.SecuritySchemes = map[string]*SecurityScheme{"token": b.SecurityScheme}
+	}
+
+	for _, route := range routes {
+		path := Path(route.Path)
+		item, ok := document.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			document.Paths[path] = item
+		}
+		(*item)[strings.ToLower(route.Method)] = b.operation(route)
+	}
+	for _, value := range types {
+		b.schemas.For(reflect.TypeOf(value))
+	}
+	return document
+}
+
+func (b *Builder) operation(route Route) *Operation {
+	operation := &Operation{
+		OperationID: operationID(route),
+		Summary:     route.Summary,
+		Responses:   map[string]*Response{},
+		Security:    []map[string][]string{},
+	}
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+	if !route.Public && b.SecurityScheme != nil {
+		operation.Security = append(operation.Security, map[string][]string{"token": {}})
+	}
+
+	for _, name := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		operation.Parameters = append(operation.Parameters, b.pathParameter(route, name[1]))
+	}
+	if route.Query != nil {
+		for _, param := range b.queryParameters(reflect.TypeOf(route.Query)) {
+			if !slices.Contains(route.Omit, param.Name) {
+				operation.Parameters = append(operation.Parameters, param)
+			}
+		}
+	}
+	for _, param := range route.Params {
+		if param.In == "path" {
+			continue
+		}
+		i := slices.IndexFunc(operation.Parameters, func(p *Parameter) bool { return p.In == param.In && p.Name == param.Name })
+		if i >= 0 {
+			operation.Parameters[i] = param
+		} else {
+			operation.Parameters = append(operation.Parameters, param)
+		}
+	}
+
+	switch {
+	case route.Body != nil:
+		operation.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/json": {Schema: b.schemas.For(reflect.TypeOf(route.Body))},
+		}}
+	case route.BodyTypes != nil:
+		operation.RequestBody = &RequestBody{Required: true, Content: mediaTypes(route.BodyTypes)}
+	}
+
+	statuses := route.Statuses
+	if statuses == nil && !route.Redirect {
+		statuses = []int{http.StatusOK}
+	}
+	for _, status := range statuses {
+		operation.Responses[strconv.Itoa(status)] = b.response(route, status)
+	}
+	if route.Redirect {
+		operation.Responses[strconv.Itoa(http.StatusFound)] = &Response{
+			Description: http.StatusText(http.StatusFound),
+			Headers:     map[string]*Header{"Location": {Schema: &Schema{Type: "string", Format: "uri"}}},
+		}
+	}
+	if route.ETag && route.Method == http.MethodGet {
+		operation.Responses[strconv.Itoa(http.StatusNotModified)] = &Response{Description: http.StatusText(http.StatusNotModified)}
+	}
+
+	if b.Problem != nil {
+		operation.Responses["default"] = &Response{
+			Description: "Problem",
+			Content: map[string]*MediaType{
+				"application/problem+json": {Schema: b.schemas.For(reflect.TypeOf(b.Problem))},
+			},
+		}
+	}
+	return operation
+}
+
+func (b *Builder) response(route Route, status int) *Response {
+	response := &Response{Description: http.StatusText(status)}
+	if route.Data != nil || route.ResponseTypes != nil {
+		response.Content = mediaTypes(route.ResponseTypes)
+	}
+	if route.Data != nil {
+		response.Content["application/json"] = &MediaType{Schema: b.envelope(route)}
+	}
+	if route.ETag {
+		response.Headers = map[string]*Header{"ETag": {Schema: &Schema{Type: "string"}}}
+	}
+	return response
+}
+
+func (b *Builder) pathParameter(route Route, name string) *Parameter {
+	for _, param := range route.Params {
+		if param.In == "path" && param.Name == name {
+			required := *param
+			required.Required = true
+			return &required
+		}
+	}
+	return &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+}
+
+// queryParameters lists the members of a query struct. Object members are
+// left out, since they do not map onto a single query parameter; routes
+// describe those themselves in Params, which also override generated
+// parameters of the same name.
+func (b *Builder) queryParameters(t reflect.Type) []*Parameter {
+	query := NewSchemas().object(t)
+
+	var params []*Parameter
+	for _, name := range slices.Sorted(maps.Keys(query.Properties)) {
+		schema := query.Properties[name]
+		if schema.Ref != "" || schema.Type == "object" || slices.Equal(typeList(schema), []string{"object", "null"}) {
+			continue
+		}
+		if types := typeList(schema); len(types) == 2 {
+			schema.Type = types[0]
+		}
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: slices.Contains(query.Required, name),
+			Schema:   schema,
+		})
+	}
+	return params
+}
+
+// envelope is the schema of the {"data": ...} wrapper around route's data.
+func (b *Builder) envelope(route Route) *Schema {
+	envelope := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"data": b.schemas.For(reflect.TypeOf(route.Data))},
+		Required:   []string{"data"},
+	}
+	paging := route.Paging
+	if paging == nil {
+		paging = b.Paging
+	}
+	if route.Paged && paging != nil {
+		envelope.Properties["paging"] = b.schemas.For(reflect.TypeOf(paging))
+	}
+	return envelope
+}
+
+func mediaTypes(types map[string]*Schema) map[string]*MediaType {
+	content := make(map[string]*MediaType, len(types))
+	for name, schema := range types {
+		content[name] = &MediaType{Schema: schema}
+	}
+	return content
+}
+
+// operationID names an operation after its method and path, as in
+// get_contacts_contactId_addresses.
+func operationID(route Route) string {
+	var parts []string
+	for _, part := range strings.Split(strings.TrimPrefix(route.Path, "/api"), "/") {
+		part = strings.Trim(part, ":_")
+		if part != "" {
+			parts = append(parts, strings.NewReplacer("-", "_", ".", "_").Replace(part))
+		}
+	}
+	return strings.ToLower(route.Method) + "_" + strings.Join(parts, "_")
+}
+
+func typeList(schema *Schema) []string {
+	switch t := schema.Type.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+// DocsPage is a self-contained page that renders the document served next
+// to it as openapi.json.
+//
+//go:embed docs.html
+var DocsPage []byte
@@ -0,0 +1,275 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldNamePattern mirrors the validator's field_name rule.
+const fieldNamePattern = `^[a-z][a-z0-9_]*$`
+
+// Schema is the part of JSON Schema 2020-12 that the API's types need.
+// Type is a string, or a list of strings for values that may also be null.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	// Closed forbids members other than Properties. It is written as
+	// "additionalProperties": false.
+	Closed bool `json:"-"`
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.Closed {
+		return json.Marshal((*plain)(s))
+	}
+	return json.Marshal(struct {
+		*plain
+		AdditionalProperties bool `json:"additionalProperties"`
+	}{plain: (*plain)(s)})
+}
+
+// Schemas turns Go types into schemas, collecting named structs as
+// components that other schemas refer to.
+type Schemas struct {
+	Components map[string]*Schema
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{Components: map[string]*Schema{}}
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// For is the schema of values of type t as encoding/json writes them.
+func (s *Schemas) For(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return s.For(t.Elem())
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := t.Name()
+		if _, ok := s.Components[name]; !ok {
+			// Reserve the name first, so that recursive types terminate.
+			s.Components[name] = nil
+			s.Components[name] = s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return s.object(t)
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.For(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.For(t.Elem())}
+	default:
+		// Interfaces hold anything.
+		return &Schema{}
+	}
+}
+
+// object is the schema of a struct. Structs with validation rules are
+// requests, whose required members are the ones the validator requires;
+// other structs are responses, which always write members without omitempty.
+func (s *Schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}, Closed: true}
+	request := hasValidation(t)
+	s.addFields(schema, t, request)
+	return schema
+}
+
+func (s *Schemas) addFields(schema *Schema, t reflect.Type, request bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.addFields(schema, field.Type, request)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		omitempty := strings.Contains(options, "omitempty")
+		property := s.For(field.Type)
+		if property.Ref == "" {
+			applyRules(property, field.Tag.Get("validate"))
+		}
+		if (request || !omitempty) && nullable(field.Type) {
+			property.Type = []any{property.Type, "null"}
+		}
+		schema.Properties[name] = property
+
+		if request && hasRule(field.Tag.Get("validate"), "required") || !request && !omitempty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// nullable reports whether encoding/json may write null for a member of
+// type t that is never omitted.
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasValidation(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("validate"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRule(rules, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if rule == "dive" || rule == "keys" {
+			return false
+		}
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules carries the validator's rules over to the schema. Rules after
+// dive apply to the items of a slice or the values of a map; rules between
+// keys and endkeys, and rules that depend on other fields, are left out.
+func applyRules(schema *Schema, rules string) {
+	if rules == "" {
+		return
+	}
+
+	target := schema
+	inKeys := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch {
+		case name == "keys":
+			inKeys = true
+			continue
+		case name == "endkeys":
+			inKeys = false
+			continue
+		case inKeys:
+			continue
+		case name == "dive":
+			switch {
+			case target.Items != nil:
+				target = target.Items
+			case target.AdditionalProperties != nil:
+				target = target.AdditionalProperties
+			default:
+				return
+			}
+			continue
+		}
+		if target.Ref != "" {
+			continue
+		}
+
+		switch name {
+		case "max", "min":
+			applyBound(target, name, param)
+		case "email":
+			target.Format = "email"
+		case "uuid":
+			target.Format = "uuid"
+		case "unique":
+			target.UniqueItems = true
+		case "field_name":
+			target.Pattern = fieldNamePattern
+		case "latitude":
+			target.Minimum, target.Maximum = ptr(-90.0), ptr(90.0)
+		case "longitude":
+			target.Minimum, target.Maximum = ptr(-180.0), ptr(180.0)
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				target.Enum = append(target.Enum, value)
+			}
+			if hasRule(rules, "omitempty") {
+				target.Enum = append(target.Enum, "")
+			}
+		}
+	}
+}
+
+func applyBound(schema *Schema, name, param string) {
+	switch schema.Type {
+	case "string":
+		if n, err := strconv.Atoi(param); err == nil {
+			if name == "max" {
+				schema.MaxLength = &n
+			} else {
+				schema.MinLength = &n
+			}
+		}
+	case "array", "object":
+		if n, err := strconv.Atoi(param); err == nil && schema.Type == "array" {
+			if name == "max" {
+				schema.MaxItems = &n
+			} else {
+				schema.MinItems = &n
+			}
+		}
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			if name == "max" {
+				schema.Maximum = &n
+			} else {
+				schema.Minimum = &n
+			}
+		}
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
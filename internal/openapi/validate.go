@@ -0,0 +1,387 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Violation is one way in which a request or response breaks the document.
+// Where locates it, as in "query.size" or "body.dates[0].type".
+type Violation struct {
+	Where   string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Where + ": " + v.Message
+}
+
+// Validator holds traffic to a Document. It is safe for concurrent use.
+type Validator struct {
+	document *Document
+	aliases  map[string]string
+	patterns sync.Map
+}
+
+// NewValidator holds traffic to document. Aliases maps path prefixes the
+// document leaves out onto the ones it describes them under, as in /api/v1/
+// onto /api/.
+func NewValidator(document *Document, aliases map[string]string) *Validator {
+	return &Validator{document: document, aliases: aliases}
+}
+
+// Operation finds the operation for a method and echo route path.
+func (v *Validator) Operation(method, path string) (*Operation, bool) {
+	for prefix, target := range v.aliases {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			path = target + rest
+			break
+		}
+	}
+	item, ok := v.document.Paths[Path(path)]
+	if !ok {
+		return nil, false
+	}
+	operation, ok := (*item)[strings.ToLower(method)]
+	return operation, ok
+}
+
+// ValidateRequest holds a request's path and query parameters and its body
+// to the operation. Bodies that are not JSON are only checked for their
+// media type.
+func (v *Validator) ValidateRequest(operation *Operation, path map[string]string, query url.Values, contentType string, body []byte) []Violation {
+	var violations []Violation
+	for _, param := range operation.Parameters {
+		var values []string
+		ok := false
+		switch param.In {
+		case "path":
+			var value string
+			if value, ok = path[param.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values, ok = query[param.Name]
+		default:
+			continue
+		}
+		where := param.In + "." + param.Name
+		if !ok {
+			if param.Required {
+				violations = append(violations, Violation{Where: where, Message: "is required"})
+			}
+			continue
+		}
+		value, err := paramValue(param.Schema, values)
+		if err != nil {
+			violations = append(violations, Violation{Where: where, Message: err.Error()})
+			continue
+		}
+		violations = append(violations, v.validate(where, param.Schema, value)...)
+	}
+
+	if operation.RequestBody == nil {
+		return violations
+	}
+	if len(body) == 0 && contentType == "" {
+		if operation.RequestBody.Required {
+			violations = append(violations, Violation{Where: "body", Message: "is required"})
+		}
+		return violations
+	}
+	return append(violations, v.validateContent("body", operation.RequestBody.Content, contentType, body)...)
+}
+
+// ValidateResponse holds a response to the operation. Statuses the operation
+// does not list fall back to its default response.
+func (v *Validator) ValidateResponse(operation *Operation, status int, contentType string, body []byte) []Violation {
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		if status < 400 {
+			return []Violation{{Where: "status", Message: fmt.Sprintf("%d is not a documented response", status)}}
+		}
+		if response, ok = operation.Responses["default"]; !ok {
+			return nil
+		}
+	}
+	if response.Content == nil {
+		if len(body) > 0 {
+			return []Violation{{Where: "body", Message: "must be empty"}}
+		}
+		return nil
+	}
+	return v.validateContent("body", response.Content, contentType, body)
+}
+
+func (v *Validator) validateContent(where string, content map[string]*MediaType, contentType string, body []byte) []Violation {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []Violation{{Where: "content_type", Message: fmt.Sprintf("%q is not a media type", contentType)}}
+	}
+
+	media, ok := content[mediaType]
+	if !ok {
+		for name, candidate := range content {
+			if matchMediaType(name, mediaType) {
+				media, ok = candidate, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return []Violation{{Where: "content_type", Message: fmt.Sprintf("%s is not one of: %s", mediaType, strings.Join(sortedKeys(content), ", "))}}
+	}
+	if !isJSON(mediaType) || media.Schema == nil {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []Violation{{Where: where, Message: "is not valid JSON"}}
+	}
+	return v.validate(where, media.Schema, value)
+}
+
+// matchMediaType matches ranges such as image/* as well as exact types.
+func matchMediaType(pattern, mediaType string) bool {
+	if pattern == "*/*" {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "/*")
+	if !ok {
+		return pattern == mediaType
+	}
+	return strings.HasPrefix(mediaType, prefix+"/")
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// paramValue turns the values given for a parameter into what the
+// parameter's schema describes.
+func paramValue(schema *Schema, values []string) (any, error) {
+	if schema.Type == "array" {
+		items := make([]any, len(values))
+		for i, value := range values {
+			item, err := scalarValue(schema.Items, value)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	if len(values) > 1 {
+		return nil, fmt.Errorf("must be given once")
+	}
+	return scalarValue(schema, values[0])
+}
+
+func scalarValue(schema *Schema, value string) (any, error) {
+	if schema == nil {
+		return value, nil
+	}
+	switch schema.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return json.Number(value), nil
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return json.Number(value), nil
+	case "boolean":
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return parsed, nil
+	default:
+		return value, nil
+	}
+}
+
+func (v *Validator) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = v.document.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// validate holds a JSON value, decoded with UseNumber, to a schema.
+func (v *Validator) validate(where string, schema *Schema, value any) []Violation {
+	schema = v.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	types := typeList(schema)
+	if len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return hasType(value, t) }) {
+		return []Violation{{Where: where, Message: "must be of type " + strings.Join(types, " or ")}}
+	}
+	if value == nil {
+		return nil
+	}
+
+	var violations []Violation
+	fail := func(format string, args ...any) {
+		violations = append(violations, Violation{Where: where, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema.Enum != nil && !slices.Contains(schema.Enum, value) {
+		fail("must be one of: %s", enumList(schema.Enum))
+	}
+
+	switch value := value.(type) {
+	case string:
+		length := len([]rune(value))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fail("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("must be at most %d characters", *schema.MaxLength)
+		}
+		if schema.Pattern != "" && !v.pattern(schema.Pattern).MatchString(value) {
+			fail("must match %s", schema.Pattern)
+		}
+		if message := checkFormat(schema.Format, value); message != "" {
+			fail("%s", message)
+		}
+	case json.Number:
+		number, _ := value.Float64()
+		if schema.Minimum != nil && number < *schema.Minimum {
+			fail("must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			fail("must be at most %v", *schema.Maximum)
+		}
+	case []any:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			fail("must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			fail("must have at most %d items", *schema.MaxItems)
+		}
+		if schema.UniqueItems && hasDuplicates(value) {
+			fail("must not repeat items")
+		}
+		for i, item := range value {
+			violations = append(violations, v.validate(fmt.Sprintf("%s[%d]", where, i), schema.Items, item)...)
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				violations = append(violations, Violation{Where: where + "." + name, Message: "is required"})
+			}
+		}
+		for _, name := range sortedKeys(value) {
+			if property, ok := schema.Properties[name]; ok {
+				violations = append(violations, v.validate(where+"."+name, property, value[name])...)
+			} else if schema.AdditionalProperties != nil {
+				violations = append(violations, v.validate(where+"."+name, schema.AdditionalProperties, value[name])...)
+			} else if schema.Closed {
+				violations = append(violations, Violation{Where: where + "." + name, Message: "is not a known member"})
+			}
+		}
+	}
+	return violations
+}
+
+func (v *Validator) pattern(pattern string) *regexp.Regexp {
+	if re, ok := v.patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, _ := v.patterns.LoadOrStore(pattern, regexp.MustCompile(pattern))
+	return re.(*regexp.Regexp)
+}
+
+func hasType(value any, t string) bool {
+	switch value := value.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		_, err := value.Int64()
+		return t == "integer" && err == nil
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	default:
+		return false
+	}
+}
+
+func checkFormat(format, value string) string {
+	switch format {
+	case "uuid":
+		if uuid.Validate(value) != nil {
+			return "must be a UUID"
+		}
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return "must be an email address"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "uri":
+		if _, err := url.Parse(value); err != nil {
+			return "must be a URI"
+		}
+	}
+	return ""
+}
+
+func hasDuplicates(items []any) bool {
+	seen := map[string]bool{}
+	for _, item := range items {
+		key, _ := json.Marshal(item)
+		if seen[string(key)] {
+			return true
+		}
+		seen[string(key)] = true
+	}
+	return false
+}
+
+func enumList(values []any) string {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = fmt.Sprint(value)
+	}
+	return strings.Join(names, ", ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
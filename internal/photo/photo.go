@@ -0,0 +1,191 @@
+// Package photo prepares uploaded contact photos for storage: it checks the
+// real image type, drops embedded metadata such as EXIF and renders a
+// thumbnail.
+package photo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+	ContentTypeWebP = "image/webp"
+
+	// ThumbnailSize bounds the longer side of a thumbnail, in pixels.
+	ThumbnailSize = 256
+	// MaxPixels guards against decompression bombs: a small file can
+	// declare huge dimensions and exhaust memory once decoded.
+	MaxPixels = 16_000_000
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type, use JPEG, PNG or WebP")
+	ErrInvalidImage    = errors.New("invalid image")
+	ErrTooManyPixels   = fmt.Errorf("image is larger than %d megapixels", MaxPixels/1_000_000)
+)
+
+type Photo struct {
+	ContentType          string
+	Data                 []byte
+	ThumbnailContentType string
+	Thumbnail            []byte
+}
+
+// ThumbnailContentType is the type of the thumbnails Process renders for
+// photos of the type.
+func ThumbnailContentType(contentType string) string {
+	if contentType == ContentTypeWebP {
+		return ContentTypePNG
+	}
+	return contentType
+}
+
+// Process sniffs the image type from the content itself, never trusting the
+// declared one, and returns the photo without metadata plus its thumbnail.
+// JPEG and PNG are re-encoded, which drops every metadata segment; a JPEG is
+// first turned upright according to its EXIF orientation, since the tag is
+// lost with the rest. The thumbnail is of ThumbnailContentType.
+func Process(data []byte) (*Photo, error) {
+	switch contentType := http.DetectContentType(data); contentType {
+	case ContentTypeJPEG:
+		return processJPEG(data)
+	case ContentTypePNG:
+		return processPNG(data)
+	case ContentTypeWebP:
+		return processWebP(data)
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func processJPEG(data []byte) (*Photo, error) {
+	img, err := decode(data, jpeg.DecodeConfig, jpeg.Decode)
+	if err != nil {
+		return nil, err
+	}
+	img = orient(img, jpegOrientation(data))
+
+	photo := &Photo{ContentType: ContentTypeJPEG, ThumbnailContentType: ContentTypeJPEG}
+	if photo.Data, err = encodeJPEG(img, 90); err != nil {
+		return nil, err
+	}
+	if photo.Thumbnail, err = encodeJPEG(thumbnail(img), 85); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+func processPNG(data []byte) (*Photo, error) {
+	img, err := decode(data, png.DecodeConfig, png.Decode)
+	if err != nil {
+		return nil, err
+	}
+
+	photo := &Photo{ContentType: ContentTypePNG, ThumbnailContentType: ContentTypePNG}
+	if photo.Data, err = encodePNG(img); err != nil {
+		return nil, err
+	}
+	if photo.Thumbnail, err = encodePNG(thumbnail(img)); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// decode checks the declared dimensions before decoding and returns the
+// image as RGBA, the form orient and thumbnail work on.
+func decode(data []byte, decodeConfig func(r io.Reader) (image.Config, error),
+	decodeImage func(r io.Reader) (image.Image, error)) (*image.RGBA, error) {
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if err := checkDimensions(config.Width, config.Height); err != nil {
+		return nil, err
+	}
+
+	img, err := decodeImage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba, nil
+}
+
+func checkDimensions(width int, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	if int64(width)*int64(height) > MaxPixels {
+		return ErrTooManyPixels
+	}
+	return nil
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// thumbnail scales img down to fit ThumbnailSize, averaging every source
+// pixel that falls into a target pixel. Smaller images are kept as they are.
+func thumbnail(img *image.RGBA) *image.RGBA {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	if width <= ThumbnailSize && height <= ThumbnailSize {
+		return img
+	}
+
+	thumbWidth, thumbHeight := ThumbnailSize, ThumbnailSize
+	if width > height {
+		thumbHeight = max(1, height*ThumbnailSize/width)
+	} else {
+		thumbWidth = max(1, width*ThumbnailSize/height)
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for ty := 0; ty < thumbHeight; ty++ {
+		y0, y1 := ty*height/thumbHeight, (ty+1)*height/thumbHeight
+		for tx := 0; tx < thumbWidth; tx++ {
+			x0, x1 := tx*width/thumbWidth, (tx+1)*width/thumbWidth
+
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				row := img.Pix[y*img.Stride+x0*4 : y*img.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			count := (x1 - x0) * (y1 - y0)
+			offset := ty*thumb.Stride + tx*4
+			for i := range sum {
+				thumb.Pix[offset+i] = uint8(sum[i] / count)
+			}
+		}
+	}
+	return thumb
+}
@@ -0,0 +1,335 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// gopher is a 75x100 lossless WebP in the simple format: one VP8L chunk of
+// odd length followed by its padding byte.
+func gopher(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/gopher.lossless.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func riff(chunks ...[]byte) []byte {
+	body := bytes.Join(chunks, nil)
+	header := []byte("RIFF\x00\x00\x00\x00WEBP")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+len(body)))
+	return append(header, body...)
+}
+
+func chunk(fourCC string, payload []byte) []byte {
+	out := []byte(fourCC + "\x00\x00\x00\x00")
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(payload)))
+	out = append(out, payload...)
+	if len(payload)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func vp8x(flags byte, width int, height int) []byte {
+	payload := make([]byte, 10)
+	payload[0] = flags
+	payload[4], payload[5], payload[6] = byte(width-1), byte((width-1)>>8), byte((width-1)>>16)
+	payload[7], payload[8], payload[9] = byte(height-1), byte((height-1)>>8), byte((height-1)>>16)
+	return chunk("VP8X", payload)
+}
+
+func TestProcessWebPStripsMetadata(t *testing.T) {
+	simple := gopher(t)
+	vp8l := simple[12:]
+	data := riff(
+		vp8x(0x08|0x04, 75, 100),
+		vp8l,
+		chunk("EXIF", []byte("Exif\x00\x00II*\x00")),
+		chunk("XMP ", []byte("<x:xmpmeta/>")),
+	)
+
+	photo, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := riff(vp8x(0, 75, 100), vp8l)
+	if !bytes.Equal(photo.Data, want) {
+		t.Errorf("Process() data = %q, want %q", photo.Data, want)
+	}
+	if photo.ContentType != ContentTypeWebP || photo.ThumbnailContentType != ContentTypePNG {
+		t.Errorf("Process() types = %s and %s, want %s and %s",
+			photo.ContentType, photo.ThumbnailContentType, ContentTypeWebP, ContentTypePNG)
+	}
+
+	config, err := webp.DecodeConfig(bytes.NewReader(photo.Data))
+	if err != nil {
+		t.Fatalf("stripped photo does not decode: %v", err)
+	}
+	if config.Width != 75 || config.Height != 100 {
+		t.Errorf("stripped photo is %dx%d, want 75x100", config.Width, config.Height)
+	}
+
+	thumb, err := png.DecodeConfig(bytes.NewReader(photo.Thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail is not a PNG: %v", err)
+	}
+	if thumb.Width != 75 || thumb.Height != 100 {
+		t.Errorf("thumbnail is %dx%d, want 75x100", thumb.Width, thumb.Height)
+	}
+}
+
+func TestProcessWebPKeepsSimpleFile(t *testing.T) {
+	simple := gopher(t)
+	photo, err := Process(simple)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if !bytes.Equal(photo.Data, simple) {
+		t.Errorf("Process() changed a file without metadata")
+	}
+}
+
+func TestProcessWebPRejectsMalformed(t *testing.T) {
+	simple := gopher(t)
+	vp8l := simple[12:]
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "RIFF size past the end", data: append([]byte("RIFF\xff\x00\x00\x00"), simple[8:]...)},
+		{name: "chunk length past the end", data: riff(vp8l, []byte("EXIF\xff\x00\x00\x00abc"))},
+		{name: "odd length chunk without padding", data: riff(vp8l, []byte("EXIF\x03\x00\x00\x00abc"))},
+		{name: "trailing bytes shorter than a chunk header", data: riff(vp8l, []byte("EXIF"))},
+		{name: "short VP8X chunk", data: riff(chunk("VP8X", make([]byte, 4)), vp8l)},
+		{name: "VP8L chunk missing its padding byte", data: riff(vp8l[:len(vp8l)-1])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(tt.data); !errors.Is(err, ErrInvalidImage) {
+				t.Errorf("Process() error = %v, want %v", err, ErrInvalidImage)
+			}
+		})
+	}
+}
+
+func TestProcessTooManyPixels(t *testing.T) {
+	pngData := encode(t, png.Encode, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	// Declare 5000x5000 in IHDR and fix its checksum; the pixels never load.
+	binary.BigEndian.PutUint32(pngData[16:], 5000)
+	binary.BigEndian.PutUint32(pngData[20:], 5000)
+	binary.BigEndian.PutUint32(pngData[29:], crc32.ChecksumIEEE(pngData[12:29]))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "PNG", data: pngData},
+		{name: "WebP", data: riff(vp8x(0, 10_000, 10_000), gopher(t)[12:])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(tt.data); !errors.Is(err, ErrTooManyPixels) {
+				t.Errorf("Process() error = %v, want %v", err, ErrTooManyPixels)
+			}
+		})
+	}
+}
+
+func TestProcessRejectsUnsupportedType(t *testing.T) {
+	if _, err := Process([]byte("GIF89a\x01\x00\x01\x00")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Process() error = %v, want %v", err, ErrUnsupportedType)
+	}
+}
+
+func TestProcessJPEGOrientation(t *testing.T) {
+	// A 16x8 image, red on the left and blue on the right, stored as a camera
+	// held upright would store it: orientation 6 turns it clockwise.
+	stored := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			stored.Set(x, y, color.RGBA{R: 255, A: 255})
+			if x >= 8 {
+				stored.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	data := withOrientation(encode(t, func(w io.Writer, img image.Image) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 100})
+	}, stored), 6)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("jpegOrientation() = %d, want 6", jpegOrientation(data))
+	}
+
+	photo, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if bytes.Contains(photo.Data, []byte("Exif\x00\x00")) {
+		t.Errorf("Process() kept the EXIF segment")
+	}
+
+	upright, err := jpeg.Decode(bytes.NewReader(photo.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := upright.Bounds(); bounds.Dx() != 8 || bounds.Dy() != 16 {
+		t.Fatalf("Process() photo is %dx%d, want 8x16", bounds.Dx(), bounds.Dy())
+	}
+	if r, _, b, _ := upright.At(4, 3).RGBA(); r < b {
+		t.Errorf("top of the upright photo is not red")
+	}
+	if r, _, b, _ := upright.At(4, 12).RGBA(); b < r {
+		t.Errorf("bottom of the upright photo is not blue")
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// The stored image is
+	//   a b c
+	//   d e f
+	// and each orientation lists the rows of the upright one.
+	tests := []struct {
+		orientation int
+		want        []string
+	}{
+		{orientation: 1, want: []string{"abc", "def"}},
+		{orientation: 2, want: []string{"cba", "fed"}},
+		{orientation: 3, want: []string{"fed", "cba"}},
+		{orientation: 4, want: []string{"def", "abc"}},
+		{orientation: 5, want: []string{"ad", "be", "cf"}},
+		{orientation: 6, want: []string{"da", "eb", "fc"}},
+		{orientation: 7, want: []string{"fc", "eb", "da"}},
+		{orientation: 8, want: []string{"cf", "be", "ad"}},
+	}
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+		for i, label := range "abcdef" {
+			img.Pix[i*4] = byte(label)
+		}
+
+		out := orient(img, tt.orientation)
+		var rows []string
+		for y := 0; y < out.Rect.Dy(); y++ {
+			var row []byte
+			for x := 0; x < out.Rect.Dx(); x++ {
+				row = append(row, out.Pix[y*out.Stride+x*4])
+			}
+			rows = append(rows, string(row))
+		}
+		if !reflect.DeepEqual(rows, tt.want) {
+			t.Errorf("orient(%d) = %v, want %v", tt.orientation, rows, tt.want)
+		}
+	}
+}
+
+func TestExifOrientationBigEndian(t *testing.T) {
+	tiff := []byte("MM\x00*\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x08\x00\x00\x00\x00\x00\x00")
+	if got := exifOrientation(tiff); got != 8 {
+		t.Errorf("exifOrientation() = %d, want 8", got)
+	}
+	if got := exifOrientation(tiff[:12]); got != 1 {
+		t.Errorf("exifOrientation() of a truncated IFD = %d, want 1", got)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantWidth     int
+		wantHeight    int
+	}{
+		{name: "small image is kept", width: 100, height: 50, wantWidth: 100, wantHeight: 50},
+		{name: "landscape", width: 1024, height: 512, wantWidth: 256, wantHeight: 128},
+		{name: "portrait", width: 300, height: 600, wantWidth: 128, wantHeight: 256},
+		{name: "thin", width: 4000, height: 2, wantWidth: 256, wantHeight: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb := thumbnail(image.NewRGBA(image.Rect(0, 0, tt.width, tt.height)))
+			if thumb.Rect.Dx() != tt.wantWidth || thumb.Rect.Dy() != tt.wantHeight {
+				t.Errorf("thumbnail() is %dx%d, want %dx%d", thumb.Rect.Dx(), thumb.Rect.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestThumbnailAveragesBoxes(t *testing.T) {
+	// A one pixel checkerboard halves to an even grey, and a white left half
+	// stays white while the black right half stays black.
+	checkerboard := image.NewRGBA(image.Rect(0, 0, 512, 512))
+	halves := image.NewRGBA(image.Rect(0, 0, 1024, 512))
+	for y := 0; y < 512; y++ {
+		for x := 0; x < 512; x++ {
+			if (x+y)%2 == 0 {
+				checkerboard.Set(x, y, color.White)
+			} else {
+				checkerboard.Set(x, y, color.Black)
+			}
+		}
+		for x := 0; x < 1024; x++ {
+			if x < 512 {
+				halves.Set(x, y, color.White)
+			} else {
+				halves.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	grey := thumbnail(checkerboard)
+	for i := 0; i < len(grey.Pix); i += 4 {
+		if grey.Pix[i] != 127 || grey.Pix[i+3] != 255 {
+			t.Fatalf("checkerboard thumbnail pixel %d = %v, want grey", i/4, grey.Pix[i:i+4])
+		}
+	}
+
+	split := thumbnail(halves)
+	if got := split.RGBAAt(127, 64); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("left half thumbnail pixel = %v, want white", got)
+	}
+	if got := split.RGBAAt(128, 64); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("right half thumbnail pixel = %v, want black", got)
+	}
+}
+
+func encode(t *testing.T, encoder func(io.Writer, image.Image) error, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := encoder(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF segment with the orientation tag right
+// after the start of image marker.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00\x12\x01\x03\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	binary.LittleEndian.PutUint16(tiff[18:], orientation)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(payload)))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
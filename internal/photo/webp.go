@@ -0,0 +1,70 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/image/webp"
+)
+
+// processWebP strips the EXIF and XMP chunks from the RIFF container without
+// touching the image data, as there is no WebP encoder to re-encode it with.
+// The thumbnail is a PNG, which keeps any transparency.
+func processWebP(data []byte) (*Photo, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("%w: not a WebP file", ErrInvalidImage)
+	}
+
+	img, err := decode(data, webp.DecodeConfig, webp.Decode)
+	if err != nil {
+		return nil, err
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	if size < 4 || 8+size > len(data) {
+		return nil, fmt.Errorf("%w: truncated WebP file", ErrInvalidImage)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("RIFF\x00\x00\x00\x00WEBP")
+
+	body := data[12 : 8+size]
+	for len(body) > 0 {
+		if len(body) < 8 {
+			return nil, fmt.Errorf("%w: truncated WebP chunk", ErrInvalidImage)
+		}
+
+		// Chunks of odd length are followed by a padding byte.
+		fourCC := string(body[:4])
+		length := int(binary.LittleEndian.Uint32(body[4:]))
+		padded := length + length&1
+		if 8+padded > len(body) {
+			return nil, fmt.Errorf("%w: truncated WebP chunk", ErrInvalidImage)
+		}
+		chunk := body[:8+padded]
+		body = body[len(chunk):]
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			continue
+		case "VP8X":
+			if length < 10 {
+				return nil, fmt.Errorf("%w: invalid VP8X chunk", ErrInvalidImage)
+			}
+			// The header flags announce the chunks; clear EXIF and XMP.
+			chunk = bytes.Clone(chunk)
+			chunk[8] &^= 0x08 | 0x04
+		}
+		out.Write(chunk)
+	}
+
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+
+	photo := &Photo{ContentType: ContentTypeWebP, Data: stripped, ThumbnailContentType: ThumbnailContentType(ContentTypeWebP)}
+	if photo.Thumbnail, err = encodePNG(thumbnail(img)); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
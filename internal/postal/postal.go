@@ -0,0 +1,206 @@
+// Package postal knows how addresses are written in each country: which
+// ISO 3166-1 alpha-2 codes exist, which address fields a country requires,
+// what its postal codes look like and how its addresses are laid out on an
+// envelope. The rules are embedded from countries.json, whose format strings
+// follow the conventions of Google's libaddressinput: %A is the street, %C
+// the city, %S the province, %Z the postal code and %n a line break.
+package postal
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultFormat lays out addresses of countries the dataset has no format for.
+const defaultFormat = "%A%n%C%n%S %Z"
+
+//go:embed countries.json
+var countriesJSON []byte
+
+type Country struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	Format  string   `json:"format"`
+	// Require and Upper list the fields, by their format letter, that the
+	// country requires and that it writes in capitals.
+	Require    string `json:"require"`
+	Upper      string `json:"upper"`
+	PostalCode string `json:"postal_code"`
+	Example    string `json:"example"`
+
+	postalCode *regexp.Regexp
+}
+
+type Address struct {
+	Street     string
+	City       string
+	Province   string
+	PostalCode string
+	Country    string
+}
+
+// FieldError names the address field, by its JSON name, that broke the
+// country's rules.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+var fields = []struct {
+	letter byte
+	name   string
+	value  func(Address) string
+}{
+	{'A', "street", func(a Address) string { return a.Street }},
+	{'C', "city", func(a Address) string { return a.City }},
+	{'S', "province", func(a Address) string { return a.Province }},
+	{'Z', "postal_code", func(a Address) string { return a.PostalCode }},
+}
+
+var countries, countriesByName = func() (map[string]*Country, map[string]*Country) {
+	var list []*Country
+	if err := json.Unmarshal(countriesJSON, &list); err != nil {
+		panic(fmt.Errorf("postal: parse countries.json: %w", err))
+	}
+
+	byCode := make(map[string]*Country, len(list))
+	byName := make(map[string]*Country, len(list))
+	for _, country := range list {
+		if country.Format == "" {
+			country.Format = defaultFormat
+		}
+		if country.PostalCode != "" {
+			country.postalCode = regexp.MustCompile(`^(?:` + country.PostalCode + `)$`)
+		}
+		byCode[country.Code] = country
+		byName[strings.ToLower(country.Name)] = country
+		for _, alias := range country.Aliases {
+			byName[alias] = country
+		}
+	}
+	return byCode, byName
+}()
+
+// Lookup finds the country with the given ISO 3166-1 alpha-2 code, in any
+// case.
+func Lookup(code string) (*Country, bool) {
+	country, ok := countries[strings.ToUpper(strings.TrimSpace(code))]
+	return country, ok
+}
+
+// Code turns a country code or English country name into its ISO 3166-1
+// alpha-2 code, or returns "" when it names no country. Imports use it for
+// the country names that other address books write.
+func Code(country string) string {
+	if found, ok := Lookup(country); ok {
+		return found.Code
+	}
+	if found, ok := countriesByName[strings.ToLower(strings.TrimSpace(country))]; ok {
+		return found.Code
+	}
+	return ""
+}
+
+// Normalize trims the address's fields and writes its country and postal
+// code in capitals, the form Validate checks and addresses are stored in.
+func Normalize(address Address) Address {
+	return Address{
+		Street:     strings.TrimSpace(address.Street),
+		City:       strings.TrimSpace(address.City),
+		Province:   strings.TrimSpace(address.Province),
+		PostalCode: strings.ToUpper(strings.TrimSpace(address.PostalCode)),
+		Country:    strings.ToUpper(strings.TrimSpace(address.Country)),
+	}
+}
+
+// Validate holds a normalized address to its country's rules. Addresses
+// without a country have no rules to break.
+func Validate(address Address) []FieldError {
+	if address.Country == "" {
+		return nil
+	}
+
+	country, ok := countries[address.Country]
+	if !ok {
+		return []FieldError{{Field: "country", Message: "must be an ISO 3166-1 alpha-2 country code"}}
+	}
+
+	var errs []FieldError
+	for _, field := range fields {
+		if strings.IndexByte(country.Require, field.letter) >= 0 && field.value(address) == "" {
+			errs = append(errs, FieldError{Field: field.name, Message: "is required for addresses in " + country.Name})
+		}
+	}
+
+	if address.PostalCode != "" && country.postalCode != nil && !country.postalCode.MatchString(address.PostalCode) {
+		errs = append(errs, FieldError{
+			Field:   "postal_code",
+			Message: fmt.Sprintf("must be a valid postal code for %s, such as %s", country.Name, country.Example),
+		})
+	}
+	return errs
+}
+
+// Format lays the address out in lines the way its country writes them, with
+// the country's name in capitals on the last line. Addresses whose country is
+// not a known code, such as ones stored before codes were required, use the
+// default layout and keep their country as written.
+func Format(address Address) string {
+	format, upper := defaultFormat, ""
+	countryLine := strings.TrimSpace(address.Country)
+	if country, ok := Lookup(address.Country); ok {
+		format, upper = country.Format, country.Upper
+		countryLine = strings.ToUpper(country.Name)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(format, "%n") {
+		if line = formatLine(line, address, upper); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if countryLine != "" {
+		lines = append(lines, countryLine)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatLine fills in one line of a format. The text before a missing field
+// is dropped, so that a missing province leaves no dangling comma behind.
+func formatLine(line string, address Address, upper string) string {
+	var out strings.Builder
+	literal := ""
+	for i := 0; i < len(line); i++ {
+		if line[i] != '%' || i+1 == len(line) {
+			literal += line[i : i+1]
+			continue
+		}
+
+		i++
+		value := ""
+		for _, field := range fields {
+			if field.letter == line[i] {
+				value = strings.TrimSpace(field.value(address))
+			}
+		}
+		if value != "" {
+			if strings.IndexByte(upper, line[i]) >= 0 {
+				value = strings.ToUpper(value)
+			}
+			if out.Len() > 0 {
+				out.WriteString(literal)
+			}
+			out.WriteString(value)
+		}
+		literal = ""
+	}
+	return out.String()
+}
@@ -0,0 +1,194 @@
+package postal
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCountries(t *testing.T) {
+	codePattern := regexp.MustCompile(`^[A-Z]{2}$`)
+	for code, country := range countries {
+		if !codePattern.MatchString(code) {
+			t.Errorf("country code %q is not an ISO 3166-1 alpha-2 code", code)
+		}
+		for _, alias := range country.Aliases {
+			if alias != strings.ToLower(alias) {
+				t.Errorf("%s: alias %q must be lowercase to be found", code, alias)
+			}
+		}
+		if country.postalCode != nil && !country.postalCode.MatchString(country.Example) {
+			t.Errorf("%s: example %q does not match its postal code pattern", code, country.Example)
+		}
+		if country.postalCode != nil && country.Example == "" {
+			t.Errorf("%s: postal code pattern has no example", code)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		country string
+		want    string
+	}{
+		{country: "DE", want: "DE"},
+		{country: " us ", want: "US"},
+		{country: "Germany", want: "DE"},
+		{country: "GERMANY", want: "DE"},
+		{country: "Deutschland", want: "DE"},
+		{country: "United States of America", want: "US"},
+		{country: "Holland", want: "NL"},
+		{country: "Czech Republic", want: "CZ"},
+		{country: "Côte d'Ivoire", want: "CI"},
+		{country: "Atlantis", want: ""},
+		{country: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Code(tt.country); got != tt.want {
+			t.Errorf("Code(%q) = %q, want %q", tt.country, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := Normalize(Address{
+		Street:     " 1 Infinite Loop ",
+		City:       " Cupertino\t",
+		Province:   " ca ",
+		PostalCode: " ec1y 8sy\n",
+		Country:    " gb ",
+	})
+	want := Address{Street: "1 Infinite Loop", City: "Cupertino", Province: "ca", PostalCode: "EC1Y 8SY", Country: "GB"}
+	if got != want {
+		t.Errorf("Normalize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		address Address
+		want    []string
+	}{
+		{
+			name:    "no country",
+			address: Address{Street: "Somewhere"},
+		},
+		{
+			name:    "unknown country",
+			address: Address{Country: "XX"},
+			want:    []string{"country"},
+		},
+		{
+			name:    "country without rules",
+			address: Address{Country: "AD"},
+		},
+		{
+			name:    "valid postal code",
+			address: Address{Street: "Unter den Linden 1", City: "Berlin", PostalCode: "10117", Country: "DE"},
+		},
+		{
+			name:    "invalid postal code",
+			address: Address{Street: "Unter den Linden 1", City: "Berlin", PostalCode: "1011", Country: "DE"},
+			want:    []string{"postal_code"},
+		},
+		{
+			name:    "postal code with optional separator",
+			address: Address{Street: "Main St 1", City: "Cupertino", Province: "CA", PostalCode: "95014-2083", Country: "US"},
+		},
+		{
+			name:    "postal code matched in full",
+			address: Address{Street: "Main St 1", City: "Cupertino", Province: "CA", PostalCode: "95014X", Country: "US"},
+			want:    []string{"postal_code"},
+		},
+		{
+			name:    "alternative postal code pattern",
+			address: Address{Street: "1 Main St", City: "London", PostalCode: "GIR 0AA", Country: "GB"},
+		},
+		{
+			name:    "missing required province",
+			address: Address{Street: "Main St 1", City: "Cupertino", PostalCode: "95014", Country: "US"},
+			want:    []string{"province"},
+		},
+		{
+			name:    "missing required fields",
+			address: Address{Country: "AU"},
+			want:    []string{"street", "city", "province", "postal_code"},
+		},
+		{
+			name:    "postal code not required but checked",
+			address: Address{Street: "Road 1", City: "Dhaka", PostalCode: "13400", Country: "BD"},
+			want:    []string{"postal_code"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range Validate(tt.address) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMessage(t *testing.T) {
+	errs := Validate(Address{Street: "Dam 1", City: "Amsterdam", PostalCode: "1012", Country: "NL"})
+	want := []FieldError{{Field: "postal_code", Message: "must be a valid postal code for Netherlands, such as 1234 AB"}}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Validate() = %v, want %v", errs, want)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		address Address
+		want    string
+	}{
+		{
+			name:    "city before postal code with upper case fields",
+			address: Address{Street: "1600 Amphitheatre Pkwy", City: "Mountain View", Province: "ca", PostalCode: "94043", Country: "US"},
+			want:    "1600 Amphitheatre Pkwy\nMOUNTAIN VIEW, CA 94043\nUNITED STATES",
+		},
+		{
+			name:    "postal code before city",
+			address: Address{Street: "Unter den Linden 1", City: "Berlin", PostalCode: "10117", Country: "de"},
+			want:    "Unter den Linden 1\n10117 Berlin\nGERMANY",
+		},
+		{
+			name:    "missing field drops its separator",
+			address: Address{Street: "1600 Amphitheatre Pkwy", City: "Mountain View", PostalCode: "94043", Country: "US"},
+			want:    "1600 Amphitheatre Pkwy\nMOUNTAIN VIEW 94043\nUNITED STATES",
+		},
+		{
+			name:    "empty line is dropped",
+			address: Address{Street: "1-2-3 Shibuya", Province: "Tokyo", Country: "JP"},
+			want:    "1-2-3 Shibuya\nTOKYO\nJAPAN",
+		},
+		{
+			name:    "country written as a name",
+			address: Address{Street: "1 Main St", City: "Springfield", Province: "Fictional", PostalCode: "12345", Country: " Narnia "},
+			want:    "1 Main St\nSpringfield\nFictional 12345\nNarnia",
+		},
+		{
+			name:    "no country",
+			address: Address{Street: "1 Main St", City: "Springfield"},
+			want:    "1 Main St\nSpringfield",
+		},
+		{
+			name: "empty",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.address); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AddressRepository struct {
@@ -28,3 +30,128 @@ func (r *AddressRepository) FindAllByContactId(tx *gorm.DB, contactId string) ([
 	}
 	return addresses, nil
 }
+
+func (r *AddressRepository) FindAllByContactIds(tx *gorm.DB, contactIds []string) ([]entity.Address, error) {
+	var addresses []entity.Address
+	if err := tx.Where("contact_id IN ?", contactIds).Order("created_at, id").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// FindTrashedByIdAndContactId finds an address that was deleted, on its own
+// or with its contact, and not yet purged.
+func (r *AddressRepository) FindTrashedByIdAndContactId(db *gorm.DB, address *entity.Address, id string, contactId string) error {
+	return db.Unscoped().Where("id = ? AND contact_id = ? AND deleted_at <> 0", id, contactId).Take(address).Error
+}
+
+// Restore brings a trashed address back, as primary or not as address says.
+func (r *AddressRepository) Restore(db *gorm.DB, address *entity.Address) error {
+	if err := db.Unscoped().Model(address).Updates(map[string]any{
+		"deleted_at": 0,
+		"is_primary": address.IsPrimary,
+		"version":    gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return err
+	}
+
+	address.DeletedAt = 0
+	address.Version++
+	return nil
+}
+
+func (r *AddressRepository) FindPrimaryByContactId(tx *gorm.DB, address *entity.Address, contactId string) error {
+	return tx.Where("contact_id = ? AND is_primary", contactId).Take(address).Error
+}
+
+// FindOldestByContactId finds the address that becomes primary when the
+// primary one goes away.
+func (r *AddressRepository) FindOldestByContactId(tx *gorm.DB, address *entity.Address, contactId string) error {
+	return tx.Where("contact_id = ?", contactId).Order("created_at, id").Take(address).Error
+}
+
+func (r *AddressRepository) FindAllPrimaryByContactIds(tx *gorm.DB, contactIds []string) ([]entity.Address, error) {
+	var addresses []entity.Address
+	if err := tx.Where("contact_id IN ? AND is_primary", contactIds).Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// DemotePrimary clears the primary flag of the given contacts' addresses,
+// except on the address keepId.
+func (r *AddressRepository) DemotePrimary(db *gorm.DB, contactIds []string, keepId string) error {
+	return db.Model(&entity.Address{}).
+		Where("contact_id IN ? AND is_primary AND id <> ?", contactIds, keepId).
+		UpdateColumns(map[string]any{"is_primary": false, "version": gorm.Expr("version + 1")}).Error
+}
+
+// Reparent moves every address of the given contacts, trashed ones included,
+// to another contact.
+func (r *AddressRepository) Reparent(db *gorm.DB, fromContactIds []string, toContactId string) error {
+	return db.Unscoped().Model(&entity.Address{}).
+		Where("contact_id IN ?", fromContactIds).
+		Update("contact_id", toContactId).Error
+}
+
+// FindAllCountries returns every distinct country stored on an address,
+// trashed ones included.
+func (r *AddressRepository) FindAllCountries(db *gorm.DB) ([]string, error) {
+	var countries []string
+	err := db.Unscoped().Model(&entity.Address{}).Distinct().Pluck("country", &countries).Error
+	return countries, err
+}
+
+// FindAllByCountryForUpdate locks every address stored with the country,
+// trashed ones included.
+func (r *AddressRepository) FindAllByCountryForUpdate(db *gorm.DB, country string) ([]entity.Address, error) {
+	var addresses []entity.Address
+	if err := db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("country = ?", country).Order("contact_id, id").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// ReplaceCountry rewrites the country of every address stored with from,
+// trashed ones included, bumping the versions of the addresses and of their
+// contacts. It returns how many addresses changed.
+func (r *AddressRepository) ReplaceCountry(db *gorm.DB, from string, to string) (int64, error) {
+	if err := db.Unscoped().Model(&entity.Contact{}).
+		Where("id IN (?)", db.Unscoped().Model(&entity.Address{}).Select("contact_id").Where("country = ?", from)).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error; err != nil {
+		return 0, err
+	}
+
+	result := db.Unscoped().Model(&entity.Address{}).Where("country = ?", from).
+		UpdateColumns(map[string]any{"country": to, "version": gorm.Expr("version + 1")})
+	return result.RowsAffected, result.Error
+}
+
+func (r *AddressRepository) PurgeTrashed(db *gorm.DB, before int64) (int64, error) {
+	result := db.Unscoped().Where("deleted_at <> 0 AND deleted_at < ?", before).Delete(&entity.Address{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *AddressRepository) Search(db *gorm.DB, request *dto.ListAddressRequest) ([]entity.Address, *Page, error) {
+	return Paginate(db, r.Sorting(), NewPageRequest(request), func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("addresses.contact_id = ?", request.ContactId)
+	})
+}
+
+func (r *AddressRepository) Sorting() Sorting[entity.Address] {
+	return Sorting[entity.Address]{
+		Columns: map[string]SortColumn[entity.Address]{
+			"street":      {Expression: "coalesce(addresses.street, '')", Value: func(a *entity.Address) any { return a.Street }},
+			"city":        {Expression: "coalesce(addresses.city, '')", Value: func(a *entity.Address) any { return a.City }},
+			"province":    {Expression: "coalesce(addresses.province, '')", Value: func(a *entity.Address) any { return a.Province }},
+			"postal_code": {Expression: "coalesce(addresses.postal_code, '')", Value: func(a *entity.Address) any { return a.PostalCode }},
+			"country":     {Expression: "coalesce(addresses.country, '')", Value: func(a *entity.Address) any { return a.Country }},
+			"created_at":  {Expression: "addresses.created_at", Value: func(a *entity.Address) any { return a.CreatedAt }},
+			"updated_at":  {Expression: "addresses.updated_at", Value: func(a *entity.Address) any { return a.UpdatedAt }},
+		},
+		Default:     "created_at",
+		TiebreakKey: "id",
+		Tiebreak:    SortColumn[entity.Address]{Expression: "addresses.id", Value: func(a *entity.Address) any { return a.ID }},
+	}
+}
@@ -1,10 +1,21 @@
 package repository
 
 import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/soft_delete"
 )
 
 type ContactRepository struct {
@@ -22,39 +33,559 @@ func (r *ContactRepository) FindByIdAndUserId(db *gorm.DB, contact *entity.Conta
 	return db.Where("id = ? AND user_id = ?", id, userId).Take(contact).Error
 }
 
-func (r *ContactRepository) Search(db *gorm.DB, request *dto.SearchContactRequest) ([]entity.Contact, int64, error) {
+// FindByIdAndAccess finds a contact the user owns or has been granted at
+// least the given permission on. Grants are read on every call, so a revoked
+// share stops working with the next request.
+func (r *ContactRepository) FindByIdAndAccess(db *gorm.DB, contact *entity.Contact, id string, userId string, permission string) error {
+	return db.Where("contacts.id = ? AND (contacts.user_id = ? OR contacts.id IN (?))",
+		id, userId, sharedContactIds(userId, permission)).Take(contact).Error
+}
+
+func (r *ContactRepository) FindByIdAndAccessForUpdate(db *gorm.DB, contact *entity.Contact, id string, userId string, permission string) error {
+	return r.FindByIdAndAccess(db.Clauses(clause.Locking{Strength: "UPDATE"}), contact, id, userId, permission)
+}
+
+// FindAllIdsByAccess keeps the ids of the contacts the user owns or has been
+// granted at least the given permission on.
+func (r *ContactRepository) FindAllIdsByAccess(db *gorm.DB, ids []string, userId string, permission string) ([]string, error) {
+	var accessible []string
+	err := db.Model(&entity.Contact{}).
+		Where("contacts.id IN ? AND (contacts.user_id = ? OR contacts.id IN (?))", ids, userId, sharedContactIds(userId, permission)).
+		Pluck("contacts.id", &accessible).Error
+	return accessible, err
+}
+
+// SearchShared lists the contacts other users shared with the user, with
+// SharePermission set to the highest permission granted on each.
+func (r *ContactRepository) SearchShared(db *gorm.DB, request *dto.SearchSharedContactRequest) ([]entity.Contact, *Page, error) {
+	return Paginate(db, r.Sorting(&dto.SearchContactRequest{}), NewPageRequest(request), func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("contacts.user_id <> ? AND contacts.id IN (?)",
+			request.UserId, sharedContactIds(request.UserId, entity.PermissionRead))
+	}, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("contacts.*, CASE WHEN contacts.id IN (?) THEN ? ELSE ? END AS share_permission",
+			sharedContactIds(request.UserId, entity.PermissionWrite), entity.PermissionWrite, entity.PermissionRead)
+	}, r.PreloadTags, r.PreloadPrimaryAddress)
+}
+
+// sharedContactIds selects the ids of contacts shared with the user at the
+// given permission or above, either directly or through a shared tag.
+func sharedContactIds(userId string, permission string) clause.Expr {
+	permissions := []string{entity.PermissionWrite}
+	if permission == entity.PermissionRead {
+		permissions = append(permissions, entity.PermissionRead)
+	}
+
+	return gorm.Expr("SELECT shares.contact_id FROM shares "+
+		"WHERE shares.grantee_id = ? AND shares.permission IN ? AND shares.contact_id IS NOT NULL "+
+		"UNION SELECT contact_tags.contact_id FROM contact_tags JOIN shares ON shares.tag_id = contact_tags.tag_id "+
+		"WHERE shares.grantee_id = ? AND shares.permission IN ?",
+		userId, permissions, userId, permissions)
+}
+
+// Search also loads the addresses of the page's contacts when they are
+// expanded, in one query for the whole page.
+func (r *ContactRepository) Search(db *gorm.DB, request *dto.SearchContactRequest) ([]entity.Contact, *Page, error) {
+	selects := []func(*gorm.DB) *gorm.DB{r.RankContact(request), r.PreloadTags, r.PreloadPrimaryAddress}
+	if slices.Contains(request.Expand, "addresses") {
+		selects = append(selects, r.PreloadAddresses)
+	}
+	return Paginate(db, r.Sorting(request), NewPageRequest(request), r.FilterContact(request), selects...)
+}
+
+func (r *ContactRepository) PreloadTags(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Tags", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("lower(tags.name), tags.id")
+	})
+}
+
+// Export walks every contact matching the filter in primary key order with
+// addresses and tags preloaded, so only one batch is held in memory at a time.
+func (r *ContactRepository) Export(db *gorm.DB, request *dto.SearchContactRequest, batchSize int, fn func([]entity.Contact) error) error {
+	var contacts []entity.Contact
+	return db.Scopes(r.FilterContact(request), r.PreloadTags, r.PreloadAddresses).
+		FindInBatches(&contacts, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(contacts)
+		}).Error
+}
+
+func (r *ContactRepository) PreloadAddresses(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Addresses", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("addresses.created_at, addresses.id")
+	})
+}
+
+func (r *ContactRepository) PreloadPrimaryAddress(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("PrimaryAddress", "addresses.is_primary")
+}
+
+func (r *ContactRepository) LoadAddresses(db *gorm.DB, contact *entity.Contact) error {
+	return db.Model(contact).Order("addresses.created_at, addresses.id").Association("Addresses").Find(&contact.Addresses)
+}
+
+func (r *ContactRepository) LoadTags(db *gorm.DB, contact *entity.Contact) error {
+	return db.Model(contact).Order("lower(tags.name), tags.id").Association("Tags").Find(&contact.Tags)
+}
+
+func (r *ContactRepository) SearchTrash(db *gorm.DB, request *dto.SearchTrashContactRequest) ([]entity.Contact, *Page, error) {
+	sorting := r.Sorting(&dto.SearchContactRequest{})
+	sorting.Columns["deleted_at"] = SortColumn[entity.Contact]{
+		Expression: "contacts.deleted_at",
+		Value:      func(c *entity.Contact) any { return int64(c.DeletedAt) },
+	}
+	sorting.Default = "-deleted_at"
+
+	return Paginate(db, sorting, NewPageRequest(request), func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped().Where("contacts.user_id = ? AND contacts.deleted_at <> 0", request.UserId)
+	}, r.PreloadTags)
+}
+
+func (r *ContactRepository) FindTrashedByIdAndUserId(db *gorm.DB, contact *entity.Contact, id string, userId string) error {
+	return db.Unscoped().Where("id = ? AND user_id = ? AND deleted_at <> 0", id, userId).Take(contact).Error
+}
+
+// Trash soft deletes the contact together with its live addresses, stamping
+// both with the same deleted_at so Restore can tell them apart from addresses
+// that were trashed on their own earlier. It fails with ErrVersionConflict
+// when the contact changed since it was read.
+func (r *ContactRepository) Trash(db *gorm.DB, contact *entity.Contact) error {
+	deletedAt := soft_delete.DeletedAt(time.Now().UnixMilli())
+
+	result := db.Model(contact).Where("version = ?", contact.Version).
+		Updates(map[string]any{"deleted_at": deletedAt, "version": contact.Version + 1})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	if err := db.Model(&entity.Address{}).Where("contact_id = ?", contact.ID).Update("deleted_at", deletedAt).Error; err != nil {
+		return err
+	}
+
+	contact.DeletedAt = deletedAt
+	contact.Version++
+	return nil
+}
+
+func (r *ContactRepository) Restore(db *gorm.DB, contact *entity.Contact) error {
+	if err := db.Unscoped().Model(&entity.Address{}).
+		Where("contact_id = ? AND deleted_at = ?", contact.ID, contact.DeletedAt).
+		Update("deleted_at", 0).Error; err != nil {
+		return err
+	}
+
+	if err := db.Unscoped().Model(contact).
+		Updates(map[string]any{"deleted_at": 0, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		return err
+	}
+
+	contact.DeletedAt = 0
+	contact.Version++
+	return nil
+}
+
+// RefreshLastInteraction recomputes last_interaction_at from the contact's
+// calls, meetings and emails. Plain notes are not interactions. The version
+// is bumped when the value changes, as it is part of the contact.
+func (r *ContactRepository) RefreshLastInteraction(db *gorm.DB, contact *entity.Contact) error {
+	var refreshed struct {
+		LastInteractionAt int64
+		Version           int64
+	}
+	if err := db.Raw("UPDATE contacts SET last_interaction_at = latest.at, "+
+		"version = contacts.version + CASE WHEN contacts.last_interaction_at IS DISTINCT FROM latest.at THEN 1 ELSE 0 END "+
+		"FROM (SELECT coalesce(max(occurred_at), 0) AS at FROM notes WHERE notes.contact_id = ? AND notes.type <> ?) AS latest "+
+		"WHERE contacts.id = ? RETURNING contacts.last_interaction_at, contacts.version",
+		contact.ID, entity.NoteTypeNote, contact.ID).Scan(&refreshed).Error; err != nil {
+		return err
+	}
+
+	contact.LastInteractionAt = refreshed.LastInteractionAt
+	contact.Version = refreshed.Version
+	return nil
+}
+
+// RemoveCustomField drops the named custom field from all of the user's
+// contacts, trashed ones included.
+func (r *ContactRepository) RemoveCustomField(db *gorm.DB, userId string, name string) error {
+	return db.Unscoped().Model(&entity.Contact{}).
+		Where("user_id = ? AND custom_fields ->> ? IS NOT NULL", userId, name).
+		UpdateColumns(map[string]any{
+			"custom_fields": gorm.Expr("custom_fields - ?", name),
+			"version":       gorm.Expr("version + 1"),
+		}).Error
+}
+
+// BumpVersion marks a change to the contact that does not touch its own
+// columns, such as its tags.
+func (r *ContactRepository) BumpVersion(db *gorm.DB, contact *entity.Contact) error {
+	if err := db.Model(contact).Update("version", gorm.Expr("version + 1")).Error; err != nil {
+		return err
+	}
+
+	contact.Version++
+	return nil
+}
+
+// FindAllByTagIdForUpdate locks every contact with the tag, trashed ones
+// included, and loads their tags.
+func (r *ContactRepository) FindAllByTagIdForUpdate(db *gorm.DB, tagId string) ([]entity.Contact, error) {
+	var contacts []entity.Contact
+	if err := db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(r.PreloadTags).
+		Where("id IN (?)", db.Table("contact_tags").Select("contact_id").Where("tag_id = ?", tagId)).
+		Order("id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// FindAllWithCustomFieldForUpdate locks every contact of the user with a
+// value for the named custom field, trashed ones included.
+func (r *ContactRepository) FindAllWithCustomFieldForUpdate(db *gorm.DB, userId string, name string) ([]entity.Contact, error) {
+	var contacts []entity.Contact
+	if err := db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND custom_fields ->> ? IS NOT NULL", userId, name).
+		Order("id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// BumpVersionByTagId bumps the version of every contact with the tag, whose
+// name is part of them.
+func (r *ContactRepository) BumpVersionByTagId(db *gorm.DB, tagId string) error {
+	return db.Unscoped().Model(&entity.Contact{}).
+		Where("id IN (?)", db.Table("contact_tags").Select("contact_id").Where("tag_id = ?", tagId)).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
+
+// FindAllWithDates returns the user's live contacts that have any dates.
+func (r *ContactRepository) FindAllWithDates(db *gorm.DB, userId string) ([]entity.Contact, error) {
+	var contacts []entity.Contact
+	if err := db.Where("contacts.user_id = ? AND contacts.dates <> '[]'::jsonb", userId).
+		Order("contacts.id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// FindAllWithDatesBetween returns the live contacts with a date falling
+// between the days from and to, both included, for one user or for every
+// user when userId is empty. Dates match on month and day only, leaving the
+// exact occurrence to the caller; Feb 29 matches whenever Feb 28 does, since
+// it falls on Feb 28 in common years.
+func (r *ContactRepository) FindAllWithDatesBetween(db *gorm.DB, userId string, from time.Time, to time.Time) ([]entity.Contact, error) {
+	tx := db.Where("contacts.dates <> '[]'::jsonb")
+	if userId != "" {
+		tx = tx.Where("contacts.user_id = ?", userId)
+	}
+
+	if to.Sub(from) < 365*24*time.Hour {
+		start := int(from.Month())*100 + from.Day()
+		end := int(to.Month())*100 + to.Day()
+		if end == 228 {
+			end = 229
+		}
+
+		condition := "d.month * 100 + d.day BETWEEN ? AND ?"
+		if end < start {
+			condition = "(d.month * 100 + d.day >= ? OR d.month * 100 + d.day <= ?)"
+		}
+		tx = tx.Where("EXISTS (SELECT 1 FROM jsonb_to_recordset(contacts.dates) AS d(month int, day int) WHERE "+condition+")",
+			start, end)
+	}
+
+	var contacts []entity.Contact
+	if err := tx.Order("contacts.id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// DuplicatePair is one pair of a user's contacts that share a normalized
+// email or phone, or whose full names are similar enough.
+type DuplicatePair struct {
+	ContactId      string  `gorm:"column:contact_id"`
+	OtherId        string  `gorm:"column:other_id"`
+	EmailMatch     bool    `gorm:"column:email_match"`
+	PhoneMatch     bool    `gorm:"column:phone_match"`
+	NameSimilarity float64 `gorm:"column:name_similarity"`
+}
+
+const (
+	duplicateEmailExpression = "lower(trim(coalesce(%s.email, '')))"
+	duplicatePhoneExpression = "regexp_replace(coalesce(nullif(%[1]s.phone_e164, ''), %[1]s.phone, ''), '[^0-9]', '', 'g')"
+	duplicateNameExpression  = "lower(trim(coalesce(%[1]s.first_name, '') || ' ' || coalesce(%[1]s.last_name, '')))"
+)
+
+// FindDuplicatePairs finds at most limit pairs. Rather than comparing every
+// contact with every other, it only pairs contacts that share an email or a
+// phone, or whose names the trigram index finds similar enough, so each
+// contact costs an index lookup.
+func (r *ContactRepository) FindDuplicatePairs(db *gorm.DB, userId string, minNameSimilarity float64, limit int) ([]DuplicatePair, error) {
+	email := func(table string) string { return fmt.Sprintf(duplicateEmailExpression, table) }
+	phone := func(table string) string { return fmt.Sprintf(duplicatePhoneExpression, table) }
+	name := func(table string) string { return fmt.Sprintf(duplicateNameExpression, table) }
+
+	emailMatch := fmt.Sprintf("(%s <> '' AND %s = %s)", email("a"), email("a"), email("b"))
+	// Short numbers such as extensions are too likely to collide by accident.
+	phoneMatch := fmt.Sprintf("(length(%s) >= 7 AND %s = %s)", phone("a"), phone("a"), phone("b"))
+	nameSimilarity := fmt.Sprintf("similarity(%s, %s)", name("a"), name("b"))
+
+	// % compares names against pg_trgm.similarity_threshold, for the rest of
+	// the transaction.
+	if err := db.Exec("SELECT set_config('pg_trgm.similarity_threshold', ?, true)", strconv.FormatFloat(minNameSimilarity, 'f', -1, 64)).Error; err != nil {
+		return nil, err
+	}
+
+	candidates := func(match string) string {
+		return "SELECT a.id AS contact_id, b.id AS other_id FROM contacts AS a " +
+			"JOIN contacts AS b ON b.user_id = a.user_id AND b.id > a.id AND b.deleted_at = 0 AND " + match + " " +
+			"WHERE a.user_id = @user AND a.deleted_at = 0"
+	}
+
+	var pairs []DuplicatePair
+	err := db.Raw(fmt.Sprintf("WITH candidates AS (%s UNION %s UNION %s) "+
+		"SELECT a.id AS contact_id, b.id AS other_id, %s AS email_match, %s AS phone_match, %s AS name_similarity "+
+		"FROM candidates JOIN contacts AS a ON a.id = candidates.contact_id JOIN contacts AS b ON b.id = candidates.other_id "+
+		"ORDER BY a.id, b.id LIMIT @limit",
+		candidates(emailMatch), candidates(phoneMatch), candidates(fmt.Sprintf("%s %% %s", name("a"), name("b"))),
+		emailMatch, phoneMatch, nameSimilarity),
+		sql.Named("user", userId), sql.Named("limit", limit)).
+		Scan(&pairs).Error
+	return pairs, err
+}
+
+func (r *ContactRepository) FindAllByIdsAndUserId(db *gorm.DB, ids []string, userId string) ([]entity.Contact, error) {
 	var contacts []entity.Contact
-	if err := db.Scopes(r.FilterContact(request)).Offset((request.Page - 1) * request.Size).Limit(request.Size).Find(&contacts).Error; err != nil {
-		return nil, 0, err
+	if err := db.Where("id IN ? AND user_id = ?", ids, userId).Order("id").Find(&contacts).Error; err != nil {
+		return nil, err
 	}
+	return contacts, nil
+}
+
+func (r *ContactRepository) FindAllByIdsAndUserIdForUpdate(db *gorm.DB, ids []string, userId string) ([]entity.Contact, error) {
+	return r.FindAllByIdsAndUserId(db.Clauses(clause.Locking{Strength: "UPDATE"}), ids, userId)
+}
 
-	var total int64 = 0
-	if err := db.Model(&entity.Contact{}).Scopes(r.FilterContact(request)).Count(&total).Error; err != nil {
-		return nil, 0, err
+// MergeTags copies the source contacts' tags onto the target, skipping the
+// ones it already has.
+func (r *ContactRepository) MergeTags(db *gorm.DB, targetId string, sourceIds []string) error {
+	return db.Exec(`INSERT INTO contact_tags (contact_id, tag_id)
+		SELECT DISTINCT ?, tag_id FROM contact_tags WHERE contact_id IN ?
+		ON CONFLICT DO NOTHING`, targetId, sourceIds).Error
+}
+
+// DeletePermanently bypasses the trash; addresses still attached to the
+// contacts follow through ON DELETE CASCADE.
+func (r *ContactRepository) DeletePermanently(db *gorm.DB, ids []string) error {
+	return db.Unscoped().Where("id IN ?", ids).Delete(&entity.Contact{}).Error
+}
+
+// PurgeTrashed hard deletes contacts trashed before the given unix milli
+// timestamp and returns the id and photo of each, as the delete saw them;
+// their addresses follow through ON DELETE CASCADE.
+func (r *ContactRepository) PurgeTrashed(db *gorm.DB, before int64) ([]entity.Contact, error) {
+	var contacts []entity.Contact
+	err := db.Raw("DELETE FROM contacts WHERE deleted_at <> 0 AND deleted_at < ? RETURNING id, photo_id", before).
+		Scan(&contacts).Error
+	return contacts, err
+}
+
+func (r *ContactRepository) Sorting(request *dto.SearchContactRequest) Sorting[entity.Contact] {
+	sorting := Sorting[entity.Contact]{
+		Columns: map[string]SortColumn[entity.Contact]{
+			"first_name":          {Expression: "coalesce(contacts.first_name, '')", Value: func(c *entity.Contact) any { return c.FirstName }},
+			"last_name":           {Expression: "coalesce(contacts.last_name, '')", Value: func(c *entity.Contact) any { return c.LastName }},
+			"email":               {Expression: "coalesce(contacts.email, '')", Value: func(c *entity.Contact) any { return c.Email }},
+			"phone":               {Expression: "coalesce(contacts.phone, '')", Value: func(c *entity.Contact) any { return c.Phone }},
+			"created_at":          {Expression: "contacts.created_at", Value: func(c *entity.Contact) any { return c.CreatedAt }},
+			"updated_at":          {Expression: "contacts.updated_at", Value: func(c *entity.Contact) any { return c.UpdatedAt }},
+			"last_interaction_at": {Expression: "contacts.last_interaction_at", Value: func(c *entity.Contact) any { return c.LastInteractionAt }},
+		},
+		Default:     "created_at",
+		TiebreakKey: "id",
+		Tiebreak:    SortColumn[entity.Contact]{Expression: "contacts.id", Value: func(c *entity.Contact) any { return c.ID }},
+	}
+
+	if q := strings.TrimSpace(request.Query); q != "" {
+		sorting.Columns["relevance"] = SortColumn[entity.Contact]{
+			Expression: contactRankExpression,
+			Args:       []any{toPrefixTsQuery(q), q, q, q},
+			Value:      func(c *entity.Contact) any { return c.Rank },
+		}
+		sorting.Default = "-relevance"
+	}
+
+	if near := request.Near; near != nil {
+		sorting.Columns["distance"] = SortColumn[entity.Contact]{
+			Expression: contactDistanceExpression,
+			Args:       distanceArgs(near),
+			Value:      func(c *entity.Contact) any { return *c.Distance },
+		}
+		if q := strings.TrimSpace(request.Query); q == "" {
+			sorting.Default = "distance"
+		}
 	}
 
-	return contacts, total, nil
+	return sorting
 }
 
 func (r *ContactRepository) FilterContact(request *dto.SearchContactRequest) func(tx *gorm.DB) *gorm.DB {
 	return func(tx *gorm.DB) *gorm.DB {
-		tx = tx.Where("user_id = ?", request.UserId)
+		tx = tx.Where("contacts.user_id = ?", request.UserId)
+
+		if q := strings.TrimSpace(request.Query); q != "" {
+			if tsQuery := toPrefixTsQuery(q); tsQuery != "" {
+				tx = tx.Where("contacts.search_vector @@ to_tsquery('simple', ?) OR ? <% contacts.first_name OR ? <% contacts.last_name OR ? <% contacts.email "+
+					"OR contacts.id IN (SELECT notes.contact_id FROM notes WHERE notes.search_vector @@ to_tsquery('simple', ?))",
+					tsQuery, q, q, q, tsQuery)
+			} else {
+				tx = tx.Where("? <% contacts.first_name OR ? <% contacts.last_name OR ? <% contacts.email", q, q, q)
+			}
+		}
+
+		if tags := normalizeTagNames(request.Tags); len(tags) > 0 {
+			// Tags are matched through the caller's own tags only, so another
+			// user's identically named tag can never widen the result.
+			tagged := tx.Session(&gorm.Session{NewDB: true}).
+				Table("contact_tags").
+				Select("contact_tags.contact_id").
+				Joins("JOIN tags ON tags.id = contact_tags.tag_id").
+				Where("tags.user_id = ? AND lower(tags.name) IN ?", request.UserId, tags)
+
+			if request.TagMode == "all" {
+				tagged = tagged.Group("contact_tags.contact_id").Having("COUNT(DISTINCT tags.id) = ?", len(tags))
+			}
+
+			tx = tx.Where("contacts.id IN (?)", tagged)
+		}
 
 		if name := request.Name; name != "" {
-			name = "%" + name + "%"
-			tx = tx.Where("first_name LIKE ? OR last_name LIKE ?", name, name)
+			name = "%" + escapeLike(name) + "%"
+			tx = tx.Where("contacts.first_name ILIKE ? OR contacts.last_name ILIKE ?", name, name)
 		}
 
 		if phone := request.Phone; phone != "" {
-			phone = "%" + phone + "%"
-			tx = tx.Where("phone LIKE ?", phone)
+			// The raw input still matches rows saved before phone_e164 existed.
+			conditions := "contacts.phone ILIKE ?"
+			args := []any{"%" + escapeLike(phone) + "%"}
+
+			if digits := onlyDigits(phone); digits != "" {
+				conditions += " OR contacts.phone_e164 LIKE ?"
+				args = append(args, "%"+digits+"%")
+			}
+			if request.PhonePrefix != "" {
+				conditions += " OR contacts.phone_e164 LIKE ?"
+				args = append(args, escapeLike(request.PhonePrefix)+"%")
+			}
+
+			tx = tx.Where(conditions, args...)
 		}
 
 		if email := request.Email; email != "" {
-			email = "%" + email + "%"
-			tx = tx.Where("email LIKE ?", email)
+			email = "%" + escapeLike(email) + "%"
+			tx = tx.Where("contacts.email ILIKE ?", email)
+		}
+
+		if near := request.Near; near != nil {
+			nearby := nearbyAddresses(tx.Session(&gorm.Session{NewDB: true}).Table("addresses").Select("addresses.contact_id"),
+				near, request.RadiusKm)
+			tx = tx.Where("contacts.id IN (?)", nearby)
+		}
+
+		for _, filter := range request.CustomFieldFilters {
+			if value, ok := filter.Value.(string); ok && filter.Partial {
+				tx = tx.Where("contacts.custom_fields ->> ? ILIKE ?", filter.Name, "%"+escapeLike(value)+"%")
+				continue
+			}
+			// Containment can use the jsonb_path_ops index.
+			containment, _ := json.Marshal(map[string]any{filter.Name: filter.Value})
+			tx = tx.Where("contacts.custom_fields @> ?::jsonb", string(containment))
 		}
 
 		return tx
 	}
 }
+
+const contactRankExpression = "(ts_rank(contacts.search_vector, to_tsquery('simple', ?)) + " +
+	"coalesce(greatest(word_similarity(?, contacts.first_name), word_similarity(?, contacts.last_name), word_similarity(?, contacts.email)), 0))"
+
+// RankContact selects relevance (and optionally a highlight snippet) for a
+// full-text query, and the distance for a proximity search. It is kept apart
+// from FilterContact so that count queries stay free of these expressions.
+func (r *ContactRepository) RankContact(request *dto.SearchContactRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		columns := []string{"contacts.*"}
+		var args []any
+
+		if q := strings.TrimSpace(request.Query); q != "" {
+			tsQuery := toPrefixTsQuery(q)
+			columns = append(columns, contactRankExpression+" AS search_rank")
+			args = append(args, tsQuery, q, q, q)
+
+			if request.Highlight {
+				// The fields are plain text, so matches are marked with the
+				// entity's characters rather than HTML, after taking any of
+				// those out of the fields themselves.
+				columns = append(columns, "ts_headline('simple', translate(concat_ws(' ', contacts.first_name, contacts.last_name, contacts.email, contacts.phone), ?, ''), "+
+					"to_tsquery('simple', ?), ?) AS search_highlight")
+				args = append(args, entity.HighlightStart+entity.HighlightStop, tsQuery,
+					`StartSel="`+entity.HighlightStart+`", StopSel="`+entity.HighlightStop+`", MaxFragments=1, MinWords=3, MaxWords=12`)
+			}
+		}
+
+		if near := request.Near; near != nil {
+			columns = append(columns, contactDistanceExpression+" AS search_distance")
+			args = append(args, distanceArgs(near)...)
+		}
+
+		if len(columns) == 1 {
+			return tx
+		}
+		return tx.Select(strings.Join(columns, ", "), args...)
+	}
+}
+
+// toPrefixTsQuery turns free text into a tsquery where every term must match
+// as a prefix, so "jo smi" finds "John Smith". Only letters and digits are
+// kept, which also keeps tsquery syntax out of user input.
+func toPrefixTsQuery(q string) string {
+	terms := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+func normalizeTagNames(names []string) []string {
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func onlyDigits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
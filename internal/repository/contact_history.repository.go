@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ContactHistoryRepository struct {
+	Repository[entity.ContactHistory]
+	Log *zap.Logger
+}
+
+func NewContactHistoryRepository(log *zap.Logger) *ContactHistoryRepository {
+	return &ContactHistoryRepository{
+		Log: log,
+	}
+}
+
+func (r *ContactHistoryRepository) Search(db *gorm.DB, request *dto.ListContactHistoryRequest) ([]entity.ContactHistory, *Page, error) {
+	return Paginate(db, r.Sorting(), NewPageRequest(request), func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("contact_history.contact_id = ?", request.ContactId)
+	})
+}
+
+func (r *ContactHistoryRepository) Sorting() Sorting[entity.ContactHistory] {
+	return Sorting[entity.ContactHistory]{
+		Columns: map[string]SortColumn[entity.ContactHistory]{
+			"created_at": {Expression: "contact_history.created_at", Value: func(h *entity.ContactHistory) any { return h.CreatedAt }},
+		},
+		Default:     "-created_at",
+		TiebreakKey: "id",
+		Tiebreak:    SortColumn[entity.ContactHistory]{Expression: "contact_history.id", Value: func(h *entity.ContactHistory) any { return h.ID }},
+	}
+}
+
+// FindContactVersion finds the entry that left the contact at version.
+func (r *ContactHistoryRepository) FindContactVersion(db *gorm.DB, history *entity.ContactHistory, contactId string, version int64) error {
+	return db.Where("contact_id = ? AND entity_type = ? AND entity_id = ? AND version = ?",
+		contactId, entity.HistoryContact, contactId, version).
+		Order("created_at DESC").Take(history).Error
+}
+
+// ReparentAddresses moves the history of addresses that moved to another
+// contact along with them, so it survives the old contact being deleted.
+func (r *ContactHistoryRepository) ReparentAddresses(db *gorm.DB, fromContactIds []string, toContactId string) error {
+	return db.Model(&entity.ContactHistory{}).
+		Where("contact_id IN ? AND entity_type = ?", fromContactIds, entity.HistoryAddress).
+		Update("contact_id", toContactId).Error
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindAllWithDatesBetween(t *testing.T) {
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	const dates = "(EXISTS (SELECT 1 FROM jsonb_to_recordset(contacts.dates) AS d(month int, day int) WHERE "
+
+	tests := []struct {
+		name     string
+		userId   string
+		from, to time.Time
+		want     string
+	}{
+		{
+			name: "within a month",
+			from: day(2025, time.June, 1), to: day(2025, time.June, 30),
+			want: dates + "d.month * 100 + d.day BETWEEN 601 AND 630))",
+		},
+		{
+			name: "across the new year",
+			from: day(2025, time.December, 20), to: day(2026, time.January, 10),
+			want: dates + "(d.month * 100 + d.day >= 1220 OR d.month * 100 + d.day <= 110)))",
+		},
+		{
+			name: "ending on Feb 28 of a common year takes in Feb 29",
+			from: day(2025, time.February, 1), to: day(2025, time.February, 28),
+			want: dates + "d.month * 100 + d.day BETWEEN 201 AND 229))",
+		},
+		{
+			name: "ending on Feb 28 across the new year takes in Feb 29",
+			from: day(2025, time.December, 15), to: day(2026, time.February, 28),
+			want: dates + "(d.month * 100 + d.day >= 1215 OR d.month * 100 + d.day <= 229)))",
+		},
+		{
+			name: "ending on Feb 29 of a leap year",
+			from: day(2028, time.February, 1), to: day(2028, time.February, 29),
+			want: dates + "d.month * 100 + d.day BETWEEN 201 AND 229))",
+		},
+		{
+			name: "starting on Mar 1 leaves Feb 29 out",
+			from: day(2025, time.March, 1), to: day(2025, time.March, 31),
+			want: dates + "d.month * 100 + d.day BETWEEN 301 AND 331))",
+		},
+		{
+			name: "a whole year matches every date",
+			from: day(2025, time.March, 1), to: day(2026, time.March, 1),
+			want: "",
+		},
+		{
+			name:   "for one user",
+			userId: "ada",
+			from:   day(2025, time.June, 1), to: day(2025, time.June, 1),
+			want: "contacts.user_id = 'ada' AND " + dates + "d.month * 100 + d.day BETWEEN 601 AND 601))",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := dryRun(t)
+			if _, err := new(ContactRepository).FindAllWithDatesBetween(db, tt.userId, tt.from, tt.to); err != nil {
+				t.Fatalf("FindAllWithDatesBetween() error = %v", err)
+			}
+
+			want := `SELECT * FROM "contacts" WHERE contacts.dates <> '[]'::jsonb `
+			if tt.want != "" {
+				want += "AND " + tt.want + " "
+			}
+			want += `AND "contacts"."deleted_at" = 0 ORDER BY contacts.id`
+			if got := strings.Join(*statements, "; "); got != want {
+				t.Errorf("FindAllWithDatesBetween() ran\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestFindAllByTagIdForUpdate(t *testing.T) {
+	db, statements := dryRun(t)
+	if _, err := NewContactRepository(nil).FindAllByTagIdForUpdate(db, "t1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Trashed contacts carry the tag too, so they are locked and recorded.
+	// The first statement is the subquery, built on its own.
+	want := `SELECT * FROM "contacts" WHERE id IN (SELECT contact_id FROM "contact_tags" WHERE tag_id = 't1') ` +
+		`ORDER BY id FOR UPDATE`
+	if got := (*statements)[len(*statements)-1]; got != want {
+		t.Errorf("FindAllByTagIdForUpdate() ran %q, want %q", got, want)
+	}
+}
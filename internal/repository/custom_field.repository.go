@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CustomFieldRepository struct {
+	Repository[entity.CustomField]
+	Log *zap.Logger
+}
+
+func NewCustomFieldRepository(log *zap.Logger) *CustomFieldRepository {
+	return &CustomFieldRepository{
+		Log: log,
+	}
+}
+
+func (r *CustomFieldRepository) FindByIdAndUserId(db *gorm.DB, field *entity.CustomField, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(field).Error
+}
+
+func (r *CustomFieldRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.CustomField, error) {
+	var fields []entity.CustomField
+	if err := db.Where("user_id = ?", userId).Order("name").Find(&fields).Error; err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (r *CustomFieldRepository) CountByNameAndUserId(db *gorm.DB, name string, userId string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.CustomField)).Where("user_id = ? AND name = ?", userId, name).Count(&total).Error
+	return total, err
+}
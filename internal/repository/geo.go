@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"math"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"gorm.io/gorm"
+)
+
+const earthRadiusKm = 6371.0088
+
+// addressDistanceExpression is the great-circle distance in kilometres from
+// an address to the point bound to its placeholders as latitude, latitude,
+// longitude, by the haversine formula. least keeps rounding from pushing asin
+// out of its domain.
+const addressDistanceExpression = "2 * 6371.0088 * asin(least(1, sqrt(" +
+	"power(sin(radians(addresses.latitude - ?) / 2), 2) + " +
+	"cos(radians(?)) * cos(radians(addresses.latitude)) * power(sin(radians(addresses.longitude - ?) / 2), 2))))"
+
+// contactDistanceExpression is the distance to the contact's nearest address.
+const contactDistanceExpression = "(SELECT min(" + addressDistanceExpression + ") FROM addresses " +
+	"WHERE addresses.contact_id = contacts.id AND addresses.deleted_at = 0 AND addresses.latitude IS NOT NULL)"
+
+func distanceArgs(point *dto.GeoPoint) []any {
+	return []any{point.Latitude, point.Latitude, point.Longitude}
+}
+
+// nearbyAddresses narrows addresses to those within radiusKm of the point.
+// The bounding box lets the coordinates index do most of the work; the
+// haversine distance then trims its corners.
+func nearbyAddresses(tx *gorm.DB, point *dto.GeoPoint, radiusKm float64) *gorm.DB {
+	angle := radiusKm / earthRadiusKm
+	latitude := point.Latitude * math.Pi / 180
+
+	minLatitude := point.Latitude - angle*180/math.Pi
+	maxLatitude := point.Latitude + angle*180/math.Pi
+	tx = tx.Where("addresses.deleted_at = 0 AND addresses.latitude BETWEEN ? AND ?", minLatitude, maxLatitude)
+
+	// The longitude bounds follow J. P. Matuschek's "Finding Points Within a
+	// Distance of a Latitude/Longitude Using Bounding Coordinates". A circle
+	// that takes in a pole spans every longitude.
+	if ratio := math.Sin(angle) / math.Cos(latitude); minLatitude > -90 && maxLatitude < 90 && ratio < 1 {
+		delta := math.Asin(ratio) * 180 / math.Pi
+		minLongitude, maxLongitude := point.Longitude-delta, point.Longitude+delta
+		switch {
+		case minLongitude < -180:
+			tx = tx.Where("addresses.longitude >= ? OR addresses.longitude <= ?", minLongitude+360, maxLongitude)
+		case maxLongitude > 180:
+			tx = tx.Where("addresses.longitude >= ? OR addresses.longitude <= ?", minLongitude, maxLongitude-360)
+		default:
+			tx = tx.Where("addresses.longitude BETWEEN ? AND ?", minLongitude, maxLongitude)
+		}
+	}
+
+	return tx.Where(addressDistanceExpression+" <= ?", append(distanceArgs(point), radiusKm)...)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository struct {
+	Repository[entity.ImportJob]
+	Log *zap.Logger
+}
+
+func NewImportJobRepository(log *zap.Logger) *ImportJobRepository {
+	return &ImportJobRepository{
+		Log: log,
+	}
+}
+
+func (r *ImportJobRepository) FindByIdAndUserId(db *gorm.DB, job *entity.ImportJob, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(job).Error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type NoteRepository struct {
+	Repository[entity.Note]
+	Log *zap.Logger
+}
+
+func NewNoteRepository(log *zap.Logger) *NoteRepository {
+	return &NoteRepository{
+		Log: log,
+	}
+}
+
+func (r *NoteRepository) FindByIdAndContactId(db *gorm.DB, note *entity.Note, id string, contactId string) error {
+	return db.Where("id = ? AND contact_id = ?", id, contactId).Take(note).Error
+}
+
+func (r *NoteRepository) Search(db *gorm.DB, request *dto.ListNoteRequest) ([]entity.Note, *Page, error) {
+	return Paginate(db, r.Sorting(), NewPageRequest(request), func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("notes.contact_id = ?", request.ContactId)
+		if request.Type != "" {
+			tx = tx.Where("notes.type = ?", request.Type)
+		}
+		return tx
+	})
+}
+
+func (r *NoteRepository) Sorting() Sorting[entity.Note] {
+	return Sorting[entity.Note]{
+		Columns: map[string]SortColumn[entity.Note]{
+			"occurred_at": {Expression: "notes.occurred_at", Value: func(n *entity.Note) any { return n.OccurredAt }},
+			"created_at":  {Expression: "notes.created_at", Value: func(n *entity.Note) any { return n.CreatedAt }},
+			"updated_at":  {Expression: "notes.updated_at", Value: func(n *entity.Note) any { return n.UpdatedAt }},
+		},
+		Default:     "-occurred_at",
+		TiebreakKey: "id",
+		Tiebreak:    SortColumn[entity.Note]{Expression: "notes.id", Value: func(n *entity.Note) any { return n.ID }},
+	}
+}
+
+func (r *NoteRepository) Reparent(db *gorm.DB, fromContactIds []string, toContactId string) error {
+	return db.Model(&entity.Note{}).
+		Where("contact_id IN ?", fromContactIds).
+		Update("contact_id", toContactId).Error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OIDCStateRepository struct {
+	Repository[entity.OIDCState]
+	Log *zap.Logger
+}
+
+func NewOIDCStateRepository(log *zap.Logger) *OIDCStateRepository {
+	return &OIDCStateRepository{
+		Log: log,
+	}
+}
+
+func (r *OIDCStateRepository) FindByStateForUpdate(db *gorm.DB, state *entity.OIDCState, value string) error {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("state = ?", value).Take(state).Error
+}
+
+func (r *OIDCStateRepository) DeleteExpired(db *gorm.DB, now int64) error {
+	return db.Where("expires_at < ?", now).Delete(&entity.OIDCState{}).Error
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidPageRequest = errors.New("invalid page request")
+
+type SortColumn[T any] struct {
+	Expression string
+	Args       []any
+	Value      func(*T) any
+}
+
+// Sorting describes which fields a list endpoint may be sorted by. The
+// tiebreaker is always appended so that keyset cursors stay unambiguous.
+type Sorting[T any] struct {
+	Columns     map[string]SortColumn[T]
+	Default     string
+	TiebreakKey string
+	Tiebreak    SortColumn[T]
+}
+
+type PageRequest struct {
+	Sort         string
+	After        string
+	Before       string
+	Page         int
+	Size         int
+	IncludeTotal bool
+}
+
+// NewPageRequest reads the paging members every list request declares: Sort,
+// After, Before, Page, Size and IncludeTotal.
+func NewPageRequest(request any) PageRequest {
+	value := reflect.Indirect(reflect.ValueOf(request))
+	return PageRequest{
+		Sort:         value.FieldByName("Sort").String(),
+		After:        value.FieldByName("After").String(),
+		Before:       value.FieldByName("Before").String(),
+		Page:         int(value.FieldByName("Page").Int()),
+		Size:         int(value.FieldByName("Size").Int()),
+		IncludeTotal: value.FieldByName("IncludeTotal").Bool(),
+	}
+}
+
+type Page struct {
+	Total *int64
+	Next  string
+	Prev  string
+}
+
+type sortKey[T any] struct {
+	name   string
+	column SortColumn[T]
+	desc   bool
+}
+
+type cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+// Paginate lists T using offset or keyset paging. The filter scope is shared
+// with the optional count query; selects only apply to the page itself.
+func Paginate[T any](db *gorm.DB, sorting Sorting[T], request PageRequest, filter func(*gorm.DB) *gorm.DB,
+	selects ...func(*gorm.DB) *gorm.DB) ([]T, *Page, error) {
+	keys, signature, err := parseSort(sorting, request.Sort)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if request.After != "" && request.Before != "" {
+		return nil, nil, fmt.Errorf("%w: after and before are mutually exclusive", ErrInvalidPageRequest)
+	}
+
+	backward := request.Before != ""
+	query := db.Model(new(T)).Scopes(filter).Scopes(selects...)
+
+	if raw := request.After + request.Before; raw != "" {
+		values, err := decodeCursor(raw, signature, len(keys))
+		if err != nil {
+			return nil, nil, err
+		}
+		query = query.Where(keysetCondition(keys, values, backward))
+	} else if request.Page > 1 {
+		query = query.Offset((request.Page - 1) * request.Size)
+	}
+
+	query = query.Order(orderByKeys(keys, backward))
+
+	var items []T
+	if err := query.Limit(request.Size + 1).Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := len(items) > request.Size
+	if hasMore {
+		items = items[:request.Size]
+	}
+
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	page := new(Page)
+	if len(items) > 0 {
+		first := encodeCursor(keys, signature, &items[0])
+		last := encodeCursor(keys, signature, &items[len(items)-1])
+
+		switch {
+		case backward:
+			page.Next = last
+			if hasMore {
+				page.Prev = first
+			}
+		case request.After != "":
+			page.Prev = first
+			if hasMore {
+				page.Next = last
+			}
+		default:
+			if request.Page > 1 {
+				page.Prev = first
+			}
+			if hasMore {
+				page.Next = last
+			}
+		}
+	}
+
+	if request.IncludeTotal {
+		var total int64
+		if err := db.Model(new(T)).Scopes(filter).Count(&total).Error; err != nil {
+			return nil, nil, err
+		}
+		page.Total = &total
+	}
+
+	return items, page, nil
+}
+
+func parseSort[T any](sorting Sorting[T], sort string) ([]sortKey[T], string, error) {
+	if strings.TrimSpace(sort) == "" {
+		sort = sorting.Default
+	}
+
+	var keys []sortKey[T]
+	var names []string
+	seen := map[string]bool{}
+
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(field, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(field, "-"), "+")
+
+		column, ok := sorting.Columns[name]
+		if !ok && name != sorting.TiebreakKey {
+			return nil, "", fmt.Errorf("%w: cannot sort by %q", ErrInvalidPageRequest, name)
+		}
+		if name == sorting.TiebreakKey {
+			column = sorting.Tiebreak
+		}
+
+		if seen[name] {
+			return nil, "", fmt.Errorf("%w: duplicate sort field %q", ErrInvalidPageRequest, name)
+		}
+		seen[name] = true
+
+		keys = append(keys, sortKey[T]{name: name, column: column, desc: desc})
+		names = append(names, field)
+	}
+
+	if !seen[sorting.TiebreakKey] {
+		keys = append(keys, sortKey[T]{name: sorting.TiebreakKey, column: sorting.Tiebreak})
+		names = append(names, sorting.TiebreakKey)
+	}
+
+	return keys, strings.Join(names, ","), nil
+}
+
+func keysetCondition[T any](keys []sortKey[T], values []any, backward bool) clause.Expr {
+	var sql strings.Builder
+	var vars []any
+
+	for i := range keys {
+		if i > 0 {
+			sql.WriteString(" OR ")
+		}
+		sql.WriteString("(")
+		for j := 0; j < i; j++ {
+			sql.WriteString(keys[j].column.Expression + " = ? AND ")
+			vars = append(vars, keys[j].column.Args...)
+			vars = append(vars, values[j])
+		}
+
+		operator := " > ?"
+		if keys[i].desc != backward {
+			operator = " < ?"
+		}
+		sql.WriteString(keys[i].column.Expression + operator + ")")
+		vars = append(vars, keys[i].column.Args...)
+		vars = append(vars, values[i])
+	}
+
+	return clause.Expr{SQL: sql.String(), Vars: vars}
+}
+
+// orderByKeys builds the whole ORDER BY as one expression, since gorm replaces
+// rather than merges successive expression-based OrderBy clauses.
+func orderByKeys[T any](keys []sortKey[T], backward bool) clause.OrderBy {
+	columns := make([]string, len(keys))
+	var vars []any
+	for i, key := range keys {
+		columns[i] = key.column.Expression + direction(key.desc != backward)
+		vars = append(vars, key.column.Args...)
+	}
+
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(columns, ", "), Vars: vars, WithoutParentheses: true}}
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func encodeCursor[T any](keys []sortKey[T], signature string, item *T) string {
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		values[i] = key.column.Value(item)
+	}
+
+	payload, _ := json.Marshal(cursor{Sort: signature, Values: values})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func decodeCursor(raw string, signature string, size int) ([]any, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPageRequest)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	decoded := new(cursor)
+	if err := decoder.Decode(decoded); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPageRequest)
+	}
+
+	if decoded.Sort != signature || len(decoded.Values) != size {
+		return nil, fmt.Errorf("%w: cursor does not match sort order", ErrInvalidPageRequest)
+	}
+
+	for i, value := range decoded.Values {
+		number, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		if integer, err := number.Int64(); err == nil {
+			decoded.Values[i] = integer
+		} else if float, err := number.Float64(); err == nil {
+			decoded.Values[i] = float
+		}
+	}
+
+	return decoded.Values, nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type pageItem struct {
+	ID   string
+	Name string
+	Age  int
+}
+
+var pageItemSorting = Sorting[pageItem]{
+	Columns: map[string]SortColumn[pageItem]{
+		"name": {Expression: "lower(name)", Value: func(i *pageItem) any { return strings.ToLower(i.Name) }},
+		"age":  {Expression: "age", Value: func(i *pageItem) any { return i.Age }},
+	},
+	Default:     "name",
+	TiebreakKey: "id",
+	Tiebreak:    SortColumn[pageItem]{Expression: "id", Value: func(i *pageItem) any { return i.ID }},
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		sort      string
+		signature string
+		wantErr   bool
+	}{
+		{sort: "", signature: "name,id"},
+		{sort: "-name", signature: "-name,id"},
+		{sort: "age, -name", signature: "age,-name,id"},
+		{sort: "+age", signature: "+age,id"},
+		{sort: "-id,name", signature: "-id,name"},
+		{sort: "email", wantErr: true},
+		{sort: "name;drop table contacts", wantErr: true},
+		{sort: "name,-name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			_, signature, err := parseSort(pageItemSorting, tt.sort)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPageRequest) {
+					t.Fatalf("parseSort() error = %v, want %v", err, ErrInvalidPageRequest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSort() error = %v", err)
+			}
+			if signature != tt.signature {
+				t.Errorf("parseSort() signature = %q, want %q", signature, tt.signature)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	keys, signature, err := parseSort(pageItemSorting, "-age,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := encodeCursor(keys, signature, &pageItem{ID: "c1", Name: "Ada", Age: 36})
+	values, err := decodeCursor(raw, signature, len(keys))
+	if err != nil {
+		t.Fatalf("decodeCursor() error = %v", err)
+	}
+	if want := []any{int64(36), "ada", "c1"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("decodeCursor() = %#v, want %#v", values, want)
+	}
+}
+
+func TestDecodeCursorTampered(t *testing.T) {
+	keys, signature, err := parseSort(pageItemSorting, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := encodeCursor(keys, signature, &pageItem{ID: "c1", Name: "Ada"})
+	encode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload))
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "not base64", raw: "!!" + valid},
+		{name: "padded base64", raw: base64.URLEncoding.EncodeToString([]byte(`{"s":"name,id","v":["ada","c1"]}`)) + "="},
+		{name: "not JSON", raw: encode("name,id")},
+		{name: "other sort order", raw: encode(`{"s":"-name,id","v":["ada","c1"]}`)},
+		{name: "missing value", raw: encode(`{"s":"name,id","v":["ada"]}`)},
+		{name: "extra value", raw: encode(`{"s":"name,id","v":["ada","c1","x"]}`)},
+		{name: "truncated", raw: valid[:len(valid)/2]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.raw, signature, len(keys)); !errors.Is(err, ErrInvalidPageRequest) {
+				t.Errorf("decodeCursor() error = %v, want %v", err, ErrInvalidPageRequest)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		backward bool
+		want     string
+	}{
+		{name: "after ascending", sort: "name", want: "(lower(name) > ?) OR (lower(name) = ? AND id > ?)"},
+		{name: "before ascending", sort: "name", backward: true, want: "(lower(name) < ?) OR (lower(name) = ? AND id < ?)"},
+		{name: "after descending", sort: "-age", want: "(age < ?) OR (age = ? AND id > ?)"},
+		{name: "before descending", sort: "-age", backward: true, want: "(age > ?) OR (age = ? AND id < ?)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, _, err := parseSort(pageItemSorting, tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			condition := keysetCondition(keys, []any{"v", "c1"}, tt.backward)
+			if condition.SQL != tt.want {
+				t.Errorf("keysetCondition() = %q, want %q", condition.SQL, tt.want)
+			}
+			if want := []any{"v", "v", "c1"}; !reflect.DeepEqual(condition.Vars, want) {
+				t.Errorf("keysetCondition() vars = %v, want %v", condition.Vars, want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	keys, signature, err := parseSort(pageItemSorting, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := encodeCursor(keys, signature, &pageItem{ID: "c1", Name: "Ada"})
+
+	tests := []struct {
+		name    string
+		request PageRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "offset page with total",
+			request: PageRequest{Page: 3, Size: 10, IncludeTotal: true},
+			want: []string{
+				`SELECT * FROM "page_items" ORDER BY lower(name) ASC, id ASC LIMIT 11 OFFSET 20`,
+				`SELECT count(*) FROM "page_items"`,
+			},
+		},
+		{
+			name:    "offset page without total",
+			request: PageRequest{Page: 1, Size: 10},
+			want:    []string{`SELECT * FROM "page_items" ORDER BY lower(name) ASC, id ASC LIMIT 11`},
+		},
+		{
+			name:    "after cursor",
+			request: PageRequest{After: cursor, Size: 10},
+			want: []string{`SELECT * FROM "page_items" WHERE (lower(name) > 'ada') OR (lower(name) = 'ada' AND id > 'c1') ` +
+				`ORDER BY lower(name) ASC, id ASC LIMIT 11`},
+		},
+		{
+			name:    "before cursor reverses the order",
+			request: PageRequest{Before: cursor, Size: 10, IncludeTotal: true},
+			want: []string{
+				`SELECT * FROM "page_items" WHERE (lower(name) < 'ada') OR (lower(name) = 'ada' AND id < 'c1') ` +
+					`ORDER BY lower(name) DESC, id DESC LIMIT 11`,
+				`SELECT count(*) FROM "page_items"`,
+			},
+		},
+		{
+			name:    "after and before",
+			request: PageRequest{After: cursor, Before: cursor, Size: 10},
+			wantErr: true,
+		},
+		{
+			name:    "cursor of another sort order",
+			request: PageRequest{Sort: "-name", After: cursor, Size: 10},
+			wantErr: true,
+		},
+		{
+			name:    "sort outside the whitelist",
+			request: PageRequest{Sort: "email", Size: 10},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := dryRun(t)
+			_, page, err := Paginate(db, pageItemSorting, tt.request, func(tx *gorm.DB) *gorm.DB { return tx })
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPageRequest) {
+					t.Fatalf("Paginate() error = %v, want %v", err, ErrInvalidPageRequest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Paginate() error = %v", err)
+			}
+			if !reflect.DeepEqual(*statements, tt.want) {
+				t.Errorf("Paginate() ran\n%q\nwant\n%q", *statements, tt.want)
+			}
+			if (page.Total != nil) != tt.request.IncludeTotal {
+				t.Errorf("Paginate() total = %v, want one: %v", page.Total, tt.request.IncludeTotal)
+			}
+		})
+	}
+}
+
+func TestNewPageRequest(t *testing.T) {
+	want := PageRequest{Sort: "-name", After: "a", Before: "b", Page: 2, Size: 20, IncludeTotal: true}
+	requests := []any{
+		&dto.SearchContactRequest{Sort: "-name", After: "a", Before: "b", Page: 2, Size: 20, IncludeTotal: true},
+		&dto.SearchSharedContactRequest{Sort: "-name", After: "a", Before: "b", Page: 2, Size: 20, IncludeTotal: true},
+		&dto.SearchTrashContactRequest{Sort: "-name", After: "a", Before: "b", Page: 2, Size: 20, IncludeTotal: true},
+		&dto.ListContactHistoryRequest{Sort: "-name", After: "a", Before: "b", Page: 2, Size: 20, IncludeTotal: true},
+		&dto.ListNoteRequest{Sort: "-name", After: "a", Before: "b", Page: 2, Size: 20, IncludeTotal: true},
+		&dto.ListAddressRequest{Sort: "-name", After: "a", Before: "b", Page: 2, Size: 20, IncludeTotal: true},
+	}
+	for _, request := range requests {
+		if got := NewPageRequest(request); got != want {
+			t.Errorf("NewPageRequest(%T) = %+v, want %+v", request, got, want)
+		}
+	}
+}
+
+// dryRun opens a database that only builds statements, collecting the SQL of
+// every query it is asked to run with its values inlined.
+func dryRun(t *testing.T) (*gorm.DB, *[]string) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statements := new([]string)
+	err = db.Callback().Query().After("gorm:query").Register("test:collect", func(tx *gorm.DB) {
+		*statements = append(*statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, statements
+}
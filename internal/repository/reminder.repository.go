@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReminderRepository struct {
+	Repository[entity.Reminder]
+	Log *zap.Logger
+}
+
+func NewReminderRepository(log *zap.Logger) *ReminderRepository {
+	return &ReminderRepository{
+		Log: log,
+	}
+}
+
+// CreateIfAbsent inserts the reminder unless one was already recorded for the
+// same date and occurrence, and reports whether it did.
+func (r *ReminderRepository) CreateIfAbsent(db *gorm.DB, reminder *entity.Reminder) (bool, error) {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteOccurredBefore drops the records of occurrences before the given
+// YYYY-MM-DD day, which can no longer be reminded of again.
+func (r *ReminderRepository) DeleteOccurredBefore(db *gorm.DB, day string) error {
+	return db.Where("occurs_on < ?", day).Delete(&entity.Reminder{}).Error
+}
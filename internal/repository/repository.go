@@ -1,6 +1,13 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrVersionConflict = errors.New("version conflict")
 
 type Repository[T any] struct {
 	DB *gorm.DB
@@ -18,6 +25,31 @@ func (r *Repository[T]) Delete(db *gorm.DB, entity *T) error {
 	return db.Delete(entity).Error
 }
 
+// UpdateVersioned saves entity only while the stored row is still at version,
+// so a concurrent writer makes it fail with ErrVersionConflict instead of
+// being overwritten. The entity must already carry its next version.
+func (r *Repository[T]) UpdateVersioned(db *gorm.DB, entity *T, version int64) error {
+	result := db.Model(entity).Where("version = ?", version).Select("*").Omit(clause.Associations).Updates(entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *Repository[T]) DeleteVersioned(db *gorm.DB, entity *T, version int64) error {
+	result := db.Where("version = ?", version).Delete(entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
 func (r *Repository[T]) CountById(db *gorm.DB, id any) (int64, error) {
 	var total int64
 	err := db.Model(new(T)).Where("id = ?", id).Count(&total).Error
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ShareRepository struct {
+	Repository[entity.Share]
+	Log *zap.Logger
+}
+
+func NewShareRepository(log *zap.Logger) *ShareRepository {
+	return &ShareRepository{
+		Log: log,
+	}
+}
+
+func (r *ShareRepository) FindByIdAndOwnerId(db *gorm.DB, share *entity.Share, id string, ownerId string) error {
+	return db.Where("id = ? AND owner_id = ?", id, ownerId).Take(share).Error
+}
+
+func (r *ShareRepository) FindAllByOwnerId(db *gorm.DB, ownerId string) ([]entity.Share, error) {
+	var shares []entity.Share
+	if err := db.Where("owner_id = ?", ownerId).Order("created_at, id").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// CountByTarget counts the grants of the contact, or of the tag when
+// contactId is nil, to the grantee.
+func (r *ShareRepository) CountByTarget(db *gorm.DB, granteeId string, contactId *string, tagId *string) (int64, error) {
+	query := db.Model(new(entity.Share)).Where("grantee_id = ?", granteeId)
+	if contactId != nil {
+		query = query.Where("contact_id = ?", *contactId)
+	} else {
+		query = query.Where("tag_id = ?", *tagId)
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	return total, err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	Repository[entity.Tag]
+	Log *zap.Logger
+}
+
+func NewTagRepository(log *zap.Logger) *TagRepository {
+	return &TagRepository{
+		Log: log,
+	}
+}
+
+func (r *TagRepository) FindByIdAndUserId(db *gorm.DB, tag *entity.Tag, id string, userId string) error {
+	return db.Where("id = ? AND user_id = ?", id, userId).Take(tag).Error
+}
+
+func (r *TagRepository) FindAllByUserId(db *gorm.DB, userId string) ([]entity.Tag, error) {
+	var tags []entity.Tag
+	if err := db.Where("user_id = ?", userId).Order("lower(name), id").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) CountByNameAndUserId(db *gorm.DB, name string, userId string, excludeId string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.Tag)).
+		Where("user_id = ? AND lower(name) = ? AND id <> ?", userId, strings.ToLower(name), excludeId).
+		Count(&total).Error
+	return total, err
+}
+
+func (r *TagRepository) Attach(db *gorm.DB, contactId string, tagId string) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.ContactTag{ContactId: contactId, TagId: tagId}).Error
+}
+
+func (r *TagRepository) Detach(db *gorm.DB, contactId string, tagId string) error {
+	return db.Where("contact_id = ? AND tag_id = ?", contactId, tagId).Delete(&entity.ContactTag{}).Error
+}
@@ -20,3 +20,7 @@ func NewUserRepository(log *zap.Logger) *UserRepository {
 func (r *UserRepository) FindByToken(db *gorm.DB, user *entity.User, token string) error {
 	return db.Where("token = ?", token).First(user).Error
 }
+
+func (r *UserRepository) FindByCalendarToken(db *gorm.DB, user *entity.User, token string) error {
+	return db.Where("calendar_token = ?", token).Take(user).Error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository struct {
+	Repository[entity.UserIdentity]
+	Log *zap.Logger
+}
+
+func NewUserIdentityRepository(log *zap.Logger) *UserIdentityRepository {
+	return &UserIdentityRepository{
+		Log: log,
+	}
+}
+
+func (r *UserIdentityRepository) FindByIssuerAndSubject(db *gorm.DB, identity *entity.UserIdentity, issuer string, subject string) error {
+	return db.Where("issuer = ? AND subject = ?", issuer, subject).Take(identity).Error
+}
+
+// FindAllByVerifiedEmail finds the identities whose provider verified email,
+// compared without regard to case.
+func (r *UserIdentityRepository) FindAllByVerifiedEmail(db *gorm.DB, email string) ([]entity.UserIdentity, error) {
+	var identities []entity.UserIdentity
+	if err := db.Where("email_verified AND LOWER(email) = LOWER(?)", email).Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
@@ -0,0 +1,127 @@
+// Package fakes3 is an in-memory stand-in for an S3 compatible object store,
+// covering the object calls storage.S3Store makes. It checks request
+// signatures against a single key pair and forgets everything on restart, so
+// it is only meant for local development and integration testing.
+package fakes3
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/storage"
+)
+
+type Config struct {
+	AccessKey string
+	SecretKey string
+	Region    string
+}
+
+type object struct {
+	contentType string
+	data        []byte
+}
+
+type Server struct {
+	Config Config
+
+	mu      sync.RWMutex
+	objects map[string]object
+}
+
+func New(config Config) *Server {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return &Server{
+		Config:  config,
+		objects: map[string]object{},
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(s.serve)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if !ok || bucket == "" || key == "" {
+		writeError(w, http.StatusBadRequest, "InvalidRequest")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	if !s.authorized(r, body) {
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+
+	name := bucket + "/" + key
+	switch r.Method {
+	case http.MethodPut:
+		s.mu.Lock()
+		s.objects[name] = object{contentType: r.Header.Get("Content-Type"), data: body}
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		s.mu.RLock()
+		obj, found := s.objects[name]
+		s.mu.RUnlock()
+		if !found {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if obj.contentType != "" {
+			w.Header().Set("Content-Type", obj.contentType)
+		}
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.objects, name)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// authorized signs a copy of the request the way the client should have and
+// compares the result with the Authorization header it sent.
+func (s *Server) authorized(r *http.Request, body []byte) bool {
+	payloadHash := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(payloadHash[:]) {
+		return false
+	}
+
+	signedAt, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil || time.Since(signedAt).Abs() > 15*time.Minute {
+		return false
+	}
+
+	expected := r.Clone(r.Context())
+	storage.SignV4(expected, s.Config.AccessKey, s.Config.SecretKey, s.Config.Region,
+		r.Header.Get("X-Amz-Content-Sha256"), signedAt)
+
+	return subtle.ConstantTimeCompare([]byte(expected.Header.Get("Authorization")), []byte(r.Header.Get("Authorization"))) == 1
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, "<Error><Code>"+code+"</Code></Error>")
+}
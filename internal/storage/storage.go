@@ -0,0 +1,47 @@
+// Package storage keeps binary objects such as contact photos outside the
+// database, behind the BlobStore interface.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque blobs by key. Keys are slash separated paths made
+// of letters, digits, '-', '_' and '.'.
+type BlobStore interface {
+	Put(ctx context.Context, key string, contentType string, data []byte) error
+	// Get returns ErrNotFound when nothing is stored under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds when nothing is stored under key.
+	Delete(ctx context.Context, key string) error
+}
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+func validateKey(key string) error {
+	if key == "" || key[0] == '/' || key[len(key)-1] == '/' {
+		return ErrInvalidKey
+	}
+
+	segmentStart := 0
+	for i := 0; i <= len(key); i++ {
+		if i < len(key) && key[i] != '/' {
+			c := key[i]
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+				return ErrInvalidKey
+			}
+			continue
+		}
+
+		segment := key[segmentStart:i]
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+		segmentStart = i + 1
+	}
+	return nil
+}
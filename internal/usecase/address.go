@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/geocode"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/postal"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newAddress builds the address a create request describes for the contact,
+// normalized, and reports the fields that break its country's rules. Whether
+// it is primary is up to the caller.
+func newAddress(contactId string, request *dto.CreateAddressRequest) (*entity.Address, []dto.FieldError) {
+	address := &entity.Address{
+		ID:          uuid.NewString(),
+		ContactId:   contactId,
+		Street:      request.Street,
+		City:        request.City,
+		Province:    request.Province,
+		PostalCode:  request.PostalCode,
+		Country:     request.Country,
+		Label:       request.Label,
+		CustomLabel: request.CustomLabel,
+	}
+	return address, normalizeAddress(address)
+}
+
+// createAddress stores a new address and records its creation, with tx.
+func createAddress(ctx context.Context, tx *gorm.DB, addressRepository *repository.AddressRepository,
+	historyRepository *repository.ContactHistoryRepository, address *entity.Address, actorId string) error {
+	if err := addressRepository.Create(tx, address); err != nil {
+		return err
+	}
+
+	return recordHistory(ctx, tx, historyRepository, &entity.ContactHistory{
+		ContactId:  address.ContactId,
+		EntityType: entity.HistoryAddress,
+		EntityId:   address.ID,
+		Action:     entity.HistoryCreate,
+		Version:    address.Version,
+		ActorId:    actorId,
+	}, nil, addressSnapshot(address))
+}
+
+// normalizeAddress puts the address's fields into stored form and holds them
+// to the rules of the address's country.
+func normalizeAddress(address *entity.Address) []dto.FieldError {
+	normalized := postal.Normalize(postalAddress(address))
+	address.Street = normalized.Street
+	address.City = normalized.City
+	address.Province = normalized.Province
+	address.PostalCode = normalized.PostalCode
+	address.Country = normalized.Country
+
+	var fields []dto.FieldError
+	for _, err := range postal.Validate(normalized) {
+		fields = append(fields, dto.FieldError{Field: err.Field, Message: err.Message})
+	}
+	return fields
+}
+
+func postalAddress(address *entity.Address) postal.Address {
+	return postal.Address{
+		Street:     address.Street,
+		City:       address.City,
+		Province:   address.Province,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
+
+// geocodeAddress places the address on the map. Addresses the geocoder
+// cannot place, or cannot reach a service for, are kept without coordinates
+// rather than refused, and are left out of proximity searches.
+func geocodeAddress(ctx context.Context, geocoder geocode.Geocoder, log *zap.Logger, address *entity.Address) {
+	point, err := geocoder.Geocode(ctx, postalAddress(address))
+	if err != nil {
+		if !errors.Is(err, geocode.ErrNotFound) {
+			log.With(zap.Error(err)).Warn("failed to geocode address")
+		}
+		address.Latitude, address.Longitude = nil, nil
+		return
+	}
+	address.Latitude, address.Longitude = &point.Latitude, &point.Longitude
+}
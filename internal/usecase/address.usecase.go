@@ -2,13 +2,14 @@ package usecase
 
 import (
 	"context"
+	"errors"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
-	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/geocode"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -20,116 +21,230 @@ type AddressUseCase struct {
 	Validate          *validator.Validate
 	AddressRepository *repository.AddressRepository
 	ContactRepository *repository.ContactRepository
+	HistoryRepository *repository.ContactHistoryRepository
+	Geocoder          geocode.Geocoder
 }
 
 func NewAddressUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
-	contactRepository *repository.ContactRepository, addressRepository *repository.AddressRepository) *AddressUseCase {
+	contactRepository *repository.ContactRepository, addressRepository *repository.AddressRepository,
+	historyRepository *repository.ContactHistoryRepository, geocoder geocode.Geocoder) *AddressUseCase {
 	return &AddressUseCase{
 		DB:                db,
 		Log:               logger,
 		Validate:          validate,
 		ContactRepository: contactRepository,
 		AddressRepository: addressRepository,
+		HistoryRepository: historyRepository,
+		Geocoder:          geocoder,
 	}
 }
 
 func (c *AddressUseCase) Create(ctx context.Context, request *dto.CreateAddressRequest) (*dto.AddressResponse, error) {
-	tx := c.DB.WithContext(ctx).Begin()
-	defer tx.Rollback()
-
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to validate request body")
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
+	}
+
+	address, fields := newAddress(request.ContactId, request)
+	if fields != nil {
+		c.Log.Warn("address breaks its country's rules", zap.String("country", address.Country))
+		return nil, apperror.Invalid("address breaks its country's rules", fields...)
 	}
+	// The geocoder may be a remote service, so it is asked before the
+	// transaction takes any locks.
+	geocodeAddress(ctx, c.Geocoder, c.Log, address)
 
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	// The contact stays locked until commit, so concurrent changes of its
+	// primary address take turns.
 	contact := new(entity.Contact)
-	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+	if err := c.ContactRepository.FindByIdAndAccessForUpdate(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find contact")
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
 	}
 
-	address := &entity.Address{
-		ID:         uuid.NewString(),
-		ContactId:  contact.ID,
-		Street:     request.Street,
-		City:       request.City,
-		Province:   request.Province,
-		PostalCode: request.PostalCode,
-		Country:    request.Country,
+	primary := new(entity.Address)
+	hasPrimary := true
+	if err := c.AddressRepository.FindPrimaryByContactId(tx, primary, contact.ID); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.With(zap.Error(err)).Error("failed to find primary address")
+			return nil, apperror.ErrInternal
+		}
+		hasPrimary = false
 	}
 
-	if err := c.AddressRepository.Create(tx, address); err != nil {
+	address.IsPrimary = request.IsPrimary || !hasPrimary
+
+	if address.IsPrimary && hasPrimary {
+		if err := c.changePrimary(ctx, tx, primary, false, request.UserId); err != nil {
+			c.Log.With(zap.Error(err)).Error("failed to demote primary address")
+			return nil, apperror.ErrInternal
+		}
+	}
+
+	if err := createAddress(ctx, tx, c.AddressRepository, c.HistoryRepository, address, request.UserId); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to create address")
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
+	}
+
+	// Addresses are part of the contact, so it changes with them.
+	if err := c.ContactRepository.BumpVersion(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to bump contact version")
+		return nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.AddressToResponse(address), nil
 }
 
+// Update reads and geocodes outside of the transaction, which only writes;
+// the versioned update refuses the write if the address changed meanwhile.
 func (c *AddressUseCase) Update(ctx context.Context, request *dto.UpdateAddressRequest) (*dto.AddressResponse, error) {
-	tx := c.DB.WithContext(ctx).Begin()
-	defer tx.Rollback()
-
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to validate request body")
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
 	}
 
+	db := c.DB.WithContext(ctx)
+
 	contact := new(entity.Contact)
-	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+	if err := c.ContactRepository.FindByIdAndAccess(db, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find contact")
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
 	}
 
 	address := new(entity.Address)
-	if err := c.AddressRepository.FindByIdAndContactId(tx, address, request.ID, contact.ID); err != nil {
+	if err := c.AddressRepository.FindByIdAndContactId(db, address, request.ID, contact.ID); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find address")
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := checkVersion(request.IfMatch, address.Version); err != nil {
+		c.Log.Warn("address version does not match", zap.Int64("version", address.Version))
+		return nil, err
 	}
 
+	before := addressSnapshot(address)
+	location := postalAddress(address)
 	address.Street = request.Street
 	address.City = request.City
 	address.Province = request.Province
 	address.PostalCode = request.PostalCode
 	address.Country = request.Country
+	address.Label = request.Label
+	address.CustomLabel = request.CustomLabel
+	if fields := normalizeAddress(address); fields != nil {
+		c.Log.Warn("address breaks its country's rules", zap.String("country", address.Country))
+		return nil, apperror.Invalid("address breaks its country's rules", fields...)
+	}
+	if postalAddress(address) != location {
+		geocodeAddress(ctx, c.Geocoder, c.Log, address)
+	}
+	address.Version++
+
+	tx := db.Begin()
+	defer tx.Rollback()
 
-	if err := c.AddressRepository.Update(tx, address); err != nil {
+	if err := c.AddressRepository.UpdateVersioned(tx, address, address.Version-1); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to update address")
-		return nil, echo.ErrInternalServerError
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, errVersionMismatch
+		}
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.recordAddressHistory(ctx, tx, address, entity.HistoryUpdate, request.UserId, before, addressSnapshot(address)); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to record address history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.BumpVersion(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to bump contact version")
+		return nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.AddressToResponse(address), nil
 }
 
+// Patch applies the patch to the address's current values and saves the
+// result through Update, pinned to the version the patch was applied to.
+func (c *AddressUseCase) Patch(ctx context.Context, request *dto.PatchAddressRequest) (*dto.AddressResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	db := c.DB.WithContext(ctx)
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(db, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	address := new(entity.Address)
+	if err := c.AddressRepository.FindByIdAndContactId(db, address, request.ID, contact.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find address")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := checkVersion(request.IfMatch, address.Version); err != nil {
+		c.Log.Warn("address version does not match", zap.Int64("version", address.Version))
+		return nil, err
+	}
+
+	update, err := applyPatch(&dto.UpdateAddressRequest{
+		Street:      address.Street,
+		City:        address.City,
+		Province:    address.Province,
+		PostalCode:  address.PostalCode,
+		Country:     address.Country,
+		Label:       address.Label,
+		CustomLabel: address.CustomLabel,
+	}, request.Format, request.Patch)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("failed to apply address patch")
+		return nil, err
+	}
+
+	update.UserId = request.UserId
+	update.ContactId = request.ContactId
+	update.ID = request.ID
+	update.IfMatch = []int64{address.Version}
+
+	return c.Update(ctx, update)
+}
+
 func (c *AddressUseCase) Get(ctx context.Context, request *dto.GetAddressRequest) (*dto.AddressResponse, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	contact := new(entity.Contact)
-	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionRead); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find contact")
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
 	}
 
 	address := new(entity.Address)
 	if err := c.AddressRepository.FindByIdAndContactId(tx, address, request.ID, request.ContactId); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find address")
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.AddressToResponse(address), nil
@@ -140,49 +255,214 @@ func (c *AddressUseCase) Delete(ctx context.Context, request *dto.DeleteAddressR
 	defer tx.Rollback()
 
 	contact := new(entity.Contact)
-	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+	if err := c.ContactRepository.FindByIdAndAccessForUpdate(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find contact")
-		return echo.ErrNotFound
+		return apperror.ErrNotFound
 	}
 
 	address := new(entity.Address)
 	if err := c.AddressRepository.FindByIdAndContactId(tx, address, request.ID, request.ContactId); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find address")
-		return echo.ErrNotFound
+		return apperror.ErrNotFound
+	}
+
+	if err := checkVersion(request.IfMatch, address.Version); err != nil {
+		c.Log.Warn("address version does not match", zap.Int64("version", address.Version))
+		return err
 	}
 
-	if err := c.AddressRepository.Delete(tx, address); err != nil {
+	if err := c.AddressRepository.DeleteVersioned(tx, address, address.Version); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to delete address")
-		return echo.ErrInternalServerError
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return errVersionMismatch
+		}
+		return apperror.ErrInternal
+	}
+
+	if err := c.recordAddressHistory(ctx, tx, address, entity.HistoryDelete, request.UserId, addressSnapshot(address), nil); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to record address history")
+		return apperror.ErrInternal
+	}
+
+	// The oldest remaining address takes over as primary.
+	if address.IsPrimary {
+		next := new(entity.Address)
+		err := c.AddressRepository.FindOldestByContactId(tx, next, contact.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.With(zap.Error(err)).Error("failed to find address")
+			return apperror.ErrInternal
+		}
+		if err == nil {
+			if err := c.changePrimary(ctx, tx, next, true, request.UserId); err != nil {
+				c.Log.With(zap.Error(err)).Error("failed to promote primary address")
+				return apperror.ErrInternal
+			}
+		}
+	}
+
+	if err := c.ContactRepository.BumpVersion(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to bump contact version")
+		return apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
-		return echo.ErrInternalServerError
+		return apperror.ErrInternal
 	}
 
 	return nil
 }
 
-func (c *AddressUseCase) List(ctx context.Context, request *dto.ListAddressRequest) ([]dto.AddressResponse, error) {
+// Restore brings a deleted address back to its contact until the trash is
+// purged. It is primary again only if no other address took over when it
+// was deleted.
+func (c *AddressUseCase) Restore(ctx context.Context, request *dto.RestoreAddressRequest) (*dto.AddressResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccessForUpdate(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	address := new(entity.Address)
+	if err := c.AddressRepository.FindTrashedByIdAndContactId(tx, address, request.ID, contact.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find deleted address")
+		return nil, apperror.ErrNotFound
+	}
+	before := addressSnapshot(address)
+
+	primary := new(entity.Address)
+	err := c.AddressRepository.FindPrimaryByContactId(tx, primary, contact.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.With(zap.Error(err)).Error("failed to find primary address")
+		return nil, apperror.ErrInternal
+	}
+	address.IsPrimary = err != nil
+
+	if err := c.AddressRepository.Restore(tx, address); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to restore address")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.recordAddressHistory(ctx, tx, address, entity.HistoryRestore, request.UserId, before, addressSnapshot(address)); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to record address history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.BumpVersion(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to bump contact version")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.AddressToResponse(address), nil
+}
+
+// SetPrimary makes the address its contact's primary one. The old primary
+// address is demoted in the same transaction, so the contact is never seen
+// with two primary addresses or, in between, with none.
+func (c *AddressUseCase) SetPrimary(ctx context.Context, request *dto.SetPrimaryAddressRequest) (*dto.AddressResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccessForUpdate(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	address := new(entity.Address)
+	if err := c.AddressRepository.FindByIdAndContactId(tx, address, request.ID, contact.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find address")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := checkVersion(request.IfMatch, address.Version); err != nil {
+		c.Log.Warn("address version does not match", zap.Int64("version", address.Version))
+		return nil, err
+	}
+
+	if address.IsPrimary {
+		return converter.AddressToResponse(address), nil
+	}
+
+	primary := new(entity.Address)
+	err := c.AddressRepository.FindPrimaryByContactId(tx, primary, contact.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.With(zap.Error(err)).Error("failed to find primary address")
+		return nil, apperror.ErrInternal
+	}
+	if err == nil {
+		if err := c.changePrimary(ctx, tx, primary, false, request.UserId); err != nil {
+			c.Log.With(zap.Error(err)).Error("failed to demote primary address")
+			return nil, apperror.ErrInternal
+		}
+	}
+
+	if err := c.changePrimary(ctx, tx, address, true, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to promote primary address")
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, errVersionMismatch
+		}
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.BumpVersion(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to bump contact version")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.AddressToResponse(address), nil
+}
+
+func (c *AddressUseCase) List(ctx context.Context, request *dto.ListAddressRequest) ([]dto.AddressResponse, *dto.PageMetadata, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, nil, apperror.Validation(err)
+	}
+
 	contact := new(entity.Contact)
-	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionRead); err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find contact")
-		return nil, echo.ErrNotFound
+		return nil, nil, apperror.ErrNotFound
 	}
 
-	addresses, err := c.AddressRepository.FindAllByContactId(tx, contact.ID)
+	addresses, page, err := c.AddressRepository.Search(tx, request)
 	if err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to find addresses")
-		return nil, echo.ErrInternalServerError
+		if errors.Is(err, repository.ErrInvalidPageRequest) {
+			return nil, nil, apperror.Invalid(err.Error())
+		}
+		return nil, nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
-		return nil, echo.ErrInternalServerError
+		return nil, nil, apperror.ErrInternal
 	}
 
 	responses := make([]dto.AddressResponse, len(addresses))
@@ -190,5 +470,69 @@ func (c *AddressUseCase) List(ctx context.Context, request *dto.ListAddressReque
 		responses[i] = *converter.AddressToResponse(&address)
 	}
 
+	return responses, toPageMetadata(page, repository.NewPageRequest(request)), nil
+}
+
+// ListByContacts reads the addresses of every given contact the user can
+// read, by contact id, with one query for all of them.
+func (c *AddressUseCase) ListByContacts(ctx context.Context, request *dto.ListContactsAddressesRequest) (map[string][]dto.AddressResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contactIds, err := c.ContactRepository.FindAllIdsByAccess(tx, request.ContactIds, request.UserId, entity.PermissionRead)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contacts")
+		return nil, apperror.ErrInternal
+	}
+
+	addresses, err := c.AddressRepository.FindAllByContactIds(tx, contactIds)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find addresses")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return nil, apperror.ErrInternal
+	}
+
+	responses := make(map[string][]dto.AddressResponse, len(contactIds))
+	for _, contactId := range contactIds {
+		responses[contactId] = []dto.AddressResponse{}
+	}
+	for i := range addresses {
+		responses[addresses[i].ContactId] = append(responses[addresses[i].ContactId], *converter.AddressToResponse(&addresses[i]))
+	}
 	return responses, nil
 }
+
+func (c *AddressUseCase) recordAddressHistory(ctx context.Context, tx *gorm.DB, address *entity.Address, action string,
+	actorId string, before map[string]any, after map[string]any) error {
+	return recordHistory(ctx, tx, c.HistoryRepository, &entity.ContactHistory{
+		ContactId:  address.ContactId,
+		EntityType: entity.HistoryAddress,
+		EntityId:   address.ID,
+		Action:     action,
+		Version:    address.Version,
+		ActorId:    actorId,
+	}, before, after)
+}
+
+// changePrimary sets or clears the address's primary flag as a versioned,
+// recorded update. The unique index allows one primary address per contact,
+// so the old one has to be cleared before another is set.
+func (c *AddressUseCase) changePrimary(ctx context.Context, tx *gorm.DB, address *entity.Address, primary bool, actorId string) error {
+	before := addressSnapshot(address)
+	address.IsPrimary = primary
+	address.Version++
+
+	if err := c.AddressRepository.UpdateVersioned(tx, address, address.Version-1); err != nil {
+		return err
+	}
+	return c.recordAddressHistory(ctx, tx, address, entity.HistoryUpdate, actorId, before, addressSnapshot(address))
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// calendarFeedYear starts the events of dates without a year. It is a leap
+// year, so Feb 29 is a valid start.
+const calendarFeedYear = 2000
+
+type CalendarUseCase struct {
+	DB                *gorm.DB
+	Log               *zap.Logger
+	Validate          *validator.Validate
+	UserRepository    *repository.UserRepository
+	ContactRepository *repository.ContactRepository
+}
+
+func NewCalendarUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
+	userRepository *repository.UserRepository, contactRepository *repository.ContactRepository) *CalendarUseCase {
+	return &CalendarUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		UserRepository:    userRepository,
+		ContactRepository: contactRepository,
+	}
+}
+
+// CreateToken turns on the user's calendar feed, or moves it to a new secret
+// URL when it is on already, so a leaked URL can be cut off.
+func (c *CalendarUseCase) CreateToken(ctx context.Context, request *dto.CreateCalendarTokenRequest) (*dto.CalendarTokenResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting user")
+		return nil, apperror.ErrNotFound
+	}
+
+	user.CalendarToken = uuid.New().String()
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.With(zap.Error(err)).Error("error saving calendar token")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error saving calendar token")
+		return nil, apperror.ErrInternal
+	}
+
+	return &dto.CalendarTokenResponse{
+		Token: user.CalendarToken,
+		URL:   "/api/calendar.ics?token=" + user.CalendarToken,
+	}, nil
+}
+
+// DeleteToken turns the user's calendar feed off.
+func (c *CalendarUseCase) DeleteToken(ctx context.Context, request *dto.DeleteCalendarTokenRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return false, apperror.Validation(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting user")
+		return false, apperror.ErrNotFound
+	}
+
+	user.CalendarToken = ""
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting calendar token")
+		return false, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting calendar token")
+		return false, apperror.ErrInternal
+	}
+
+	return true, nil
+}
+
+// Feed returns every date of the token owner's contacts as a yearly event.
+// Shared contacts stay out of it: the feed URL is meant to be handed to a
+// calendar app, not to carry other people's contacts along.
+func (c *CalendarUseCase) Feed(ctx context.Context, request *dto.CalendarFeedRequest) (*dto.CalendarFeedResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.ErrUnauthorized
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindByCalendarToken(tx, user, request.Token); err != nil {
+		c.Log.With(zap.Error(err)).Warn("error getting user by calendar token")
+		return nil, apperror.ErrUnauthorized
+	}
+
+	contacts, err := c.ContactRepository.FindAllWithDates(tx, user.ID)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact dates")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact dates")
+		return nil, apperror.ErrInternal
+	}
+
+	response := &dto.CalendarFeedResponse{
+		Name:   "Contacts of " + user.Name,
+		Events: []dto.CalendarEventResponse{},
+	}
+	for i := range contacts {
+		for _, date := range contacts[i].Dates {
+			response.Events = append(response.Events, calendarEvent(&contacts[i], date))
+		}
+	}
+
+	return response, nil
+}
+
+func calendarEvent(contact *entity.Contact, date entity.ContactDate) dto.CalendarEventResponse {
+	year := date.Year
+	if year == 0 {
+		year = calendarFeedYear
+	}
+
+	// Labels may hold anything, so custom dates are identified by a hash of
+	// theirs.
+	uid := contact.ID + "-" + date.Type
+	if date.Type == entity.DateTypeCustom {
+		sum := sha256.Sum256([]byte(date.Label))
+		uid += "-" + hex.EncodeToString(sum[:8])
+	}
+
+	event := dto.CalendarEventResponse{
+		UID:     uid,
+		Summary: contactDateSummary(contact, date),
+		Start:   occurrenceIn(date, year),
+	}
+	if date.Year != 0 {
+		event.Description = fmt.Sprintf("Since %d", date.Year)
+		if date.Type == entity.DateTypeBirthday {
+			event.Description = fmt.Sprintf("Born in %d", date.Year)
+		}
+	}
+	return event
+}
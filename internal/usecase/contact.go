@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/canonical"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"gorm.io/gorm"
+)
+
+var errNotOwner = apperror.New(apperror.KindForbidden, "only the contact's owner can do this")
+
+// checkOwner keeps the writes that are the owner's alone from grantees, even
+// ones with write permission: trashing the contact, as the trash is the
+// owner's, and changing its tags, as tags are the owner's and decide whom
+// tag shares reach.
+func checkOwner(contact *entity.Contact, userId string) error {
+	if contact.UserId != userId {
+		return errNotOwner
+	}
+	return nil
+}
+
+// newContact builds the contact a create request describes, given the
+// stored forms of its phone, custom fields and dates, which the caller has
+// already checked.
+func newContact(id string, request *dto.CreateContactRequest, phoneE164 string, customFields map[string]any,
+	dates []entity.ContactDate) *entity.Contact {
+	return &entity.Contact{
+		ID:           id,
+		FirstName:    request.FirstName,
+		LastName:     request.LastName,
+		Email:        canonical.Email(request.Email),
+		Phone:        request.Phone,
+		PhoneE164:    phoneE164,
+		UserId:       request.UserId,
+		CustomFields: customFields,
+		Dates:        dates,
+	}
+}
+
+// createContact stores a new contact and records its creation, with tx.
+func createContact(ctx context.Context, tx *gorm.DB, contactRepository *repository.ContactRepository,
+	historyRepository *repository.ContactHistoryRepository, contact *entity.Contact, actorId string) error {
+	if err := contactRepository.Create(tx, contact); err != nil {
+		return err
+	}
+
+	return recordHistory(ctx, tx, historyRepository, &entity.ContactHistory{
+		ContactId:  contact.ID,
+		EntityType: entity.HistoryContact,
+		EntityId:   contact.ID,
+		Action:     entity.HistoryCreate,
+		Version:    contact.Version,
+		ActorId:    actorId,
+	}, nil, contactSnapshot(contact))
+}
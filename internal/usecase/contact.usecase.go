@@ -1,33 +1,60 @@
 package usecase
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/canonical"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/storage"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+const contactExportBatchSize = 200
+
 type ContactUseCase struct {
-	DB                *gorm.DB
-	Log               *zap.Logger
-	Validate          *validator.Validate
-	ContactRepository *repository.ContactRepository
+	DB                    *gorm.DB
+	Log                   *zap.Logger
+	Validate              *validator.Validate
+	ContactRepository     *repository.ContactRepository
+	AddressRepository     *repository.AddressRepository
+	TagRepository         *repository.TagRepository
+	HistoryRepository     *repository.ContactHistoryRepository
+	NoteRepository        *repository.NoteRepository
+	CustomFieldRepository *repository.CustomFieldRepository
+	BlobStore             storage.BlobStore
+	DefaultRegion         string
 }
 
 func NewContactUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
-	contactRepository *repository.ContactRepository) *ContactUseCase {
+	contactRepository *repository.ContactRepository, addressRepository *repository.AddressRepository,
+	tagRepository *repository.TagRepository, historyRepository *repository.ContactHistoryRepository,
+	noteRepository *repository.NoteRepository, customFieldRepository *repository.CustomFieldRepository,
+	blobStore storage.BlobStore, defaultRegion string) *ContactUseCase {
 	return &ContactUseCase{
-		DB:                db,
-		Log:               logger,
-		Validate:          validate,
-		ContactRepository: contactRepository,
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		ContactRepository:     contactRepository,
+		AddressRepository:     addressRepository,
+		TagRepository:         tagRepository,
+		HistoryRepository:     historyRepository,
+		NoteRepository:        noteRepository,
+		CustomFieldRepository: customFieldRepository,
+		BlobStore:             blobStore,
+		DefaultRegion:         defaultRegion,
 	}
 }
 
@@ -37,26 +64,34 @@ func (c *ContactUseCase) Create(ctx context.Context, request *dto.CreateContactR
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.With(zap.Error(err)).Error("error validating request body")
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
+	}
+
+	phoneE164, _, err := canonicalContactDetails(request.Phone, request.Email, c.DefaultRegion)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error normalizing contact details")
+		return nil, err
+	}
+
+	customFields, err := c.customFieldValues(tx, request.UserId, request.CustomFields)
+	if err != nil {
+		return nil, err
 	}
 
-	contact := &entity.Contact{
-		ID:        uuid.New().String(),
-		FirstName: request.FirstName,
-		LastName:  request.LastName,
-		Email:     request.Email,
-		Phone:     request.Phone,
-		UserId:    request.UserId,
+	dates, err := c.contactDates(request.Dates)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := c.ContactRepository.Create(tx, contact); err != nil {
+	contact := newContact(uuid.New().String(), request, phoneE164, customFields, dates)
+	if err := createContact(ctx, tx, c.ContactRepository, c.HistoryRepository, contact, request.UserId); err != nil {
 		c.Log.With(zap.Error(err)).Error("error creating contact")
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("error creating contact")
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.ContactToResponse(contact), nil
@@ -67,103 +102,410 @@ func (c *ContactUseCase) Update(ctx context.Context, request *dto.UpdateContactR
 	defer tx.Rollback()
 
 	contact := new(entity.Contact)
-	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ID, request.UserId, entity.PermissionWrite); err != nil {
 		c.Log.With(zap.Error(err)).Error("error getting contact")
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
 	}
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.With(zap.Error(err)).Error("error validating request body")
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
+	}
+
+	if err := checkVersion(request.IfMatch, contact.Version); err != nil {
+		c.Log.Warn("contact version does not match", zap.Int64("version", contact.Version))
+		return nil, err
 	}
 
+	addresses, err := c.AddressRepository.FindAllByContactId(tx, contact.ID)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact addresses")
+		return nil, apperror.ErrInternal
+	}
+
+	countries := make([]string, len(addresses))
+	for i, address := range addresses {
+		countries[i] = address.Country
+	}
+
+	phoneE164, email, err := canonicalContactDetails(request.Phone, request.Email, phoneRegion(c.DefaultRegion, countries...))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error normalizing contact details")
+		return nil, err
+	}
+
+	// A shared contact is checked against its owner's field definitions.
+	customFields, err := c.customFieldValues(tx, contact.UserId, request.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	dates, err := c.contactDates(request.Dates)
+	if err != nil {
+		return nil, err
+	}
+
+	before := contactSnapshot(contact)
 	contact.FirstName = request.FirstName
 	contact.LastName = request.LastName
-	contact.Email = request.Email
+	contact.Email = email
 	contact.Phone = request.Phone
+	contact.PhoneE164 = phoneE164
+	contact.CustomFields = customFields
+	contact.Dates = dates
+	contact.Version++
 
-	if err := c.ContactRepository.Update(tx, contact); err != nil {
+	if err := c.ContactRepository.UpdateVersioned(tx, contact, contact.Version-1); err != nil {
 		c.Log.With(zap.Error(err)).Error("error updating contact")
-		return nil, echo.ErrInternalServerError
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, errVersionMismatch
+		}
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.recordContactHistory(ctx, tx, contact, entity.HistoryUpdate, request.UserId, before, contactSnapshot(contact)); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.LoadTags(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact tags")
+		return nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("error updating contact")
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.ContactToResponse(contact), nil
 }
 
+// Patch applies the patch to the contact's current values and saves the
+// result through Update, pinned to the version the patch was applied to.
+func (c *ContactUseCase) Patch(ctx context.Context, request *dto.PatchContactRequest) (*dto.ContactResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(c.DB.WithContext(ctx), contact, request.ID, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := checkVersion(request.IfMatch, contact.Version); err != nil {
+		c.Log.Warn("contact version does not match", zap.Int64("version", contact.Version))
+		return nil, err
+	}
+
+	update, err := applyPatch(&dto.UpdateContactRequest{
+		FirstName:    contact.FirstName,
+		LastName:     contact.LastName,
+		Email:        contact.Email,
+		Phone:        contact.Phone,
+		CustomFields: contact.CustomFields,
+		Dates:        contactDateRequests(contact.Dates),
+	}, request.Format, request.Patch)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error applying contact patch")
+		return nil, err
+	}
+
+	update.UserId = request.UserId
+	update.ID = request.ID
+	update.IfMatch = []int64{contact.Version}
+
+	return c.Update(ctx, update)
+}
+
 func (c *ContactUseCase) Get(ctx context.Context, request *dto.GetContactRequest) (*dto.ContactResponse, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.With(zap.Error(err)).Error("error validating request body")
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
 	}
 
 	contact := new(entity.Contact)
-	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ID, request.UserId, entity.PermissionRead); err != nil {
 		c.Log.With(zap.Error(err)).Error("error getting contact")
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := c.ContactRepository.LoadTags(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact tags")
+		return nil, apperror.ErrInternal
+	}
+
+	if slices.Contains(request.Expand, "addresses") {
+		if err := c.ContactRepository.LoadAddresses(tx, contact); err != nil {
+			c.Log.With(zap.Error(err)).Error("error getting contact addresses")
+			return nil, apperror.ErrInternal
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("error getting contact")
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.ContactToResponse(contact), nil
 }
 
+// Delete moves the contact to its owner's trash. Grantees cannot, whatever
+// their permission, see checkOwner.
 func (c *ContactUseCase) Delete(ctx context.Context, request *dto.DeleteContactRequest) error {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.With(zap.Error(err)).Error("error validating request body")
-		return echo.ErrBadRequest
+		return apperror.Validation(err)
 	}
 
 	contact := new(entity.Contact)
-	if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ID, request.UserId, entity.PermissionRead); err != nil {
 		c.Log.With(zap.Error(err)).Error("error getting contact")
-		return echo.ErrNotFound
+		return apperror.ErrNotFound
 	}
 
-	if err := c.ContactRepository.Delete(tx, contact); err != nil {
+	if err := checkOwner(contact, request.UserId); err != nil {
+		c.Log.Warn("only the owner can delete a contact")
+		return err
+	}
+
+	if err := checkVersion(request.IfMatch, contact.Version); err != nil {
+		c.Log.Warn("contact version does not match", zap.Int64("version", contact.Version))
+		return err
+	}
+
+	if err := c.ContactRepository.Trash(tx, contact); err != nil {
 		c.Log.With(zap.Error(err)).Error("error deleting contact")
-		return echo.ErrInternalServerError
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return errVersionMismatch
+		}
+		return apperror.ErrInternal
+	}
+
+	if err := c.recordContactHistory(ctx, tx, contact, entity.HistoryDelete, request.UserId, contactSnapshot(contact), nil); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("error deleting contact")
-		return echo.ErrInternalServerError
+		return apperror.ErrInternal
 	}
 
 	return nil
 }
 
-func (c *ContactUseCase) Search(ctx context.Context, request *dto.SearchContactRequest) ([]dto.ContactResponse, int64, error) {
+func (c *ContactUseCase) Restore(ctx context.Context, request *dto.RestoreContactRequest) (*dto.ContactResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindTrashedByIdAndUserId(tx, contact, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting trashed contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := c.ContactRepository.Restore(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("error restoring contact")
+		return nil, apperror.ErrInternal
+	}
+
+	snapshot := contactSnapshot(contact)
+	if err := c.recordContactHistory(ctx, tx, contact, entity.HistoryRestore, request.UserId, snapshot, snapshot); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.LoadTags(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact tags")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error restoring contact")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.ContactToResponse(contact), nil
+}
+
+func (c *ContactUseCase) SearchTrash(ctx context.Context, request *dto.SearchTrashContactRequest) ([]dto.ContactResponse, *dto.PageMetadata, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, nil, apperror.Validation(err)
+	}
+
+	contacts, page, err := c.ContactRepository.SearchTrash(tx, request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting trashed contacts")
+		if errors.Is(err, repository.ErrInvalidPageRequest) {
+			return nil, nil, apperror.Invalid(err.Error())
+		}
+		return nil, nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting trashed contacts")
+		return nil, nil, apperror.ErrInternal
+	}
+
+	responses := make([]dto.ContactResponse, len(contacts))
+	for i, contact := range contacts {
+		responses[i] = *converter.ContactToResponse(&contact)
+	}
+
+	return responses, toPageMetadata(page, repository.NewPageRequest(request)), nil
+}
+
+// SearchShared lists the contacts other users shared with the requester.
+func (c *ContactUseCase) SearchShared(ctx context.Context, request *dto.SearchSharedContactRequest) ([]dto.SharedContactResponse, *dto.PageMetadata, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.With(zap.Error(err)).Error("error validating request body")
-		return nil, 0, echo.ErrBadRequest
+		return nil, nil, apperror.Validation(err)
 	}
 
-	contacts, total, err := c.ContactRepository.Search(tx, request)
+	contacts, page, err := c.ContactRepository.SearchShared(tx, request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting shared contacts")
+		if errors.Is(err, repository.ErrInvalidPageRequest) {
+			return nil, nil, apperror.Invalid(err.Error())
+		}
+		return nil, nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting shared contacts")
+		return nil, nil, apperror.ErrInternal
+	}
+
+	responses := make([]dto.SharedContactResponse, len(contacts))
+	for i, contact := range contacts {
+		responses[i] = *converter.SharedContactToResponse(&contact)
+	}
+
+	return responses, toPageMetadata(page, repository.NewPageRequest(request)), nil
+}
+
+func (c *ContactUseCase) AddTag(ctx context.Context, request *dto.ContactTagRequest) (*dto.ContactResponse, error) {
+	return c.changeTag(ctx, request, c.TagRepository.Attach)
+}
+
+func (c *ContactUseCase) RemoveTag(ctx context.Context, request *dto.ContactTagRequest) (*dto.ContactResponse, error) {
+	return c.changeTag(ctx, request, c.TagRepository.Detach)
+}
+
+// changeTag attaches or detaches one of the owner's tags. Like Delete, it is
+// for the owner alone.
+func (c *ContactUseCase) changeTag(ctx context.Context, request *dto.ContactTagRequest,
+	change func(db *gorm.DB, contactId string, tagId string) error) (*dto.ContactResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionRead); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := checkOwner(contact, request.UserId); err != nil {
+		c.Log.Warn("only the owner can change a contact's tags")
+		return nil, err
+	}
+
+	tag := new(entity.Tag)
+	if err := c.TagRepository.FindByIdAndUserId(tx, tag, request.TagId, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tag")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := c.ContactRepository.LoadTags(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact tags")
+		return nil, apperror.ErrInternal
+	}
+	before := withTags(contactSnapshot(contact), contact.Tags)
+
+	if err := change(tx, contact.ID, tag.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("error changing contact tag")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.BumpVersion(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("error changing contact tag")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.LoadTags(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact tags")
+		return nil, apperror.ErrInternal
+	}
+
+	after := withTags(contactSnapshot(contact), contact.Tags)
+	if err := c.recordContactHistory(ctx, tx, contact, entity.HistoryUpdate, request.UserId, before, after); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error changing contact tag")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.ContactToResponse(contact), nil
+}
+
+func (c *ContactUseCase) Search(ctx context.Context, request *dto.SearchContactRequest) ([]dto.ContactResponse, *dto.PageMetadata, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, nil, apperror.Validation(err)
+	}
+
+	request.PhonePrefix = canonical.PhonePrefix(request.Phone, c.DefaultRegion)
+
+	filters, err := c.customFieldFilters(tx, request.UserId, request.CustomFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.CustomFieldFilters = filters
+
+	contacts, page, err := c.ContactRepository.Search(tx, request)
 	if err != nil {
 		c.Log.With(zap.Error(err)).Error("error getting contacts")
-		return nil, 0, echo.ErrInternalServerError
+		if errors.Is(err, repository.ErrInvalidPageRequest) {
+			return nil, nil, apperror.Invalid(err.Error())
+		}
+		return nil, nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.With(zap.Error(err)).Error("error getting contacts")
-		return nil, 0, echo.ErrInternalServerError
+		return nil, nil, apperror.ErrInternal
 	}
 
 	responses := make([]dto.ContactResponse, len(contacts))
@@ -171,5 +513,546 @@ func (c *ContactUseCase) Search(ctx context.Context, request *dto.SearchContactR
 		responses[i] = *converter.ContactToResponse(&contact)
 	}
 
-	return responses, total, nil
+	return responses, toPageMetadata(page, repository.NewPageRequest(request)), nil
+}
+
+// Export hands every matching contact, with addresses and tags, to write in
+// batches. It reads inside one repeatable read transaction so the batches
+// are one consistent snapshot; an error from write aborts the export and is
+// returned as is.
+func (c *ContactUseCase) Export(ctx context.Context, request *dto.ExportContactRequest, write func(*dto.ContactResponse) error) error {
+	tx := c.DB.WithContext(ctx).Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return apperror.Validation(err)
+	}
+
+	filter := &dto.SearchContactRequest{
+		UserId:   request.UserId,
+		Query:    request.Query,
+		Name:     request.Name,
+		Email:    request.Email,
+		Phone:    request.Phone,
+		Tags:     request.Tags,
+		TagMode:  request.TagMode,
+		Near:     request.Near,
+		RadiusKm: request.RadiusKm,
+	}
+	filter.PhonePrefix = canonical.PhonePrefix(filter.Phone, c.DefaultRegion)
+
+	filters, err := c.customFieldFilters(tx, request.UserId, request.CustomFields)
+	if err != nil {
+		return err
+	}
+	filter.CustomFieldFilters = filters
+
+	var writeErr error
+	err = c.ContactRepository.Export(tx, filter, contactExportBatchSize, func(contacts []entity.Contact) error {
+		for i := range contacts {
+			if writeErr = write(converter.ContactToResponse(&contacts[i])); writeErr != nil {
+				return writeErr
+			}
+		}
+		return nil
+	})
+	if writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error exporting contacts")
+		return apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error exporting contacts")
+		return apperror.ErrInternal
+	}
+
+	return nil
+}
+
+// Upcoming lists the dates of the user's own contacts that come round from
+// today up to the requested number of days ahead, soonest first.
+func (c *ContactUseCase) Upcoming(ctx context.Context, request *dto.UpcomingDateRequest) ([]dto.UpcomingDateResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	from := startOfDay(time.Now())
+	contacts, err := c.ContactRepository.FindAllWithDatesBetween(tx.Scopes(c.ContactRepository.PreloadTags),
+		request.UserId, from, from.AddDate(0, 0, request.Days))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting upcoming dates")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting upcoming dates")
+		return nil, apperror.ErrInternal
+	}
+
+	upcoming := upcomingDates(contacts, from, request.Days)
+	responses := make([]dto.UpcomingDateResponse, len(upcoming))
+	for i, item := range upcoming {
+		responses[i] = upcomingDateToResponse(item)
+	}
+
+	return responses, nil
+}
+
+func (c *ContactUseCase) FindDuplicates(ctx context.Context, request *dto.FindDuplicateContactRequest) ([]dto.DuplicateGroupResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	pairs, err := c.ContactRepository.FindDuplicatePairs(tx, request.UserId, duplicateNameThreshold, maxDuplicatePairs)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error finding duplicate contacts")
+		return nil, apperror.ErrInternal
+	}
+	if len(pairs) == maxDuplicatePairs {
+		c.Log.Warn("duplicate pairs capped", zap.String("user_id", request.UserId), zap.Int("pairs", len(pairs)))
+	}
+
+	groups := clusterDuplicates(pairs, request.MinScore)
+
+	var ids []string
+	for _, group := range groups {
+		ids = append(ids, group.ContactIds...)
+	}
+
+	contacts := map[string]*entity.Contact{}
+	if len(ids) > 0 {
+		found, err := c.ContactRepository.FindAllByIdsAndUserId(tx.Scopes(c.ContactRepository.PreloadTags), ids, request.UserId)
+		if err != nil {
+			c.Log.With(zap.Error(err)).Error("error getting duplicate contacts")
+			return nil, apperror.ErrInternal
+		}
+		for i := range found {
+			contacts[found[i].ID] = &found[i]
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error finding duplicate contacts")
+		return nil, apperror.ErrInternal
+	}
+
+	responses := make([]dto.DuplicateGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		response := dto.DuplicateGroupResponse{Score: group.Score, Reasons: group.Reasons}
+		for _, id := range group.ContactIds {
+			if contact, ok := contacts[id]; ok {
+				response.Contacts = append(response.Contacts, *converter.ContactToResponse(contact))
+			}
+		}
+		// Oldest first, as it is the natural merge target.
+		slices.SortStableFunc(response.Contacts, func(a, b dto.ContactResponse) int {
+			return cmp.Compare(a.CreatedAt, b.CreatedAt)
+		})
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+// Merge folds the source contacts into the target in one transaction: the
+// target takes the chosen field values, their addresses, notes and tags, and
+// the sources are then deleted for good.
+func (c *ContactUseCase) Merge(ctx context.Context, request *dto.MergeContactRequest) (*dto.ContactResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	ids, err := mergeContactIds(request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error validating merged contacts")
+		return nil, err
+	}
+
+	contacts, err := c.ContactRepository.FindAllByIdsAndUserIdForUpdate(tx, ids, request.UserId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contacts")
+		return nil, apperror.ErrInternal
+	}
+	if len(contacts) != len(ids) {
+		c.Log.Warn("merge references unknown contacts", zap.Strings("ids", ids))
+		return nil, apperror.ErrNotFound
+	}
+
+	byId := map[string]*entity.Contact{}
+	for i := range contacts {
+		byId[contacts[i].ID] = &contacts[i]
+	}
+
+	for field, id := range request.Fields {
+		if _, ok := byId[id]; !ok {
+			return nil, apperror.Invalid("fields." + field + " must reference one of the merged contacts")
+		}
+	}
+
+	target := byId[request.TargetId]
+	before := contactSnapshot(target)
+	pick := func(field string, value func(*entity.Contact) string) string {
+		if id, ok := request.Fields[field]; ok {
+			return value(byId[id])
+		}
+		if v := value(target); v != "" {
+			return v
+		}
+		for _, id := range request.SourceIds {
+			if v := value(byId[id]); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	target.FirstName = pick("first_name", func(c *entity.Contact) string { return c.FirstName })
+	target.LastName = pick("last_name", func(c *entity.Contact) string { return c.LastName })
+	target.Email = pick("email", func(c *entity.Contact) string { return c.Email })
+	// The stored E.164 form travels with the phone it was derived from, so
+	// the phone is picked by contact rather than by value.
+	phoneSource := byId[pick("phone", func(c *entity.Contact) string { return c.ID })]
+	if _, chosen := request.Fields["phone"]; !chosen && target.Phone == "" {
+		for _, id := range request.SourceIds {
+			if byId[id].Phone != "" {
+				phoneSource = byId[id]
+				break
+			}
+		}
+	}
+	target.Phone, target.PhoneE164 = phoneSource.Phone, phoneSource.PhoneE164
+
+	// Custom fields the target leaves empty are filled from the sources in
+	// the order given.
+	customFields := maps.Clone(target.CustomFields)
+	if customFields == nil {
+		customFields = map[string]any{}
+	}
+	for _, id := range request.SourceIds {
+		for name, value := range byId[id].CustomFields {
+			if _, ok := customFields[name]; !ok {
+				customFields[name] = value
+			}
+		}
+	}
+	target.CustomFields = customFields
+
+	// Likewise for dates: a birthday, say, is only taken from a source when
+	// the target has none.
+	dates := slices.Clone(target.Dates)
+	for _, id := range request.SourceIds {
+		for _, date := range byId[id].Dates {
+			if !slices.ContainsFunc(dates, func(d entity.ContactDate) bool { return d.Key() == date.Key() }) {
+				dates = append(dates, date)
+			}
+		}
+	}
+	target.Dates = dates
+
+	// The target keeps its own photo, or takes the first one among the
+	// sources; the photos left over go with the deleted sources.
+	var leftoverPhotos []string
+	for _, id := range request.SourceIds {
+		source := byId[id]
+		if source.PhotoId == "" {
+			continue
+		}
+		if target.PhotoId == "" {
+			target.PhotoId, target.PhotoContentType = source.PhotoId, source.PhotoContentType
+			continue
+		}
+		leftoverPhotos = append(leftoverPhotos, source.PhotoId)
+	}
+	target.Version++
+
+	if err := c.ContactRepository.UpdateVersioned(tx, target, target.Version-1); err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating contact")
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, errVersionMismatch
+		}
+		return nil, apperror.ErrInternal
+	}
+
+	// The target keeps its primary address, or takes the one of the first
+	// source that has one; the other sources' stop being primary before
+	// they move, as a contact can only have one.
+	contactIds := append([]string{target.ID}, request.SourceIds...)
+	primaries, err := c.AddressRepository.FindAllPrimaryByContactIds(tx, contactIds)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting primary addresses")
+		return nil, apperror.ErrInternal
+	}
+	keepPrimary := ""
+	for _, id := range contactIds {
+		if i := slices.IndexFunc(primaries, func(a entity.Address) bool { return a.ContactId == id }); i >= 0 {
+			keepPrimary = primaries[i].ID
+			break
+		}
+	}
+	if err := c.AddressRepository.DemotePrimary(tx, request.SourceIds, keepPrimary); err != nil {
+		c.Log.With(zap.Error(err)).Error("error demoting primary addresses")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.AddressRepository.Reparent(tx, request.SourceIds, target.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("error moving addresses")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.MergeTags(tx, target.ID, request.SourceIds); err != nil {
+		c.Log.With(zap.Error(err)).Error("error merging contact tags")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.NoteRepository.Reparent(tx, request.SourceIds, target.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("error moving notes")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.RefreshLastInteraction(tx, target); err != nil {
+		c.Log.With(zap.Error(err)).Error("error refreshing last interaction")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.HistoryRepository.ReparentAddresses(tx, request.SourceIds, target.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("error moving address history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.recordContactHistory(ctx, tx, target, entity.HistoryUpdate, request.UserId, before, contactSnapshot(target)); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.DeletePermanently(tx, request.SourceIds); err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting merged contacts")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.LoadTags(tx, target); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact tags")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.LoadAddresses(tx, target); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact addresses")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error merging contacts")
+		return nil, apperror.ErrInternal
+	}
+
+	deletePhotoBlobs(ctx, c.BlobStore, c.Log, leftoverPhotos...)
+
+	return converter.ContactToResponse(target), nil
+}
+
+func (c *ContactUseCase) History(ctx context.Context, request *dto.ListContactHistoryRequest) ([]dto.ContactHistoryResponse, *dto.PageMetadata, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionRead); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact")
+		return nil, nil, apperror.ErrNotFound
+	}
+
+	entries, page, err := c.HistoryRepository.Search(tx, request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact history")
+		if errors.Is(err, repository.ErrInvalidPageRequest) {
+			return nil, nil, apperror.Invalid(err.Error())
+		}
+		return nil, nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact history")
+		return nil, nil, apperror.ErrInternal
+	}
+
+	responses := make([]dto.ContactHistoryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = *converter.ContactHistoryToResponse(&entry)
+	}
+
+	return responses, toPageMetadata(page, repository.NewPageRequest(request)), nil
+}
+
+// Revert puts the contact's fields back to what they were at the given
+// version. It is itself a change: the contact moves to a new version and the
+// revert is recorded in its history. Addresses keep their own history and are
+// left as they are.
+func (c *ContactUseCase) Revert(ctx context.Context, request *dto.RevertContactRequest) (*dto.ContactResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := checkVersion(request.IfMatch, contact.Version); err != nil {
+		c.Log.Warn("contact version does not match", zap.Int64("version", contact.Version))
+		return nil, err
+	}
+
+	history := new(entity.ContactHistory)
+	if err := c.HistoryRepository.FindContactVersion(tx, history, contact.ID, request.Version); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact version")
+		return nil, apperror.NotFound("version not found in contact history")
+	}
+
+	before := contactSnapshot(contact)
+	contact.FirstName = snapshotString(history.Snapshot, "first_name")
+	contact.LastName = snapshotString(history.Snapshot, "last_name")
+	contact.Email = snapshotString(history.Snapshot, "email")
+	contact.Phone = snapshotString(history.Snapshot, "phone")
+	contact.PhoneE164 = snapshotString(history.Snapshot, "phone_e164")
+	// Entries written before custom fields or dates existed leave them as
+	// they are. Restored custom fields are held to today's definitions, which
+	// may have dropped a field or made one required since.
+	if customFields, ok := history.Snapshot["custom_fields"].(map[string]any); ok {
+		customFields, err := c.customFieldValues(tx, contact.UserId, customFields)
+		if err != nil {
+			return nil, err
+		}
+		contact.CustomFields = customFields
+	}
+	if dates, ok := snapshotDates(history.Snapshot); ok {
+		contact.Dates = dates
+	}
+	contact.Version++
+
+	if err := c.ContactRepository.UpdateVersioned(tx, contact, contact.Version-1); err != nil {
+		c.Log.With(zap.Error(err)).Error("error reverting contact")
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, errVersionMismatch
+		}
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.recordContactHistory(ctx, tx, contact, entity.HistoryRevert, request.UserId, before, contactSnapshot(contact)); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.LoadTags(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contact tags")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error reverting contact")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.ContactToResponse(contact), nil
+}
+
+func (c *ContactUseCase) recordContactHistory(ctx context.Context, tx *gorm.DB, contact *entity.Contact, action string,
+	actorId string, before map[string]any, after map[string]any) error {
+	return recordHistory(ctx, tx, c.HistoryRepository, &entity.ContactHistory{
+		ContactId:  contact.ID,
+		EntityType: entity.HistoryContact,
+		EntityId:   contact.ID,
+		Action:     action,
+		Version:    contact.Version,
+		ActorId:    actorId,
+	}, before, after)
+}
+
+// customFieldValues validates custom field values against the user's
+// definitions, see the package level customFieldValues.
+func (c *ContactUseCase) customFieldValues(tx *gorm.DB, userId string, values map[string]any) (map[string]any, error) {
+	definitions, err := c.CustomFieldRepository.FindAllByUserId(tx, userId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom fields")
+		return nil, apperror.ErrInternal
+	}
+
+	customFields, err := customFieldValues(definitions, values)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error validating custom fields")
+		return nil, apperror.Invalid(err.Error())
+	}
+	return customFields, nil
+}
+
+func (c *ContactUseCase) contactDates(requests []dto.ContactDateRequest) ([]entity.ContactDate, error) {
+	dates, err := contactDates(requests)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error validating contact dates")
+		return nil, apperror.Invalid(err.Error())
+	}
+	return dates, nil
+}
+
+func (c *ContactUseCase) customFieldFilters(tx *gorm.DB, userId string, params map[string]string) ([]dto.CustomFieldFilter, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	definitions, err := c.CustomFieldRepository.FindAllByUserId(tx, userId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom fields")
+		return nil, apperror.ErrInternal
+	}
+
+	filters, err := customFieldFilters(definitions, params)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error parsing custom field filters")
+		return nil, apperror.Invalid(err.Error())
+	}
+	return filters, nil
+}
+
+// canonicalContactDetails returns the E.164 form of phone and the canonical
+// email, or a 400 naming the offending field.
+func canonicalContactDetails(phone string, email string, region string) (string, string, error) {
+	phoneE164, err := canonical.Phone(phone, region)
+	if err != nil {
+		return "", "", apperror.Invalid("phone: " + err.Error())
+	}
+	return phoneE164, canonical.Email(email), nil
+}
+
+// phoneRegion reads national phone numbers in the first of the contact's
+// address countries that is known, falling back to the default region.
+func phoneRegion(defaultRegion string, countries ...string) string {
+	for _, country := range countries {
+		if region := canonical.Region(country); region != "" {
+			return region
+		}
+	}
+	return defaultRegion
 }
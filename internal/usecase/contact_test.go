@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+func TestCheckOwner(t *testing.T) {
+	contact := &entity.Contact{ID: "c1", UserId: "ada"}
+
+	if err := checkOwner(contact, "ada"); err != nil {
+		t.Errorf("checkOwner() for the owner error = %v", err)
+	}
+
+	// A grantee finds the contact through its share, but even write
+	// permission does not let them trash it or change its tags.
+	err := checkOwner(contact, "grace")
+	if appErr, ok := apperror.As(err); !ok || appErr.Kind != apperror.KindForbidden || !errors.Is(err, errNotOwner) {
+		t.Errorf("checkOwner() for a grantee error = %v, want %v", err, errNotOwner)
+	}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/postal"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CountryUseCase keeps the countries of stored addresses in the form new
+// addresses are written in.
+type CountryUseCase struct {
+	DB                *gorm.DB
+	Log               *zap.Logger
+	AddressRepository *repository.AddressRepository
+	HistoryRepository *repository.ContactHistoryRepository
+}
+
+func NewCountryUseCase(db *gorm.DB, logger *zap.Logger, addressRepository *repository.AddressRepository,
+	historyRepository *repository.ContactHistoryRepository) *CountryUseCase {
+	return &CountryUseCase{
+		DB:                db,
+		Log:               logger,
+		AddressRepository: addressRepository,
+		HistoryRepository: historyRepository,
+	}
+}
+
+// Normalize replaces every stored country that postal.Code knows by its
+// ISO 3166-1 alpha-2 code, so lower case codes, aliases such as "UK" and
+// English names all become the code. Countries it does not know are left for
+// users to correct on the address's next update. Every changed address gets
+// a history entry by the system actor. Running it again changes nothing.
+func (c *CountryUseCase) Normalize(ctx context.Context) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	countries, err := c.AddressRepository.FindAllCountries(tx)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting address countries")
+		return err
+	}
+
+	var changed int64
+	for _, country := range countries {
+		code := postal.Code(country)
+		if code == "" || code == country {
+			continue
+		}
+
+		addresses, err := c.AddressRepository.FindAllByCountryForUpdate(tx, country)
+		if err != nil {
+			c.Log.With(zap.Error(err)).Error("error getting addresses by country", zap.String("country", country))
+			return err
+		}
+
+		count, err := c.AddressRepository.ReplaceCountry(tx, country, code)
+		if err != nil {
+			c.Log.With(zap.Error(err)).Error("error normalizing address country", zap.String("country", country))
+			return err
+		}
+		changed += count
+
+		for i := range addresses {
+			if err := c.recordReplacedCountry(ctx, tx, &addresses[i], code); err != nil {
+				c.Log.With(zap.Error(err)).Error("error recording address history")
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error normalizing address countries")
+		return err
+	}
+
+	c.Log.Info("normalized address countries", zap.Int64("addresses", changed))
+	return nil
+}
+
+// recordReplacedCountry records the update ReplaceCountry made to address,
+// which holds the row as it was before.
+func (c *CountryUseCase) recordReplacedCountry(ctx context.Context, tx *gorm.DB, address *entity.Address, code string) error {
+	before := addressSnapshot(address)
+	address.Country = code
+	address.Version++
+
+	return recordHistory(ctx, tx, c.HistoryRepository, &entity.ContactHistory{
+		ContactId:  address.ContactId,
+		EntityType: entity.HistoryAddress,
+		EntityId:   address.ID,
+		Action:     entity.HistoryUpdate,
+		Version:    address.Version,
+		ActorId:    entity.HistorySystemActor,
+	}, before, addressSnapshot(address))
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+const (
+	customFieldDateLayout = "2006-01-02"
+	customFieldMaxText    = 1000
+)
+
+// customFieldValues checks contact custom field values against the user's
+// definitions and returns them in stored form. Null values are dropped, so
+// sending null clears a field.
+func customFieldValues(definitions []entity.CustomField, values map[string]any) (map[string]any, error) {
+	byName := make(map[string]*entity.CustomField, len(definitions))
+	for i := range definitions {
+		byName[definitions[i].Name] = &definitions[i]
+	}
+
+	stored := make(map[string]any, len(values))
+	for name, value := range values {
+		definition, ok := byName[name]
+		if !ok {
+			return nil, customFieldError(name, "is not a defined custom field")
+		}
+		if value == nil {
+			continue
+		}
+
+		value, err := customFieldValue(definition, value)
+		if err != nil {
+			return nil, err
+		}
+		stored[name] = value
+	}
+
+	for _, definition := range definitions {
+		if _, ok := stored[definition.Name]; definition.Required && !ok {
+			return nil, customFieldError(definition.Name, "is required")
+		}
+	}
+
+	return stored, nil
+}
+
+func customFieldValue(definition *entity.CustomField, value any) (any, error) {
+	if definition.Type == entity.CustomFieldNumber {
+		number, ok := value.(float64)
+		if !ok {
+			return nil, customFieldError(definition.Name, "must be a number")
+		}
+		return number, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, customFieldError(definition.Name, "must be a string")
+	}
+
+	switch definition.Type {
+	case entity.CustomFieldDate:
+		if _, err := time.Parse(customFieldDateLayout, text); err != nil {
+			return nil, customFieldError(definition.Name, "must be a date in YYYY-MM-DD form")
+		}
+	case entity.CustomFieldURL:
+		parsed, err := url.Parse(text)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, customFieldError(definition.Name, "must be an http or https URL")
+		}
+		if len(text) > 2000 {
+			return nil, customFieldError(definition.Name, "must be at most 2000 characters")
+		}
+	case entity.CustomFieldEnum:
+		if !slices.Contains(definition.Options, text) {
+			return nil, customFieldError(definition.Name, "must be one of the field's options")
+		}
+	default:
+		if utf8.RuneCountInString(text) > customFieldMaxText {
+			return nil, customFieldError(definition.Name, fmt.Sprintf("must be at most %d characters", customFieldMaxText))
+		}
+	}
+
+	return text, nil
+}
+
+// customFieldFilters turns custom field search parameters into filters. Text
+// and URL fields match substrings; the other types match exact values.
+func customFieldFilters(definitions []entity.CustomField, params map[string]string) ([]dto.CustomFieldFilter, error) {
+	var filters []dto.CustomFieldFilter
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		param := params[name]
+		index := slices.IndexFunc(definitions, func(definition entity.CustomField) bool { return definition.Name == name })
+		if index < 0 {
+			return nil, customFieldError(name, "is not a defined custom field")
+		}
+
+		filter := dto.CustomFieldFilter{Name: name, Value: param}
+		switch definitions[index].Type {
+		case entity.CustomFieldText, entity.CustomFieldURL:
+			filter.Partial = true
+		case entity.CustomFieldNumber:
+			number, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, customFieldError(name, "must be a number")
+			}
+			filter.Value = number
+		case entity.CustomFieldDate:
+			if _, err := time.Parse(customFieldDateLayout, param); err != nil {
+				return nil, customFieldError(name, "must be a date in YYYY-MM-DD form")
+			}
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func customFieldError(name string, message string) error {
+	return errors.New("custom_fields." + name + ": " + message)
+}
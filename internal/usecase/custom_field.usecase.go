@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"maps"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CustomFieldUseCase struct {
+	DB                    *gorm.DB
+	Log                   *zap.Logger
+	Validate              *validator.Validate
+	CustomFieldRepository *repository.CustomFieldRepository
+	ContactRepository     *repository.ContactRepository
+	HistoryRepository     *repository.ContactHistoryRepository
+}
+
+func NewCustomFieldUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
+	customFieldRepository *repository.CustomFieldRepository, contactRepository *repository.ContactRepository,
+	historyRepository *repository.ContactHistoryRepository) *CustomFieldUseCase {
+	return &CustomFieldUseCase{
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		CustomFieldRepository: customFieldRepository,
+		ContactRepository:     contactRepository,
+		HistoryRepository:     historyRepository,
+	}
+}
+
+func (c *CustomFieldUseCase) Create(ctx context.Context, request *dto.CreateCustomFieldRequest) (*dto.CustomFieldResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	total, err := c.CustomFieldRepository.CountByNameAndUserId(tx, request.Name, request.UserId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error counting custom fields")
+		return nil, apperror.ErrInternal
+	}
+
+	if total > 0 {
+		c.Log.Warn("custom field already exists")
+		return nil, apperror.ErrConflict
+	}
+
+	field := &entity.CustomField{
+		ID:       uuid.NewString(),
+		UserId:   request.UserId,
+		Name:     request.Name,
+		Type:     request.Type,
+		Required: request.Required,
+		Options:  request.Options,
+	}
+	if field.Options == nil {
+		field.Options = []string{}
+	}
+
+	if err := c.CustomFieldRepository.Create(tx, field); err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating custom field")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating custom field")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.CustomFieldToResponse(field), nil
+}
+
+// Update changes whether the field is required and, for enum fields, its
+// options. Values already stored on contacts are checked again only when
+// those contacts are next written.
+func (c *CustomFieldUseCase) Update(ctx context.Context, request *dto.UpdateCustomFieldRequest) (*dto.CustomFieldResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	field := new(entity.CustomField)
+	if err := c.CustomFieldRepository.FindByIdAndUserId(tx, field, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom field")
+		return nil, apperror.ErrNotFound
+	}
+
+	if field.Type == entity.CustomFieldEnum && len(request.Options) == 0 {
+		return nil, apperror.Invalid("options: an enum field needs at least one option")
+	}
+	if field.Type != entity.CustomFieldEnum && len(request.Options) > 0 {
+		return nil, apperror.Invalid("options: only enum fields have options")
+	}
+
+	field.Required = request.Required
+	if field.Type == entity.CustomFieldEnum {
+		field.Options = request.Options
+	}
+
+	if err := c.CustomFieldRepository.Update(tx, field); err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating custom field")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating custom field")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.CustomFieldToResponse(field), nil
+}
+
+func (c *CustomFieldUseCase) Get(ctx context.Context, request *dto.GetCustomFieldRequest) (*dto.CustomFieldResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	field := new(entity.CustomField)
+	if err := c.CustomFieldRepository.FindByIdAndUserId(tx, field, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom field")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom field")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.CustomFieldToResponse(field), nil
+}
+
+// Delete removes the definition together with its values on every contact
+// of the user, trashed ones included.
+func (c *CustomFieldUseCase) Delete(ctx context.Context, request *dto.DeleteCustomFieldRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return apperror.Validation(err)
+	}
+
+	field := new(entity.CustomField)
+	if err := c.CustomFieldRepository.FindByIdAndUserId(tx, field, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom field")
+		return apperror.ErrNotFound
+	}
+
+	contacts, err := c.ContactRepository.FindAllWithCustomFieldForUpdate(tx, request.UserId, field.Name)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting contacts with custom field")
+		return apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.RemoveCustomField(tx, request.UserId, field.Name); err != nil {
+		c.Log.With(zap.Error(err)).Error("error removing custom field values")
+		return apperror.ErrInternal
+	}
+
+	if err := recordContactUpdates(ctx, tx, c.HistoryRepository, contacts, request.UserId, contactSnapshot,
+		func(contact *entity.Contact) {
+			contact.CustomFields = maps.Clone(contact.CustomFields)
+			delete(contact.CustomFields, field.Name)
+		}); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return apperror.ErrInternal
+	}
+
+	if err := c.CustomFieldRepository.Delete(tx, field); err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting custom field")
+		return apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting custom field")
+		return apperror.ErrInternal
+	}
+
+	return nil
+}
+
+func (c *CustomFieldUseCase) List(ctx context.Context, request *dto.ListCustomFieldRequest) ([]dto.CustomFieldResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	fields, err := c.CustomFieldRepository.FindAllByUserId(tx, request.UserId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom fields")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom fields")
+		return nil, apperror.ErrInternal
+	}
+
+	responses := make([]dto.CustomFieldResponse, len(fields))
+	for i, field := range fields {
+		responses[i] = *converter.CustomFieldToResponse(&field)
+	}
+
+	return responses, nil
+}
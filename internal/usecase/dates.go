@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+const (
+	contactDateLayout = "2006-01-02"
+	// contactDateLeapYear fills in the year of a --MM-DD date while parsing,
+	// so that Feb 29 is accepted.
+	contactDateLeapYear = "2000-"
+)
+
+// contactDates parses the dates sent for a contact into stored form.
+func contactDates(requests []dto.ContactDateRequest) ([]entity.ContactDate, error) {
+	dates := make([]entity.ContactDate, 0, len(requests))
+	seen := map[string]bool{}
+	for i, request := range requests {
+		date, err := parseContactDate(request)
+		if err != nil {
+			return nil, fmt.Errorf("dates[%d].date: %w", i, err)
+		}
+		if seen[date.Key()] {
+			return nil, fmt.Errorf("dates[%d]: %s appears more than once", i, date.Key())
+		}
+		seen[date.Key()] = true
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+func parseContactDate(request dto.ContactDateRequest) (entity.ContactDate, error) {
+	date := entity.ContactDate{Type: request.Type, Label: strings.TrimSpace(request.Label)}
+
+	if monthDay, ok := strings.CutPrefix(request.Date, "--"); ok {
+		parsed, err := time.Parse(contactDateLayout, contactDateLeapYear+monthDay)
+		if err != nil {
+			return date, errors.New("must be a date in YYYY-MM-DD or --MM-DD form")
+		}
+		date.Month, date.Day = int(parsed.Month()), parsed.Day()
+		return date, nil
+	}
+
+	parsed, err := time.Parse(contactDateLayout, request.Date)
+	if err != nil || parsed.Year() < 1 {
+		return date, errors.New("must be a date in YYYY-MM-DD or --MM-DD form")
+	}
+	date.Year, date.Month, date.Day = parsed.Year(), int(parsed.Month()), parsed.Day()
+	return date, nil
+}
+
+func contactDateRequests(dates []entity.ContactDate) []dto.ContactDateRequest {
+	requests := make([]dto.ContactDateRequest, len(dates))
+	for i, date := range dates {
+		requests[i] = dto.ContactDateRequest(converter.ContactDateToResponse(date))
+	}
+	return requests
+}
+
+// contactDateName is the date's title for people, such as "Birthday" or the
+// label of a custom date.
+func contactDateName(date entity.ContactDate) string {
+	switch date.Type {
+	case entity.DateTypeBirthday:
+		return "Birthday"
+	case entity.DateTypeAnniversary:
+		return "Anniversary"
+	default:
+		return date.Label
+	}
+}
+
+// contactDateSummary titles the date in the calendar feed and in reminders,
+// as in "Birthday: Ada Lovelace".
+func contactDateSummary(contact *entity.Contact, date entity.ContactDate) string {
+	return contactDateName(date) + ": " + contactName(contact)
+}
+
+func contactName(contact *entity.Contact) string {
+	return strings.TrimSpace(contact.FirstName + " " + contact.LastName)
+}
+
+// startOfDay is the calendar day of t as a UTC midnight, so that days can be
+// counted without daylight saving shifts.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// occurrenceIn is the day the date falls on in the given year. Feb 29 falls
+// on Feb 28 in common years.
+func occurrenceIn(date entity.ContactDate, year int) time.Time {
+	day := date.Day
+	if date.Month == 2 && day == 29 && !isLeapYear(year) {
+		day = 28
+	}
+	return time.Date(year, time.Month(date.Month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// nextOccurrence is the first day on or after from that the date falls on;
+// from must be a startOfDay.
+func nextOccurrence(date entity.ContactDate, from time.Time) time.Time {
+	if occurrence := occurrenceIn(date, from.Year()); !occurrence.Before(from) {
+		return occurrence
+	}
+	return occurrenceIn(date, from.Year()+1)
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+type upcomingDate struct {
+	Contact   *entity.Contact
+	Date      entity.ContactDate
+	OccursOn  time.Time
+	DaysUntil int
+	// Years is how many years the date has come round at OccursOn, or 0
+	// when its year is not known.
+	Years int
+}
+
+// upcomingDates lists the contacts' dates that occur from the day from up to
+// and including days days later, soonest first.
+func upcomingDates(contacts []entity.Contact, from time.Time, days int) []upcomingDate {
+	var upcoming []upcomingDate
+	for i := range contacts {
+		for _, date := range contacts[i].Dates {
+			occursOn := nextOccurrence(date, from)
+			daysUntil := int(occursOn.Sub(from) / (24 * time.Hour))
+			if daysUntil > days {
+				continue
+			}
+
+			item := upcomingDate{Contact: &contacts[i], Date: date, OccursOn: occursOn, DaysUntil: daysUntil}
+			if date.Year != 0 && occursOn.Year() > date.Year {
+				item.Years = occursOn.Year() - date.Year
+			}
+			upcoming = append(upcoming, item)
+		}
+	}
+
+	slices.SortFunc(upcoming, func(a, b upcomingDate) int {
+		return cmp.Or(
+			a.OccursOn.Compare(b.OccursOn),
+			cmp.Compare(strings.ToLower(contactName(a.Contact)), strings.ToLower(contactName(b.Contact))),
+			cmp.Compare(a.Contact.ID, b.Contact.ID),
+			cmp.Compare(a.Date.Key(), b.Date.Key()),
+		)
+	})
+	return upcoming
+}
+
+func upcomingDateToResponse(upcoming upcomingDate) dto.UpcomingDateResponse {
+	date := converter.ContactDateToResponse(upcoming.Date)
+	return dto.UpcomingDateResponse{
+		Contact:   *converter.ContactToResponse(upcoming.Contact),
+		Type:      date.Type,
+		Label:     date.Label,
+		Date:      date.Date,
+		OccursOn:  upcoming.OccursOn.Format(contactDateLayout),
+		DaysUntil: upcoming.DaysUntil,
+		Years:     upcoming.Years,
+	}
+}
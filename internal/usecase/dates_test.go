@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+)
+
+func day(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNextOccurrence(t *testing.T) {
+	leapDay := entity.ContactDate{Type: entity.DateTypeBirthday, Year: 2000, Month: 2, Day: 29}
+	newYear := entity.ContactDate{Type: entity.DateTypeBirthday, Month: 1, Day: 1}
+
+	tests := []struct {
+		name string
+		date entity.ContactDate
+		from time.Time
+		want time.Time
+	}{
+		{name: "later this year", date: newYear, from: day(2024, time.January, 1), want: day(2024, time.January, 1)},
+		{name: "wraps into next year", date: newYear, from: day(2025, time.December, 31), want: day(2026, time.January, 1)},
+		{name: "Feb 29 in a leap year", date: leapDay, from: day(2028, time.February, 1), want: day(2028, time.February, 29)},
+		{name: "Feb 29 falls on Feb 28 in a common year", date: leapDay, from: day(2025, time.February, 1), want: day(2025, time.February, 28)},
+		{name: "Feb 29 on its Feb 28", date: leapDay, from: day(2025, time.February, 28), want: day(2025, time.February, 28)},
+		{name: "Feb 29 passed in a common year", date: leapDay, from: day(2025, time.March, 1), want: day(2026, time.February, 28)},
+		{name: "Feb 29 passed before a leap year", date: leapDay, from: day(2027, time.March, 1), want: day(2028, time.February, 29)},
+		{name: "Feb 29 in a century that is not a leap year", date: leapDay, from: day(2100, time.January, 1), want: day(2100, time.February, 28)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextOccurrence(tt.date, tt.from); !got.Equal(tt.want) {
+				t.Errorf("nextOccurrence() = %s, want %s", got.Format(contactDateLayout), tt.want.Format(contactDateLayout))
+			}
+		})
+	}
+}
+
+func TestUpcomingDates(t *testing.T) {
+	ada := entity.Contact{ID: "1", FirstName: "Ada", Dates: []entity.ContactDate{
+		{Type: entity.DateTypeBirthday, Year: 1990, Month: 1, Day: 5},
+		{Type: entity.DateTypeAnniversary, Month: 12, Day: 19},
+	}}
+	grace := entity.Contact{ID: "2", FirstName: "Grace", Dates: []entity.ContactDate{
+		{Type: entity.DateTypeBirthday, Year: 2000, Month: 2, Day: 29},
+		{Type: entity.DateTypeCustom, Label: "Christmas", Month: 12, Day: 25},
+	}}
+	contacts := []entity.Contact{ada, grace}
+
+	type occurrence struct {
+		contactId string
+		dateType  string
+		occursOn  string
+		daysUntil int
+		years     int
+	}
+	tests := []struct {
+		name string
+		from time.Time
+		days int
+		want []occurrence
+	}{
+		{
+			name: "across the new year",
+			from: day(2025, time.December, 20),
+			days: 30,
+			want: []occurrence{
+				{"2", entity.DateTypeCustom, "2025-12-25", 5, 0},
+				{"1", entity.DateTypeBirthday, "2026-01-05", 16, 36},
+			},
+		},
+		{
+			name: "on the first day of the window",
+			from: day(2025, time.December, 19),
+			days: 0,
+			want: []occurrence{
+				{"1", entity.DateTypeAnniversary, "2025-12-19", 0, 0},
+			},
+		},
+		{
+			name: "Feb 29 on Feb 28 of a common year",
+			from: day(2025, time.February, 20),
+			days: 8,
+			want: []occurrence{
+				{"2", entity.DateTypeBirthday, "2025-02-28", 8, 25},
+			},
+		},
+		{
+			name: "Feb 29 in a leap year",
+			from: day(2028, time.February, 20),
+			days: 8,
+			want: nil,
+		},
+		{
+			name: "Feb 29 at the end of a leap year window",
+			from: day(2028, time.February, 20),
+			days: 9,
+			want: []occurrence{
+				{"2", entity.DateTypeBirthday, "2028-02-29", 9, 28},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []occurrence
+			for _, item := range upcomingDates(contacts, tt.from, tt.days) {
+				got = append(got, occurrence{item.Contact.ID, item.Date.Type, item.OccursOn.Format(contactDateLayout),
+					item.DaysUntil, item.Years})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("upcomingDates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
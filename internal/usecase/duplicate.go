@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+)
+
+// Weights of each duplicate signal. They combine as independent evidence,
+// so two weak signals together outrank either one alone.
+const (
+	duplicateEmailWeight = 0.9
+	duplicatePhoneWeight = 0.8
+	duplicateNameWeight  = 0.7
+
+	// duplicateNameThreshold is the trigram similarity from which two full
+	// names count as a signal at all.
+	duplicateNameThreshold = 0.5
+)
+
+// maxDuplicatePairs caps the pairs clustered into duplicate groups, so that an
+// address book full of look-alikes cannot make finding them unbounded.
+const maxDuplicatePairs = 5000
+
+type duplicateGroup struct {
+	ContactIds []string
+	Score      float64
+	Reasons    []string
+}
+
+func duplicatePairScore(pair repository.DuplicatePair) (float64, []string) {
+	missing := 1.0
+	var reasons []string
+
+	if pair.EmailMatch {
+		missing *= 1 - duplicateEmailWeight
+		reasons = append(reasons, "email")
+	}
+	if pair.PhoneMatch {
+		missing *= 1 - duplicatePhoneWeight
+		reasons = append(reasons, "phone")
+	}
+	if pair.NameSimilarity >= duplicateNameThreshold {
+		missing *= 1 - duplicateNameWeight*pair.NameSimilarity
+		reasons = append(reasons, "name")
+	}
+
+	return math.Round((1-missing)*1000) / 1000, reasons
+}
+
+// clusterDuplicates joins pairs scoring at least minScore into connected
+// groups. A group scores as its strongest pair and lists every reason seen.
+func clusterDuplicates(pairs []repository.DuplicatePair, minScore float64) []duplicateGroup {
+	parent := map[string]string{}
+	var find func(id string) string
+	find = func(id string) string {
+		if parent[id] == id {
+			return id
+		}
+		parent[id] = find(parent[id])
+		return parent[id]
+	}
+
+	scores := map[string]float64{}
+	reasons := map[string]map[string]bool{}
+	type scoredPair struct {
+		pair    repository.DuplicatePair
+		score   float64
+		reasons []string
+	}
+
+	var kept []scoredPair
+	for _, pair := range pairs {
+		score, pairReasons := duplicatePairScore(pair)
+		if score == 0 || score < minScore {
+			continue
+		}
+		kept = append(kept, scoredPair{pair: pair, score: score, reasons: pairReasons})
+		for _, id := range []string{pair.ContactId, pair.OtherId} {
+			if _, ok := parent[id]; !ok {
+				parent[id] = id
+			}
+		}
+		if a, b := find(pair.ContactId), find(pair.OtherId); a != b {
+			parent[b] = a
+		}
+	}
+
+	for _, scored := range kept {
+		root := find(scored.pair.ContactId)
+		scores[root] = max(scores[root], scored.score)
+		if reasons[root] == nil {
+			reasons[root] = map[string]bool{}
+		}
+		for _, reason := range scored.reasons {
+			reasons[root][reason] = true
+		}
+	}
+
+	members := map[string][]string{}
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+	}
+
+	groups := make([]duplicateGroup, 0, len(members))
+	for root, ids := range members {
+		slices.Sort(ids)
+		group := duplicateGroup{ContactIds: ids, Score: scores[root]}
+		for reason := range reasons[root] {
+			group.Reasons = append(group.Reasons, reason)
+		}
+		slices.Sort(group.Reasons)
+		groups = append(groups, group)
+	}
+
+	slices.SortFunc(groups, func(a, b duplicateGroup) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.ContactIds, b.ContactIds)
+	})
+
+	return groups
+}
+
+// mergeContactIds lists the target of a merge followed by its sources. A
+// target among the sources or a source given twice is refused, as the merge
+// would otherwise find fewer contacts than ids and report them missing.
+func mergeContactIds(request *dto.MergeContactRequest) ([]string, error) {
+	ids := []string{request.TargetId}
+	for i, id := range request.SourceIds {
+		if id == request.TargetId {
+			return nil, apperror.Invalid("the target cannot be merged into itself",
+				dto.FieldError{Field: fmt.Sprintf("source_ids[%d]", i), Message: "must not be the target_id"})
+		}
+		if slices.Contains(ids, id) {
+			return nil, apperror.Invalid("a source can only be merged once",
+				dto.FieldError{Field: fmt.Sprintf("source_ids[%d]", i), Message: "must not repeat another source"})
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+)
+
+func TestMergeContactIds(t *testing.T) {
+	tests := []struct {
+		name      string
+		targetId  string
+		sourceIds []string
+		want      []string
+		wantField string
+	}{
+		{name: "target then sources", targetId: "a", sourceIds: []string{"b", "c"}, want: []string{"a", "b", "c"}},
+		{name: "target among the sources", targetId: "a", sourceIds: []string{"b", "a"}, wantField: "source_ids[1]"},
+		{name: "source given twice", targetId: "a", sourceIds: []string{"b", "c", "b"}, wantField: "source_ids[2]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := mergeContactIds(&dto.MergeContactRequest{TargetId: tt.targetId, SourceIds: tt.sourceIds})
+			if tt.wantField != "" {
+				appErr, ok := apperror.As(err)
+				if !ok || appErr.Kind != apperror.KindValidation {
+					t.Fatalf("mergeContactIds() error = %v, want a validation error", err)
+				}
+				if len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.wantField {
+					t.Errorf("mergeContactIds() fields = %+v, want %s", appErr.Fields, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeContactIds() error = %v", err)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("mergeContactIds() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"gorm.io/gorm"
+)
+
+type requestIdKey struct{}
+
+// WithRequestId stores the ID of the HTTP request being served, which is
+// recorded with every history entry written on its behalf.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+func requestId(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+// recordHistory completes history with the request ID, the field-level diff
+// between before and after and a snapshot, then writes it with tx so it
+// commits or rolls back together with the change. before is nil for creates
+// and after is nil for deletes; the snapshot keeps the last known state.
+func recordHistory(ctx context.Context, tx *gorm.DB, historyRepository *repository.ContactHistoryRepository,
+	history *entity.ContactHistory, before map[string]any, after map[string]any) error {
+	history.ID = uuid.NewString()
+	history.RequestId = requestId(ctx)
+	history.Changes = diffSnapshots(before, after)
+	history.Snapshot = after
+	if after == nil {
+		history.Snapshot = before
+	}
+	return historyRepository.Create(tx, history)
+}
+
+// recordContactUpdates records an update by actorId of each of contacts,
+// the rows a bulk write has just changed and bumped the versions of. They
+// are read, locked, before the write and change applies it to them in
+// memory; snapshot takes the fields the write touches.
+func recordContactUpdates(ctx context.Context, tx *gorm.DB, historyRepository *repository.ContactHistoryRepository,
+	contacts []entity.Contact, actorId string, snapshot func(*entity.Contact) map[string]any, change func(*entity.Contact)) error {
+	for i := range contacts {
+		contact := &contacts[i]
+		before := snapshot(contact)
+		change(contact)
+		contact.Version++
+
+		if err := recordHistory(ctx, tx, historyRepository, &entity.ContactHistory{
+			ContactId:  contact.ID,
+			EntityType: entity.HistoryContact,
+			EntityId:   contact.ID,
+			Action:     entity.HistoryUpdate,
+			Version:    contact.Version,
+			ActorId:    actorId,
+		}, before, snapshot(contact)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffSnapshots(before map[string]any, after map[string]any) map[string]entity.FieldChange {
+	changes := map[string]entity.FieldChange{}
+	for field, value := range after {
+		if old, ok := before[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = entity.FieldChange{Old: before[field], New: value}
+		}
+	}
+	for field, old := range before {
+		if _, ok := after[field]; !ok {
+			changes[field] = entity.FieldChange{Old: old}
+		}
+	}
+	return changes
+}
+
+func contactSnapshot(contact *entity.Contact) map[string]any {
+	return map[string]any{
+		"first_name":    contact.FirstName,
+		"last_name":     contact.LastName,
+		"email":         contact.Email,
+		"phone":         contact.Phone,
+		"phone_e164":    contact.PhoneE164,
+		"custom_fields": contact.CustomFields,
+		"dates":         contact.Dates,
+		"photo_id":      contact.PhotoId,
+	}
+}
+
+// withTags adds the sorted tag names to a contact snapshot. Only tag changes
+// record them, since the other writes leave tags alone.
+func withTags(snapshot map[string]any, tags []entity.Tag) map[string]any {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	slices.Sort(names)
+	snapshot["tags"] = names
+	return snapshot
+}
+
+func addressSnapshot(address *entity.Address) map[string]any {
+	return map[string]any{
+		"street":       address.Street,
+		"city":         address.City,
+		"province":     address.Province,
+		"postal_code":  address.PostalCode,
+		"country":      address.Country,
+		"label":        address.Label,
+		"custom_label": address.CustomLabel,
+		"is_primary":   address.IsPrimary,
+	}
+}
+
+// snapshotDates reads the dates back out of a stored snapshot, where they
+// come back as plain JSON values.
+func snapshotDates(snapshot map[string]any) ([]entity.ContactDate, bool) {
+	value, ok := snapshot["dates"]
+	if !ok {
+		return nil, false
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	dates := []entity.ContactDate{}
+	if err := json.Unmarshal(data, &dates); err != nil {
+		return nil, false
+	}
+	return dates, true
+}
+
+// snapshotString reads a string field back from a stored snapshot.
+func snapshotString(snapshot map[string]any, field string) string {
+	value, _ := snapshot[field].(string)
+	return value
+}
@@ -0,0 +1,509 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/canonical"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/geocode"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/postal"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/vcard"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	importBatchSize = 100
+	importMaxErrors = 1000
+)
+
+var importContactFields = []string{"first_name", "last_name", "email", "phone"}
+var importAddressFields = []string{"street", "city", "province", "postal_code", "country"}
+
+// importRecord is one parsed row. validateRecords fills NewContact and
+// NewAddresses with what storeBatch creates.
+type importRecord struct {
+	Row          int
+	ContactId    string
+	Contact      dto.CreateContactRequest
+	Addresses    []dto.CreateAddressRequest
+	NewContact   *entity.Contact
+	NewAddresses []*entity.Address
+}
+
+type ImportUseCase struct {
+	DB                    *gorm.DB
+	Log                   *zap.Logger
+	Validate              *validator.Validate
+	ContactRepository     *repository.ContactRepository
+	AddressRepository     *repository.AddressRepository
+	HistoryRepository     *repository.ContactHistoryRepository
+	CustomFieldRepository *repository.CustomFieldRepository
+	ImportJobRepository   *repository.ImportJobRepository
+	Geocoder              geocode.Geocoder
+	AsyncThreshold        int
+	MaxRows               int
+	DefaultRegion         string
+}
+
+func NewImportUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
+	contactRepository *repository.ContactRepository, addressRepository *repository.AddressRepository,
+	historyRepository *repository.ContactHistoryRepository, customFieldRepository *repository.CustomFieldRepository,
+	importJobRepository *repository.ImportJobRepository, geocoder geocode.Geocoder,
+	asyncThreshold int, maxRows int, defaultRegion string) *ImportUseCase {
+	return &ImportUseCase{
+		DB:                    db,
+		Log:                   logger,
+		Validate:              validate,
+		ContactRepository:     contactRepository,
+		AddressRepository:     addressRepository,
+		HistoryRepository:     historyRepository,
+		CustomFieldRepository: customFieldRepository,
+		ImportJobRepository:   importJobRepository,
+		Geocoder:              geocoder,
+		AsyncThreshold:        asyncThreshold,
+		MaxRows:               maxRows,
+		DefaultRegion:         defaultRegion,
+	}
+}
+
+// Import validates every row and, unless it is a dry run, stores the valid
+// ones. Imports above AsyncThreshold rows are stored by a background job whose
+// progress is readable through GetJob.
+func (c *ImportUseCase) Import(ctx context.Context, request *dto.ImportContactRequest) (*dto.ImportJobResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	var records []importRecord
+	var err error
+	switch request.Format {
+	case "csv":
+		records, err = parseCSVImport(request.Content, request.Mapping, request.UserId)
+	case "vcf":
+		records, err = parseVCardImport(request.Content, request.UserId)
+	}
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("error parsing import file")
+		return nil, apperror.Invalid(err.Error())
+	}
+
+	if c.MaxRows > 0 && len(records) > c.MaxRows {
+		c.Log.Warn("import has too many rows", zap.Int("rows", len(records)))
+		return nil, apperror.New(apperror.KindTooLarge,
+			fmt.Sprintf("import is limited to %d rows", c.MaxRows))
+	}
+
+	definitions, err := c.CustomFieldRepository.FindAllByUserId(c.DB.WithContext(ctx), request.UserId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting custom fields")
+		return nil, apperror.ErrInternal
+	}
+
+	job := &entity.ImportJob{
+		ID:        uuid.NewString(),
+		UserId:    request.UserId,
+		Status:    entity.ImportJobPending,
+		Format:    request.Format,
+		TotalRows: len(records),
+		Errors:    []entity.ImportRowError{},
+	}
+
+	if request.DryRun {
+		valid, rowErrors := c.validateRecords(records, definitions)
+		job.Status = entity.ImportJobCompleted
+		job.ImportedRows = len(valid)
+		job.FailedRows = len(records) - len(valid)
+		job.Errors = capRowErrors(rowErrors)
+
+		response := converter.ImportJobToResponse(job)
+		response.ID = ""
+		response.DryRun = true
+		return response, nil
+	}
+
+	if len(records) <= c.AsyncThreshold {
+		c.run(ctx, job, records, definitions)
+		if job.Status == entity.ImportJobFailed {
+			return nil, apperror.ErrInternal
+		}
+		response := converter.ImportJobToResponse(job)
+		response.ID = ""
+		return response, nil
+	}
+
+	if err := c.ImportJobRepository.Create(c.DB.WithContext(ctx), job); err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating import job")
+		return nil, apperror.ErrInternal
+	}
+
+	// The job outlives the request, so it must not inherit its cancellation.
+	go c.run(context.WithoutCancel(ctx), job, records, definitions)
+
+	return converter.ImportJobToResponse(job), nil
+}
+
+func (c *ImportUseCase) GetJob(ctx context.Context, request *dto.GetImportJobRequest) (*dto.ImportJobResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	job := new(entity.ImportJob)
+	if err := c.ImportJobRepository.FindByIdAndUserId(tx, job, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting import job")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting import job")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.ImportJobToResponse(job), nil
+}
+
+// run stores the valid records in batches. Jobs without a persisted row (the
+// synchronous path) only have their in-memory counters updated.
+func (c *ImportUseCase) run(ctx context.Context, job *entity.ImportJob, records []importRecord, definitions []entity.CustomField) {
+	db := c.DB.WithContext(ctx)
+	persisted := job.CreatedAt != 0
+
+	valid, rowErrors := c.validateRecords(records, definitions)
+	job.Status = entity.ImportJobRunning
+	job.FailedRows = len(records) - len(valid)
+	job.Errors = capRowErrors(rowErrors)
+	c.saveProgress(db, job, persisted)
+
+	for start := 0; start < len(valid); start += importBatchSize {
+		end := min(start+importBatchSize, len(valid))
+		batch := valid[start:end]
+
+		if err := c.storeBatch(ctx, db, job.UserId, batch); err != nil {
+			c.Log.With(zap.Error(err)).Error("error storing import batch", zap.String("job_id", job.ID))
+			job.FailedRows += len(batch)
+			for _, record := range batch {
+				job.Errors = capRowErrors(append(job.Errors, entity.ImportRowError{Row: record.Row, Message: "could not be stored"}))
+			}
+		} else {
+			job.ImportedRows += len(batch)
+		}
+		c.saveProgress(db, job, persisted)
+	}
+
+	job.Status = entity.ImportJobCompleted
+	if job.ImportedRows == 0 && len(valid) > 0 {
+		job.Status = entity.ImportJobFailed
+	}
+	job.FinishedAt = time.Now().UnixMilli()
+	c.saveProgress(db, job, persisted)
+}
+
+func (c *ImportUseCase) saveProgress(db *gorm.DB, job *entity.ImportJob, persisted bool) {
+	if !persisted {
+		return
+	}
+	if err := c.ImportJobRepository.Update(db, job); err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating import job", zap.String("job_id", job.ID))
+	}
+}
+
+// storeBatch geocodes the batch's addresses before it opens the transaction,
+// which a remote geocoder would otherwise keep open for seconds.
+func (c *ImportUseCase) storeBatch(ctx context.Context, db *gorm.DB, userId string, batch []importRecord) error {
+	for _, record := range batch {
+		for _, address := range record.NewAddresses {
+			geocodeAddress(ctx, c.Geocoder, c.Log, address)
+		}
+	}
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	for _, record := range batch {
+		if err := createContact(ctx, tx, c.ContactRepository, c.HistoryRepository, record.NewContact, userId); err != nil {
+			return err
+		}
+
+		for _, address := range record.NewAddresses {
+			if err := createAddress(ctx, tx, c.AddressRepository, c.HistoryRepository, address, userId); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// validateRecords also holds each record to the user's custom field
+// definitions, so a required custom field fails every row that lacks it.
+func (c *ImportUseCase) validateRecords(records []importRecord, definitions []entity.CustomField) ([]importRecord, []entity.ImportRowError) {
+	var valid []importRecord
+	var rowErrors []entity.ImportRowError
+
+	for _, record := range records {
+		var errs []entity.ImportRowError
+
+		if err := c.Validate.Struct(&record.Contact); err != nil {
+			errs = append(errs, rowValidationErrors(record.Row, "", err)...)
+		}
+
+		addresses := make([]*entity.Address, 0, len(record.Addresses))
+		countries := make([]string, 0, len(record.Addresses))
+		for i := range record.Addresses {
+			request := &record.Addresses[i]
+			if err := c.Validate.Struct(request); err != nil {
+				errs = append(errs, rowValidationErrors(record.Row, fmt.Sprintf("addresses[%d].", i), err)...)
+				continue
+			}
+
+			// Other address books write country names, which are stored as
+			// their codes.
+			if code := postal.Code(request.Country); code != "" {
+				request.Country = code
+			}
+			address, fields := newAddress(record.ContactId, request)
+			for _, field := range fields {
+				errs = append(errs, entity.ImportRowError{Row: record.Row, Field: fmt.Sprintf("addresses[%d].%s", i, field.Field), Message: field.Message})
+			}
+			addresses = append(addresses, address)
+			countries = append(countries, address.Country)
+		}
+
+		phoneE164, err := canonical.Phone(record.Contact.Phone, phoneRegion(c.DefaultRegion, countries...))
+		if err != nil {
+			errs = append(errs, entity.ImportRowError{Row: record.Row, Field: "phone", Message: err.Error()})
+		}
+
+		customFields, err := customFieldValues(definitions, record.Contact.CustomFields)
+		if err != nil {
+			errs = append(errs, entity.ImportRowError{Row: record.Row, Field: "custom_fields", Message: err.Error()})
+		}
+
+		dates, err := contactDates(record.Contact.Dates)
+		if err != nil {
+			errs = append(errs, entity.ImportRowError{Row: record.Row, Field: "dates", Message: err.Error()})
+		}
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+
+		// The address marked preferred is primary, or else the first one.
+		primary := max(slices.IndexFunc(record.Addresses, func(a dto.CreateAddressRequest) bool { return a.IsPrimary }), 0)
+		for i, address := range addresses {
+			address.IsPrimary = i == primary
+		}
+
+		record.NewContact = newContact(record.ContactId, &record.Contact, phoneE164, customFields, dates)
+		record.NewAddresses = addresses
+		valid = append(valid, record)
+	}
+
+	return valid, rowErrors
+}
+
+func rowValidationErrors(row int, prefix string, err error) []entity.ImportRowError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []entity.ImportRowError{{Row: row, Message: err.Error()}}
+	}
+
+	rowErrors := make([]entity.ImportRowError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		rowErrors[i] = entity.ImportRowError{
+			Row:     row,
+			Field:   prefix + fieldError.Field(),
+			Message: apperror.Message(fieldError),
+		}
+	}
+	return rowErrors
+}
+
+func capRowErrors(rowErrors []entity.ImportRowError) []entity.ImportRowError {
+	if rowErrors == nil {
+		return []entity.ImportRowError{}
+	}
+	if len(rowErrors) > importMaxErrors {
+		return rowErrors[:importMaxErrors]
+	}
+	return rowErrors
+}
+
+// parseCSVImport reads a header row and maps columns onto contact and address
+// fields. Without an explicit mapping a column is used when its header equals
+// the field name, ignoring case, spaces and dashes.
+func parseCSVImport(content []byte, mapping map[string]string, userId string) ([]importRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv: cannot read header: %v", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[normalizeHeader(name)] = i
+	}
+
+	fieldColumns := map[string]int{}
+	for _, field := range append(append([]string{}, importContactFields...), importAddressFields...) {
+		source, mapped := mapping[field]
+		if !mapped {
+			source = field
+		}
+		index, found := columns[normalizeHeader(source)]
+		if !found {
+			if mapped {
+				return nil, fmt.Errorf("csv: mapped column %q for %s is not in the header", source, field)
+			}
+			continue
+		}
+		fieldColumns[field] = index
+	}
+
+	if _, found := fieldColumns["first_name"]; !found {
+		return nil, errors.New("csv: no column is mapped to first_name")
+	}
+
+	var records []importRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv: %v", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := func(field string) string {
+			index, found := fieldColumns[field]
+			if !found || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+
+		contactId := uuid.NewString()
+		record := importRecord{
+			Row:       line,
+			ContactId: contactId,
+			Contact: dto.CreateContactRequest{
+				UserId:    userId,
+				FirstName: value("first_name"),
+				LastName:  value("last_name"),
+				Email:     value("email"),
+				Phone:     value("phone"),
+			},
+		}
+
+		address := dto.CreateAddressRequest{
+			UserId:     userId,
+			ContactId:  contactId,
+			Street:     value("street"),
+			City:       value("city"),
+			Province:   value("province"),
+			PostalCode: value("postal_code"),
+			Country:    value("country"),
+		}
+		if address.Street != "" || address.City != "" || address.Province != "" || address.PostalCode != "" || address.Country != "" {
+			record.Addresses = append(record.Addresses, address)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func parseVCardImport(content []byte, userId string) ([]importRecord, error) {
+	cards, err := vcard.ReadAll(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]importRecord, len(cards))
+	for i, card := range cards {
+		firstName, lastName := card.Names()
+		contactId := uuid.NewString()
+
+		record := importRecord{
+			Row:       card.Line,
+			ContactId: contactId,
+			Contact: dto.CreateContactRequest{
+				UserId:    userId,
+				FirstName: firstName,
+				LastName:  lastName,
+				Email:     first(card.Emails),
+				Phone:     first(card.Phones),
+			},
+		}
+
+		if card.Birthday != "" {
+			record.Contact.Dates = append(record.Contact.Dates, dto.ContactDateRequest{Type: entity.DateTypeBirthday, Date: card.Birthday})
+		}
+		if card.Anniversary != "" {
+			record.Contact.Dates = append(record.Contact.Dates, dto.ContactDateRequest{Type: entity.DateTypeAnniversary, Date: card.Anniversary})
+		}
+
+		for _, address := range card.Addresses {
+			request := dto.CreateAddressRequest{
+				UserId:     userId,
+				ContactId:  contactId,
+				Street:     address.Street,
+				City:       address.City,
+				Province:   address.Province,
+				PostalCode: address.PostalCode,
+				Country:    address.Country,
+			}
+			for _, addressType := range address.Types {
+				switch strings.ToLower(addressType) {
+				case "home":
+					request.Label = entity.AddressLabelHome
+				case "work":
+					request.Label = entity.AddressLabelWork
+				case "pref":
+					request.IsPrimary = true
+				}
+			}
+			record.Addresses = append(record.Addresses, request)
+		}
+
+		records[i] = record
+	}
+
+	return records, nil
+}
+
+func normalizeHeader(name string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type NoteUseCase struct {
+	DB                *gorm.DB
+	Log               *zap.Logger
+	Validate          *validator.Validate
+	ContactRepository *repository.ContactRepository
+	NoteRepository    *repository.NoteRepository
+}
+
+func NewNoteUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
+	contactRepository *repository.ContactRepository, noteRepository *repository.NoteRepository) *NoteUseCase {
+	return &NoteUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		ContactRepository: contactRepository,
+		NoteRepository:    noteRepository,
+	}
+}
+
+func (c *NoteUseCase) Create(ctx context.Context, request *dto.CreateNoteRequest) (*dto.NoteResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	note := &entity.Note{
+		ID:         uuid.NewString(),
+		ContactId:  contact.ID,
+		Type:       request.Type,
+		Body:       request.Body,
+		OccurredAt: request.OccurredAt,
+		CreatedBy:  request.UserId,
+		UpdatedBy:  request.UserId,
+	}
+	if note.OccurredAt == 0 {
+		note.OccurredAt = time.Now().UnixMilli()
+	}
+
+	if err := c.NoteRepository.Create(tx, note); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to create note")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.RefreshLastInteraction(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to refresh last interaction")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.NoteToResponse(note), nil
+}
+
+func (c *NoteUseCase) Update(ctx context.Context, request *dto.UpdateNoteRequest) (*dto.NoteResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	note := new(entity.Note)
+	if err := c.NoteRepository.FindByIdAndContactId(tx, note, request.ID, contact.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find note")
+		return nil, apperror.ErrNotFound
+	}
+
+	note.Type = request.Type
+	note.Body = request.Body
+	note.UpdatedBy = request.UserId
+	if request.OccurredAt != 0 {
+		note.OccurredAt = request.OccurredAt
+	}
+
+	if err := c.NoteRepository.Update(tx, note); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to update note")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.RefreshLastInteraction(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to refresh last interaction")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.NoteToResponse(note), nil
+}
+
+func (c *NoteUseCase) Get(ctx context.Context, request *dto.GetNoteRequest) (*dto.NoteResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionRead); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	note := new(entity.Note)
+	if err := c.NoteRepository.FindByIdAndContactId(tx, note, request.ID, contact.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find note")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.NoteToResponse(note), nil
+}
+
+func (c *NoteUseCase) Delete(ctx context.Context, request *dto.DeleteNoteRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return apperror.ErrNotFound
+	}
+
+	note := new(entity.Note)
+	if err := c.NoteRepository.FindByIdAndContactId(tx, note, request.ID, contact.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find note")
+		return apperror.ErrNotFound
+	}
+
+	if err := c.NoteRepository.Delete(tx, note); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to delete note")
+		return apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.RefreshLastInteraction(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to refresh last interaction")
+		return apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return apperror.ErrInternal
+	}
+
+	return nil
+}
+
+func (c *NoteUseCase) List(ctx context.Context, request *dto.ListNoteRequest) ([]dto.NoteResponse, *dto.PageMetadata, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionRead); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, nil, apperror.ErrNotFound
+	}
+
+	notes, page, err := c.NoteRepository.Search(tx, request)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find notes")
+		if errors.Is(err, repository.ErrInvalidPageRequest) {
+			return nil, nil, apperror.Invalid(err.Error())
+		}
+		return nil, nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return nil, nil, apperror.ErrInternal
+	}
+
+	responses := make([]dto.NoteResponse, len(notes))
+	for i, note := range notes {
+		responses[i] = *converter.NoteToResponse(&note)
+	}
+
+	return responses, toPageMetadata(page, repository.NewPageRequest(request)), nil
+}
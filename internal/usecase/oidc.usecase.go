@@ -0,0 +1,347 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/oidc"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+type OIDCUseCase struct {
+	DB                     *gorm.DB
+	Log                    *zap.Logger
+	Validate               *validator.Validate
+	Client                 *oidc.Client
+	LinkExisting           bool
+	UserRepository         *repository.UserRepository
+	UserIdentityRepository *repository.UserIdentityRepository
+	OIDCStateRepository    *repository.OIDCStateRepository
+}
+
+func NewOIDCUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate, client *oidc.Client, linkExisting bool,
+	userRepository *repository.UserRepository, userIdentityRepository *repository.UserIdentityRepository,
+	oidcStateRepository *repository.OIDCStateRepository) *OIDCUseCase {
+	return &OIDCUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		Client:                 client,
+		LinkExisting:           linkExisting,
+		UserRepository:         userRepository,
+		UserIdentityRepository: userIdentityRepository,
+		OIDCStateRepository:    oidcStateRepository,
+	}
+}
+
+func (c *OIDCUseCase) Login(ctx context.Context, request *dto.OIDCLoginRequest) (*dto.OIDCLoginResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warn("Invalid request body", zap.Error(err))
+		return nil, apperror.Validation(err)
+	}
+
+	now := time.Now()
+	if err := c.OIDCStateRepository.DeleteExpired(tx, now.UnixMilli()); err != nil {
+		c.Log.Warn("Failed delete expired oidc states", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	state := &entity.OIDCState{
+		State:        oidc.RandomString(24),
+		Nonce:        oidc.RandomString(24),
+		CodeVerifier: oidc.NewCodeVerifier(),
+		ExpiresAt:    now.Add(oidcStateTTL).UnixMilli(),
+	}
+	if request.UserId != "" {
+		state.UserId = &request.UserId
+	}
+
+	if err := c.OIDCStateRepository.Create(tx, state); err != nil {
+		c.Log.Warn("Failed create oidc state", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	extra := url.Values{}
+	if request.LoginHint != "" {
+		extra.Set("login_hint", request.LoginHint)
+	}
+
+	authorizationURL, err := c.Client.AuthCodeURL(ctx, state.State, state.Nonce, oidc.CodeChallengeS256(state.CodeVerifier), extra)
+	if err != nil {
+		c.Log.Warn("Failed build authorization url", zap.Error(err))
+		return nil, apperror.ErrUpstream
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warn("Failed commit transaction", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	return &dto.OIDCLoginResponse{AuthorizationURL: authorizationURL}, nil
+}
+
+// Callback finishes a login, or the linking of an identity when the state
+// names the user to link it to. The identity provider is only called once the
+// state is consumed, so no transaction is held open across its round trips.
+func (c *OIDCUseCase) Callback(ctx context.Context, request *dto.OIDCCallbackRequest) (*dto.UserResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warn("Invalid request body", zap.Error(err))
+		return nil, apperror.Validation(err)
+	}
+
+	state, err := c.consumeState(ctx, request.State)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Error != "" {
+		c.Log.Warn("Identity provider returned error",
+			zap.String("error", request.Error), zap.String("description", request.ErrorDescription))
+		return nil, apperror.ErrUnauthorized
+	}
+
+	if time.Now().UnixMilli() > state.ExpiresAt {
+		c.Log.Warn("Oidc state expired")
+		return nil, apperror.ErrUnauthorized
+	}
+
+	token, err := c.Client.Exchange(ctx, request.Code, state.CodeVerifier)
+	if err != nil {
+		c.Log.Warn("Failed exchange authorization code", zap.Error(err))
+		return nil, apperror.ErrUnauthorized
+	}
+
+	claims, err := c.Client.VerifyIDToken(ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		c.Log.Warn("Failed verify id token", zap.Error(err))
+		return nil, apperror.ErrUnauthorized
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var user *entity.User
+	if state.UserId != nil {
+		user, err = c.linkUser(tx, *state.UserId, claims)
+	} else {
+		user, err = c.resolveUser(tx, claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user.Token = uuid.New().String()
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warn("Failed save user", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warn("Failed commit transaction", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.UserToTokenResponse(user), nil
+}
+
+// consumeState deletes the state in a transaction of its own, as a state is
+// single use whatever the outcome of the callback.
+func (c *OIDCUseCase) consumeState(ctx context.Context, value string) (*entity.OIDCState, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	state := new(entity.OIDCState)
+	if err := c.OIDCStateRepository.FindByStateForUpdate(tx, state, value); err != nil {
+		c.Log.Warn("Failed find oidc state", zap.Error(err))
+		return nil, apperror.ErrUnauthorized
+	}
+
+	if err := c.OIDCStateRepository.Delete(tx, state); err != nil {
+		c.Log.Warn("Failed delete oidc state", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warn("Failed commit transaction", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	return state, nil
+}
+
+// linkUser links the identity to the logged in user who started linking it.
+// An identity already linked to that user is left as it is; one linked to
+// another user is a conflict.
+func (c *OIDCUseCase) linkUser(tx *gorm.DB, userId string, claims *oidc.Claims) (*entity.User, error) {
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, userId); err != nil {
+		c.Log.Warn("Failed find linking user", zap.Error(err))
+		return nil, apperror.ErrUnauthorized
+	}
+
+	identity := new(entity.UserIdentity)
+	err := c.UserIdentityRepository.FindByIssuerAndSubject(tx, identity, claims.Issuer, claims.Subject)
+	if err == nil {
+		if identity.UserId != user.ID {
+			c.Log.Warn("Identity is linked to another user", zap.String("subject", claims.Subject))
+			return nil, apperror.Conflict("identity is already linked to another user")
+		}
+		return user, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.Warn("Failed find user identity", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.createIdentity(tx, user, claims); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (c *OIDCUseCase) resolveUser(tx *gorm.DB, claims *oidc.Claims) (*entity.User, error) {
+	user := new(entity.User)
+
+	identity := new(entity.UserIdentity)
+	err := c.UserIdentityRepository.FindByIssuerAndSubject(tx, identity, claims.Issuer, claims.Subject)
+	if err == nil {
+		if err := c.UserRepository.FindById(tx, user, identity.UserId); err != nil {
+			c.Log.Warn("Failed find linked user", zap.Error(err))
+			return nil, apperror.ErrInternal
+		}
+		return user, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.Warn("Failed find user identity", zap.Error(err))
+		return nil, apperror.ErrInternal
+	}
+
+	linked, err := c.findUserByVerifiedEmail(tx, user, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if !linked {
+		username := usernameFromClaims(claims)
+		if username == "" {
+			c.Log.Warn("Id token carries no usable username", zap.String("subject", claims.Subject))
+			return nil, apperror.ErrUnauthorized
+		}
+
+		total, err := c.UserRepository.CountById(tx, username)
+		if err != nil {
+			c.Log.Warn("Failed count user from database", zap.Error(err))
+			return nil, apperror.ErrInternal
+		}
+
+		// The identity provider lets users pick their preferred_username, so
+		// it never links to the user of that name. That user links the
+		// identity from their own account, or it links on a verified email.
+		if total > 0 {
+			c.Log.Warn("User already exists", zap.String("user_id", username))
+			return nil, apperror.Conflict("user already exists; log in and link the identity to it from the current user")
+		}
+
+		// Provisioned users have no local password; bcrypt rejects the empty
+		// hash, so password login stays impossible until they set one.
+		user.ID = username
+		user.Name = claims.Name
+		if user.Name == "" {
+			user.Name = username
+		}
+
+		if err := c.UserRepository.Create(tx, user); err != nil {
+			c.Log.Warn("Failed create user to database", zap.Error(err))
+			return nil, apperror.ErrInternal
+		}
+	}
+
+	if err := c.createIdentity(tx, user, claims); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (c *OIDCUseCase) createIdentity(tx *gorm.DB, user *entity.User, claims *oidc.Claims) error {
+	identity := &entity.UserIdentity{
+		ID:            uuid.NewString(),
+		UserId:        user.ID,
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified && claims.Email != "",
+	}
+
+	if err := c.UserIdentityRepository.Create(tx, identity); err != nil {
+		c.Log.Warn("Failed create user identity", zap.Error(err))
+		return apperror.ErrInternal
+	}
+	return nil
+}
+
+// findUserByVerifiedEmail finds the user to link an unknown identity to when
+// linking is enabled: the one user with another identity whose provider
+// verified the same email as this one's did. It reports false when there is
+// none, and a conflict when several users share the email.
+func (c *OIDCUseCase) findUserByVerifiedEmail(tx *gorm.DB, user *entity.User, claims *oidc.Claims) (bool, error) {
+	if !c.LinkExisting || !claims.EmailVerified || claims.Email == "" {
+		return false, nil
+	}
+
+	identities, err := c.UserIdentityRepository.FindAllByVerifiedEmail(tx, claims.Email)
+	if err != nil {
+		c.Log.Warn("Failed find user identities by email", zap.Error(err))
+		return false, apperror.ErrInternal
+	}
+	if len(identities) == 0 {
+		return false, nil
+	}
+
+	userId := identities[0].UserId
+	for _, identity := range identities[1:] {
+		if identity.UserId != userId {
+			c.Log.Warn("Verified email belongs to several users", zap.String("subject", claims.Subject))
+			return false, apperror.ErrConflict
+		}
+	}
+
+	if err := c.UserRepository.FindById(tx, user, userId); err != nil {
+		c.Log.Warn("Failed find user by id", zap.Error(err))
+		return false, apperror.ErrInternal
+	}
+	return true, nil
+}
+
+func usernameFromClaims(claims *oidc.Claims) string {
+	username := claims.PreferredUsername
+	if username == "" && claims.EmailVerified {
+		username = claims.Email
+	}
+	if username == "" {
+		username = claims.Subject
+	}
+
+	username = strings.TrimSpace(username)
+	if len(username) > 100 {
+		return ""
+	}
+	return username
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"math"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+)
+
+func toPageMetadata(page *repository.Page, request repository.PageRequest) *dto.PageMetadata {
+	metadata := &dto.PageMetadata{
+		Size:       request.Size,
+		TotalItem:  page.Total,
+		NextCursor: page.Next,
+		PrevCursor: page.Prev,
+	}
+
+	if request.After == "" && request.Before == "" {
+		metadata.Page = request.Page
+	}
+
+	if page.Total != nil {
+		totalPage := int64(math.Ceil(float64(*page.Total) / float64(request.Size)))
+		metadata.TotalPage = &totalPage
+	}
+
+	return metadata
+}
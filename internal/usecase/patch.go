@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/jsonpatch"
+)
+
+// applyPatch runs a merge patch or JSON Patch against the JSON form of
+// current. The result is decoded strictly, so a patch touching members the
+// update request does not accept is rejected rather than silently ignored.
+func applyPatch[T any](current *T, format string, patch []byte) (*T, error) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, apperror.ErrInternal
+	}
+
+	var patched []byte
+	if format == "json" {
+		patched, err = jsonpatch.Apply(doc, patch)
+	} else {
+		patched, err = jsonpatch.MergePatch(doc, patch)
+	}
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, apperror.Conflict(err.Error())
+		}
+		return nil, apperror.Invalid(err.Error())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	result := new(T)
+	if err := decoder.Decode(result); err != nil {
+		return nil, apperror.Invalid("patched document is invalid: " + err.Error())
+	}
+	return result, nil
+}
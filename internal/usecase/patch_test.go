@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+)
+
+type patchTarget struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+	Note *string  `json:"note"`
+}
+
+func TestApplyPatch(t *testing.T) {
+	note := "call back"
+	current := patchTarget{Name: "Ada", Tags: []string{"friends"}, Note: &note}
+
+	tests := []struct {
+		name     string
+		format   string
+		patch    string
+		want     patchTarget
+		wantKind apperror.Kind
+	}{
+		{
+			name:   "merge patch replaces a member",
+			format: "merge",
+			patch:  `{"name":"Grace"}`,
+			want:   patchTarget{Name: "Grace", Tags: []string{"friends"}, Note: &note},
+		},
+		{
+			name:   "merge patch null clears a member",
+			format: "merge",
+			patch:  `{"note":null}`,
+			want:   patchTarget{Name: "Ada", Tags: []string{"friends"}},
+		},
+		{
+			name:   "JSON Patch appends with -",
+			format: "json",
+			patch:  `[{"op":"test","path":"/name","value":"Ada"},{"op":"add","path":"/tags/-","value":"math"}]`,
+			want:   patchTarget{Name: "Ada", Tags: []string{"friends", "math"}, Note: &note},
+		},
+		{
+			name:     "failed test is a conflict",
+			format:   "json",
+			patch:    `[{"op":"test","path":"/name","value":"Grace"}]`,
+			wantKind: apperror.KindConflict,
+		},
+		{
+			name:     "invalid JSON Patch is a validation error",
+			format:   "json",
+			patch:    `[{"op":"remove","path":"/missing"}]`,
+			wantKind: apperror.KindValidation,
+		},
+		{
+			name:     "unknown member is a validation error",
+			format:   "merge",
+			patch:    `{"nickname":"Countess"}`,
+			wantKind: apperror.KindValidation,
+		},
+		{
+			name:     "wrong type is a validation error",
+			format:   "json",
+			patch:    `[{"op":"replace","path":"/tags","value":"math"}]`,
+			wantKind: apperror.KindValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPatch(&current, tt.format, []byte(tt.patch))
+			if tt.wantKind != "" {
+				appErr, ok := apperror.As(err)
+				if !ok || appErr.Kind != tt.wantKind {
+					t.Fatalf("applyPatch() error = %v, want kind %s", err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyPatch() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("applyPatch() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+	if current.Name != "Ada" || len(current.Tags) != 1 || current.Note != &note {
+		t.Errorf("applyPatch() modified current: %+v", current)
+	}
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/photo"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const defaultMaxPhotoSize = 5 << 20
+
+type PhotoUseCase struct {
+	DB                *gorm.DB
+	Log               *zap.Logger
+	Validate          *validator.Validate
+	ContactRepository *repository.ContactRepository
+	HistoryRepository *repository.ContactHistoryRepository
+	BlobStore         storage.BlobStore
+	// MaxSize is the largest accepted upload in bytes.
+	MaxSize int64
+}
+
+func NewPhotoUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
+	contactRepository *repository.ContactRepository, historyRepository *repository.ContactHistoryRepository,
+	blobStore storage.BlobStore, maxSize int64) *PhotoUseCase {
+	if maxSize <= 0 {
+		maxSize = defaultMaxPhotoSize
+	}
+
+	return &PhotoUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		ContactRepository: contactRepository,
+		HistoryRepository: historyRepository,
+		BlobStore:         blobStore,
+		MaxSize:           maxSize,
+	}
+}
+
+// Put stores a new photo for the contact and replaces the previous one. The
+// blobs are written before the contact row points at them and the old ones
+// are only removed after commit, so a failure never leaves the contact
+// pointing at a missing photo.
+func (c *PhotoUseCase) Put(ctx context.Context, request *dto.PutPhotoRequest) (*dto.ContactResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	if int64(len(request.Data)) > c.MaxSize {
+		return nil, apperror.New(apperror.KindTooLarge, fmt.Sprintf("photo is larger than %d bytes", c.MaxSize))
+	}
+
+	processed, err := photo.Process(request.Data)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Warn("failed to process photo")
+		if errors.Is(err, photo.ErrUnsupportedType) {
+			return nil, apperror.New(apperror.KindUnsupported, err.Error())
+		}
+		return nil, apperror.Invalid(err.Error())
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := checkVersion(request.IfMatch, contact.Version); err != nil {
+		c.Log.Warn("contact version does not match", zap.Int64("version", contact.Version))
+		return nil, err
+	}
+
+	photoId := uuid.NewString()
+	if err := c.BlobStore.Put(ctx, photoKey(photoId), processed.ContentType, processed.Data); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to store photo")
+		return nil, apperror.ErrInternal
+	}
+	if err := c.BlobStore.Put(ctx, thumbnailKey(photoId), processed.ThumbnailContentType, processed.Thumbnail); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to store thumbnail")
+		deletePhotoBlobs(ctx, c.BlobStore, c.Log, photoId)
+		return nil, apperror.ErrInternal
+	}
+
+	previousId := contact.PhotoId
+	before := contactSnapshot(contact)
+	contact.PhotoId = photoId
+	contact.PhotoContentType = processed.ContentType
+	contact.Version++
+
+	if err := c.save(ctx, tx, contact, request.UserId, before); err != nil {
+		deletePhotoBlobs(ctx, c.BlobStore, c.Log, photoId)
+		return nil, err
+	}
+
+	deletePhotoBlobs(ctx, c.BlobStore, c.Log, previousId)
+
+	return converter.ContactToResponse(contact), nil
+}
+
+func (c *PhotoUseCase) Get(ctx context.Context, request *dto.GetPhotoRequest) (*dto.PhotoResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(c.DB.WithContext(ctx), contact, request.ContactId, request.UserId, entity.PermissionRead); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	if contact.PhotoId == "" {
+		return nil, apperror.NotFound("contact has no photo")
+	}
+
+	key, contentType := photoKey(contact.PhotoId), contact.PhotoContentType
+	if request.Size == "thumbnail" {
+		key, contentType = thumbnailKey(contact.PhotoId), photo.ThumbnailContentType(contentType)
+	}
+
+	body, err := c.BlobStore.Get(ctx, key)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to read photo")
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, apperror.NotFound("contact has no photo")
+		}
+		return nil, apperror.ErrInternal
+	}
+
+	return &dto.PhotoResponse{
+		ID:          contact.PhotoId,
+		ContentType: contentType,
+		Body:        body,
+	}, nil
+}
+
+func (c *PhotoUseCase) Delete(ctx context.Context, request *dto.DeletePhotoRequest) (*dto.ContactResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to validate request body")
+		return nil, apperror.Validation(err)
+	}
+
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	contact := new(entity.Contact)
+	if err := c.ContactRepository.FindByIdAndAccess(tx, contact, request.ContactId, request.UserId, entity.PermissionWrite); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact")
+		return nil, apperror.ErrNotFound
+	}
+
+	if contact.PhotoId == "" {
+		return nil, apperror.NotFound("contact has no photo")
+	}
+
+	if err := checkVersion(request.IfMatch, contact.Version); err != nil {
+		c.Log.Warn("contact version does not match", zap.Int64("version", contact.Version))
+		return nil, err
+	}
+
+	previousId := contact.PhotoId
+	before := contactSnapshot(contact)
+	contact.PhotoId = ""
+	contact.PhotoContentType = ""
+	contact.Version++
+
+	if err := c.save(ctx, tx, contact, request.UserId, before); err != nil {
+		return nil, err
+	}
+
+	deletePhotoBlobs(ctx, c.BlobStore, c.Log, previousId)
+
+	return converter.ContactToResponse(contact), nil
+}
+
+// save writes the contact's new photo columns, records the change and
+// commits, leaving the contact's tags loaded for the response.
+func (c *PhotoUseCase) save(ctx context.Context, tx *gorm.DB, contact *entity.Contact, actorId string, before map[string]any) error {
+	if err := c.ContactRepository.UpdateVersioned(tx, contact, contact.Version-1); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to update contact photo")
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return errVersionMismatch
+		}
+		return apperror.ErrInternal
+	}
+
+	if err := recordHistory(ctx, tx, c.HistoryRepository, &entity.ContactHistory{
+		ContactId:  contact.ID,
+		EntityType: entity.HistoryContact,
+		EntityId:   contact.ID,
+		Action:     entity.HistoryUpdate,
+		Version:    contact.Version,
+		ActorId:    actorId,
+	}, before, contactSnapshot(contact)); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to record contact history")
+		return apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.LoadTags(tx, contact); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to find contact tags")
+		return apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to commit transaction")
+		return apperror.ErrInternal
+	}
+	return nil
+}
+
+func photoKey(photoId string) string {
+	return "photos/" + photoId
+}
+
+func thumbnailKey(photoId string) string {
+	return "photos/" + photoId + "-thumbnail"
+}
+
+// deletePhotoBlobs removes a photo and its thumbnail once nothing refers to
+// them any more. Failures only leave orphaned blobs behind, so they are
+// logged rather than returned.
+func deletePhotoBlobs(ctx context.Context, store storage.BlobStore, log *zap.Logger, photoIds ...string) {
+	for _, photoId := range photoIds {
+		if photoId == "" {
+			continue
+		}
+		for _, key := range []string{photoKey(photoId), thumbnailKey(photoId)} {
+			if err := store.Delete(context.WithoutCancel(ctx), key); err != nil {
+				log.With(zap.Error(err), zap.String("key", key)).Warn("failed to delete photo blob")
+			}
+		}
+	}
+}
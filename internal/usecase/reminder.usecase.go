@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/notify"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ReminderUseCase struct {
+	DB                 *gorm.DB
+	Log                *zap.Logger
+	ContactRepository  *repository.ContactRepository
+	ReminderRepository *repository.ReminderRepository
+	Notifier           notify.Notifier
+	DaysAhead          int
+}
+
+func NewReminderUseCase(db *gorm.DB, logger *zap.Logger,
+	contactRepository *repository.ContactRepository, reminderRepository *repository.ReminderRepository,
+	notifier notify.Notifier, daysAhead int) *ReminderUseCase {
+	return &ReminderUseCase{
+		DB:                 db,
+		Log:                logger,
+		ContactRepository:  contactRepository,
+		ReminderRepository: reminderRepository,
+		Notifier:           notifier,
+		DaysAhead:          daysAhead,
+	}
+}
+
+// Send notifies the owners of contacts whose dates come round within
+// DaysAhead days. Each occurrence is reminded of once, however often Send
+// runs; a failed notification is retried on the next run.
+func (c *ReminderUseCase) Send(ctx context.Context) error {
+	from := startOfDay(time.Now())
+
+	if err := c.ReminderRepository.DeleteOccurredBefore(c.DB.WithContext(ctx), from.Format(contactDateLayout)); err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to delete past reminders")
+		return err
+	}
+
+	contacts, err := c.ContactRepository.FindAllWithDatesBetween(c.DB.WithContext(ctx), "", from, from.AddDate(0, 0, c.DaysAhead))
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("failed to get upcoming dates")
+		return err
+	}
+
+	sent := 0
+	for _, upcoming := range upcomingDates(contacts, from, c.DaysAhead) {
+		ok, err := c.remind(ctx, upcoming)
+		if err != nil {
+			c.Log.With(zap.Error(err)).Error("failed to send reminder",
+				zap.String("contact_id", upcoming.Contact.ID), zap.String("date", upcoming.Date.Key()))
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	if sent > 0 {
+		c.Log.Info("sent reminders", zap.Int("reminders", sent))
+	}
+	return nil
+}
+
+// remind records the reminder and notifies in one transaction, so the record
+// only stays when the notifier took the notification.
+func (c *ReminderUseCase) remind(ctx context.Context, upcoming upcomingDate) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	reminder := &entity.Reminder{
+		ID:        uuid.New().String(),
+		UserId:    upcoming.Contact.UserId,
+		ContactId: upcoming.Contact.ID,
+		DateKey:   upcoming.Date.Key(),
+		OccursOn:  upcoming.OccursOn.Format(contactDateLayout),
+	}
+
+	created, err := c.ReminderRepository.CreateIfAbsent(tx, reminder)
+	if err != nil || !created {
+		return false, err
+	}
+
+	if err := c.Notifier.Notify(ctx, &notify.Notification{
+		UserId:    reminder.UserId,
+		Kind:      notify.KindReminder,
+		Title:     contactDateSummary(upcoming.Contact, upcoming.Date),
+		Body:      reminderBody(upcoming),
+		ContactId: reminder.ContactId,
+		OccursOn:  reminder.OccursOn,
+	}); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit().Error
+}
+
+// reminderBody says when the date is, as in "Tomorrow, Tuesday 18 May
+// (turns 36)".
+func reminderBody(upcoming upcomingDate) string {
+	body := upcoming.OccursOn.Format("Monday 2 January")
+	switch upcoming.DaysUntil {
+	case 0:
+		body = "Today, " + body
+	case 1:
+		body = "Tomorrow, " + body
+	default:
+		body = fmt.Sprintf("In %d days, %s", upcoming.DaysUntil, body)
+	}
+
+	if upcoming.Years > 0 {
+		if upcoming.Date.Type == entity.DateTypeBirthday {
+			body += fmt.Sprintf(" (turns %d)", upcoming.Years)
+		} else {
+			body += fmt.Sprintf(" (%d years)", upcoming.Years)
+		}
+	}
+	return body
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ShareUseCase struct {
+	DB                *gorm.DB
+	Log               *zap.Logger
+	Validate          *validator.Validate
+	ShareRepository   *repository.ShareRepository
+	ContactRepository *repository.ContactRepository
+	TagRepository     *repository.TagRepository
+	UserRepository    *repository.UserRepository
+}
+
+func NewShareUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
+	shareRepository *repository.ShareRepository, contactRepository *repository.ContactRepository,
+	tagRepository *repository.TagRepository, userRepository *repository.UserRepository) *ShareUseCase {
+	return &ShareUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		ShareRepository:   shareRepository,
+		ContactRepository: contactRepository,
+		TagRepository:     tagRepository,
+		UserRepository:    userRepository,
+	}
+}
+
+func (c *ShareUseCase) Create(ctx context.Context, request *dto.CreateShareRequest) (*dto.ShareResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	if request.GranteeId == request.UserId {
+		c.Log.Warn("cannot share with oneself")
+		return nil, apperror.Invalid("cannot share with yourself")
+	}
+
+	total, err := c.UserRepository.CountById(tx, request.GranteeId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error counting users")
+		return nil, apperror.ErrInternal
+	}
+
+	if total == 0 {
+		c.Log.Warn("grantee not found")
+		return nil, apperror.NotFound("grantee not found")
+	}
+
+	share := &entity.Share{
+		ID:         uuid.NewString(),
+		OwnerId:    request.UserId,
+		GranteeId:  request.GranteeId,
+		Permission: request.Permission,
+	}
+
+	if request.ContactId != "" {
+		contact := new(entity.Contact)
+		if err := c.ContactRepository.FindByIdAndUserId(tx, contact, request.ContactId, request.UserId); err != nil {
+			c.Log.With(zap.Error(err)).Error("error getting contact")
+			return nil, apperror.ErrNotFound
+		}
+		share.ContactId = &contact.ID
+	} else {
+		tag := new(entity.Tag)
+		if err := c.TagRepository.FindByIdAndUserId(tx, tag, request.TagId, request.UserId); err != nil {
+			c.Log.With(zap.Error(err)).Error("error getting tag")
+			return nil, apperror.ErrNotFound
+		}
+		share.TagId = &tag.ID
+	}
+
+	total, err = c.ShareRepository.CountByTarget(tx, share.GranteeId, share.ContactId, share.TagId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error counting shares")
+		return nil, apperror.ErrInternal
+	}
+
+	if total > 0 {
+		c.Log.Warn("share already exists")
+		return nil, apperror.ErrConflict
+	}
+
+	if err := c.ShareRepository.Create(tx, share); err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating share")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating share")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.ShareToResponse(share), nil
+}
+
+func (c *ShareUseCase) Update(ctx context.Context, request *dto.UpdateShareRequest) (*dto.ShareResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	share := new(entity.Share)
+	if err := c.ShareRepository.FindByIdAndOwnerId(tx, share, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting share")
+		return nil, apperror.ErrNotFound
+	}
+
+	share.Permission = request.Permission
+
+	if err := c.ShareRepository.Update(tx, share); err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating share")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating share")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.ShareToResponse(share), nil
+}
+
+// Delete revokes the share. Access is checked against the shares table on
+// every request, so the grantee loses access as soon as this commits.
+func (c *ShareUseCase) Delete(ctx context.Context, request *dto.DeleteShareRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return apperror.Validation(err)
+	}
+
+	share := new(entity.Share)
+	if err := c.ShareRepository.FindByIdAndOwnerId(tx, share, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting share")
+		return apperror.ErrNotFound
+	}
+
+	if err := c.ShareRepository.Delete(tx, share); err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting share")
+		return apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting share")
+		return apperror.ErrInternal
+	}
+
+	return nil
+}
+
+func (c *ShareUseCase) List(ctx context.Context, request *dto.ListShareRequest) ([]dto.ShareResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	shares, err := c.ShareRepository.FindAllByOwnerId(tx, request.UserId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting shares")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting shares")
+		return nil, apperror.ErrInternal
+	}
+
+	responses := make([]dto.ShareResponse, len(shares))
+	for i, share := range shares {
+		responses[i] = *converter.ShareToResponse(&share)
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TagUseCase struct {
+	DB                *gorm.DB
+	Log               *zap.Logger
+	Validate          *validator.Validate
+	TagRepository     *repository.TagRepository
+	ContactRepository *repository.ContactRepository
+	HistoryRepository *repository.ContactHistoryRepository
+}
+
+func NewTagUseCase(db *gorm.DB, logger *zap.Logger, validate *validator.Validate,
+	tagRepository *repository.TagRepository, contactRepository *repository.ContactRepository,
+	historyRepository *repository.ContactHistoryRepository) *TagUseCase {
+	return &TagUseCase{
+		DB:                db,
+		Log:               logger,
+		Validate:          validate,
+		TagRepository:     tagRepository,
+		ContactRepository: contactRepository,
+		HistoryRepository: historyRepository,
+	}
+}
+
+func (c *TagUseCase) Create(ctx context.Context, request *dto.CreateTagRequest) (*dto.TagResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	total, err := c.TagRepository.CountByNameAndUserId(tx, request.Name, request.UserId, "")
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error counting tags")
+		return nil, apperror.ErrInternal
+	}
+
+	if total > 0 {
+		c.Log.Warn("tag already exists")
+		return nil, apperror.ErrConflict
+	}
+
+	tag := &entity.Tag{
+		ID:     uuid.NewString(),
+		UserId: request.UserId,
+		Name:   request.Name,
+	}
+
+	if err := c.TagRepository.Create(tx, tag); err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating tag")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error creating tag")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.TagToResponse(tag), nil
+}
+
+func (c *TagUseCase) Update(ctx context.Context, request *dto.UpdateTagRequest) (*dto.TagResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	tag := new(entity.Tag)
+	if err := c.TagRepository.FindByIdAndUserId(tx, tag, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tag")
+		return nil, apperror.ErrNotFound
+	}
+
+	total, err := c.TagRepository.CountByNameAndUserId(tx, request.Name, request.UserId, tag.ID)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error counting tags")
+		return nil, apperror.ErrInternal
+	}
+
+	if total > 0 {
+		c.Log.Warn("tag already exists")
+		return nil, apperror.ErrConflict
+	}
+
+	contacts, err := c.ContactRepository.FindAllByTagIdForUpdate(tx, tag.ID)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tagged contacts")
+		return nil, apperror.ErrInternal
+	}
+
+	tag.Name = request.Name
+
+	if err := c.TagRepository.Update(tx, tag); err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating tag")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.BumpVersionByTagId(tx, tag.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating tagged contacts")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := recordContactUpdates(ctx, tx, c.HistoryRepository, contacts, request.UserId, taggedContactSnapshot,
+		func(contact *entity.Contact) {
+			for i := range contact.Tags {
+				if contact.Tags[i].ID == tag.ID {
+					contact.Tags[i].Name = tag.Name
+				}
+			}
+		}); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating tag")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.TagToResponse(tag), nil
+}
+
+func (c *TagUseCase) Get(ctx context.Context, request *dto.GetTagRequest) (*dto.TagResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	tag := new(entity.Tag)
+	if err := c.TagRepository.FindByIdAndUserId(tx, tag, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tag")
+		return nil, apperror.ErrNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tag")
+		return nil, apperror.ErrInternal
+	}
+
+	return converter.TagToResponse(tag), nil
+}
+
+func (c *TagUseCase) Delete(ctx context.Context, request *dto.DeleteTagRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return apperror.Validation(err)
+	}
+
+	tag := new(entity.Tag)
+	if err := c.TagRepository.FindByIdAndUserId(tx, tag, request.ID, request.UserId); err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tag")
+		return apperror.ErrNotFound
+	}
+
+	// The tagged contacts are only known until the contact_tags rows go with
+	// the tag through ON DELETE CASCADE.
+	contacts, err := c.ContactRepository.FindAllByTagIdForUpdate(tx, tag.ID)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tagged contacts")
+		return apperror.ErrInternal
+	}
+
+	if err := c.ContactRepository.BumpVersionByTagId(tx, tag.ID); err != nil {
+		c.Log.With(zap.Error(err)).Error("error updating tagged contacts")
+		return apperror.ErrInternal
+	}
+
+	if err := c.TagRepository.Delete(tx, tag); err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting tag")
+		return apperror.ErrInternal
+	}
+
+	if err := recordContactUpdates(ctx, tx, c.HistoryRepository, contacts, request.UserId, taggedContactSnapshot,
+		func(contact *entity.Contact) {
+			contact.Tags = slices.DeleteFunc(contact.Tags, func(t entity.Tag) bool { return t.ID == tag.ID })
+		}); err != nil {
+		c.Log.With(zap.Error(err)).Error("error recording contact history")
+		return apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error deleting tag")
+		return apperror.ErrInternal
+	}
+
+	return nil
+}
+
+func (c *TagUseCase) List(ctx context.Context, request *dto.ListTagRequest) ([]dto.TagResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.With(zap.Error(err)).Error("error validating request body")
+		return nil, apperror.Validation(err)
+	}
+
+	tags, err := c.TagRepository.FindAllByUserId(tx, request.UserId)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tags")
+		return nil, apperror.ErrInternal
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error getting tags")
+		return nil, apperror.ErrInternal
+	}
+
+	responses := make([]dto.TagResponse, len(tags))
+	for i, tag := range tags {
+		responses[i] = *converter.TagToResponse(&tag)
+	}
+
+	return responses, nil
+}
+
+func taggedContactSnapshot(contact *entity.Contact) map[string]any {
+	return withTags(contactSnapshot(contact), contact.Tags)
+}
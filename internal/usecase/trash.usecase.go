@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/repository"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TrashUseCase struct {
+	DB                *gorm.DB
+	Log               *zap.Logger
+	ContactRepository *repository.ContactRepository
+	AddressRepository *repository.AddressRepository
+	BlobStore         storage.BlobStore
+}
+
+func NewTrashUseCase(db *gorm.DB, logger *zap.Logger,
+	contactRepository *repository.ContactRepository, addressRepository *repository.AddressRepository,
+	blobStore storage.BlobStore) *TrashUseCase {
+	return &TrashUseCase{
+		DB:                db,
+		Log:               logger,
+		ContactRepository: contactRepository,
+		AddressRepository: addressRepository,
+		BlobStore:         blobStore,
+	}
+}
+
+// Purge hard deletes what has been in the trash longer than retention. The
+// photos of purged contacts are removed from the blob store once that has
+// committed; trashing alone keeps them, so a restored contact gets its photo
+// back.
+func (c *TrashUseCase) Purge(ctx context.Context, retention time.Duration) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	before := time.Now().Add(-retention).UnixMilli()
+
+	// The photos to delete are the ones of the contacts the delete removed,
+	// not of a separate read that a restore could race.
+	contacts, err := c.ContactRepository.PurgeTrashed(tx, before)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error purging trashed contacts")
+		return err
+	}
+
+	addresses, err := c.AddressRepository.PurgeTrashed(tx, before)
+	if err != nil {
+		c.Log.With(zap.Error(err)).Error("error purging trashed addresses")
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.With(zap.Error(err)).Error("error purging trash")
+		return err
+	}
+
+	for _, contact := range contacts {
+		deletePhotoBlobs(ctx, c.BlobStore, c.Log, contact.PhotoId)
+	}
+
+	c.Log.Info("purged trash", zap.Int("contacts", len(contacts)), zap.Int64("addresses", addresses))
+	return nil
+}
@@ -5,7 +5,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	"github.com/labstack/echo/v4"
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/converter"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/dto"
 	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/entity"
@@ -39,18 +39,18 @@ func (c *UserUseCase) Verify(ctx context.Context, request *dto.VerifyUserRequest
 	err := c.Validate.Struct(request)
 	if err != nil {
 		c.Log.Warn("Invalid request body", zap.Error(err))
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
 	}
 
 	user := new(entity.User)
 	if err := c.UserRepository.FindByToken(tx, user, request.Token); err != nil {
 		c.Log.Warn("Failed find user by token", zap.Error(err))
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warn("Failed commit transaction", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return &dto.Auth{ID: user.ID}, nil
@@ -63,24 +63,24 @@ func (c *UserUseCase) Create(ctx context.Context, request *dto.RegisterUserReque
 	err := c.Validate.Struct(request)
 	if err != nil {
 		c.Log.Warn("Invalid request body", zap.Error(err))
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
 	}
 
 	total, err := c.UserRepository.CountById(tx, request.ID)
 	if err != nil {
 		c.Log.Warn("Failed count user from database", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	if total > 0 {
 		c.Log.Warn("User already exists")
-		return nil, echo.ErrConflict
+		return nil, apperror.ErrConflict
 	}
 
 	password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
 	if err != nil {
 		c.Log.Warn("Failed to generate bcrype hash", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	user := &entity.User{
@@ -91,12 +91,12 @@ func (c *UserUseCase) Create(ctx context.Context, request *dto.RegisterUserReque
 
 	if err := c.UserRepository.Create(tx, user); err != nil {
 		c.Log.Warn("Failed create user to database", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warn("Failed commit transaction", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.UserToResponse(user), nil
@@ -108,29 +108,29 @@ func (c *UserUseCase) Login(ctx context.Context, request *dto.LoginUserRequest)
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warn("Invalid request body ", zap.Error(err))
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
 	}
 
 	user := new(entity.User)
 	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
 		c.Log.Warn("Failed find user by id", zap.Error(err))
-		return nil, echo.ErrUnauthorized
+		return nil, apperror.ErrUnauthorized
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
 		c.Log.Warn("Failed to compare user password with bcrype hash", zap.Error(err))
-		return nil, echo.ErrUnauthorized
+		return nil, apperror.ErrUnauthorized
 	}
 
 	user.Token = uuid.New().String()
 	if err := c.UserRepository.Update(tx, user); err != nil {
 		c.Log.Warn("Failed save user", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warn("Failed commit transaction", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.UserToTokenResponse(user), nil
@@ -142,18 +142,18 @@ func (c *UserUseCase) Current(ctx context.Context, request *dto.GetUserRequest)
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warn("Invalid request body", zap.Error(err))
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
 	}
 
 	user := new(entity.User)
 	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
 		c.Log.Warn("Failed find user by id", zap.Error(err))
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warn("Failed commit transaction", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.UserToResponse(user), nil
@@ -165,25 +165,25 @@ func (c *UserUseCase) Logout(ctx context.Context, request *dto.LogoutUserRequest
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warn("Invalid request body", zap.Error(err))
-		return false, echo.ErrBadRequest
+		return false, apperror.Validation(err)
 	}
 
 	user := new(entity.User)
 	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
 		c.Log.Warn("Failed find user by id", zap.Error(err))
-		return false, echo.ErrNotFound
+		return false, apperror.ErrNotFound
 	}
 
 	user.Token = ""
 
 	if err := c.UserRepository.Update(tx, user); err != nil {
 		c.Log.Warn("Failed save user", zap.Error(err))
-		return false, echo.ErrInternalServerError
+		return false, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warn("Failed commit transaction", zap.Error(err))
-		return false, echo.ErrInternalServerError
+		return false, apperror.ErrInternal
 	}
 
 	return true, nil
@@ -195,13 +195,13 @@ func (c *UserUseCase) Update(ctx context.Context, request *dto.UpdateUserRequest
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warn("Invalid request body", zap.Error(err))
-		return nil, echo.ErrBadRequest
+		return nil, apperror.Validation(err)
 	}
 
 	user := new(entity.User)
 	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
 		c.Log.Warn("Failed find user by id", zap.Error(err))
-		return nil, echo.ErrNotFound
+		return nil, apperror.ErrNotFound
 	}
 
 	if request.Name != "" {
@@ -212,19 +212,19 @@ func (c *UserUseCase) Update(ctx context.Context, request *dto.UpdateUserRequest
 		password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
 		if err != nil {
 			c.Log.Warn("Failed to generate bcrype hash", zap.Error(err))
-			return nil, echo.ErrInternalServerError
+			return nil, apperror.ErrInternal
 		}
 		user.Password = string(password)
 	}
 
 	if err := c.UserRepository.Update(tx, user); err != nil {
 		c.Log.Warn("Failed save user", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warn("Failed commit transaction", zap.Error(err))
-		return nil, echo.ErrInternalServerError
+		return nil, apperror.ErrInternal
 	}
 
 	return converter.UserToResponse(user), nil
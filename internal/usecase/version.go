@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"slices"
+
+	"github.com/ta-anomaly-detection/web-server-reference/internal/domain/apperror"
+)
+
+var errVersionMismatch = apperror.New(apperror.KindPrecondition, "resource has been modified")
+
+// checkVersion enforces an If-Match precondition. An empty list means the
+// client sent none.
+func checkVersion(ifMatch []int64, version int64) error {
+	if len(ifMatch) > 0 && !slices.Contains(ifMatch, version) {
+		return errVersionMismatch
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+// Package vcard reads and writes the subset of vCard 3.0 (RFC 2426) and 4.0
+// (RFC 6350) that maps onto contacts: names, email, phone, addresses, birthday
+// and anniversary.
+package vcard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrMalformed = errors.New("vcard: malformed input")
+
+type Address struct {
+	Types      []string
+	Street     string
+	City       string
+	Province   string
+	PostalCode string
+	Country    string
+}
+
+type Card struct {
+	// Line is where BEGIN:VCARD appeared, for error reporting.
+	Line       int
+	Version    string
+	UID        string
+	FormatName string
+	FamilyName string
+	GivenName  string
+	Emails     []string
+	Phones     []string
+	Addresses  []Address
+	Categories []string
+	// Birthday and Anniversary are YYYY-MM-DD, or --MM-DD without a year,
+	// and empty when missing or not a plain date.
+	Birthday    string
+	Anniversary string
+}
+
+type property struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+	pending *string
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &Reader{scanner: scanner}
+}
+
+// ReadAll parses every card in the stream.
+func ReadAll(r io.Reader) ([]Card, error) {
+	reader := NewReader(r)
+	var cards []Card
+	for {
+		card, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return cards, nil
+		}
+		if err != nil {
+			return cards, err
+		}
+		cards = append(cards, *card)
+	}
+}
+
+// Read returns the next card, or io.EOF when the stream is exhausted.
+func (r *Reader) Read() (*Card, error) {
+	var card *Card
+
+	for {
+		line, lineNumber, err := r.unfoldedLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) && card != nil {
+				return nil, fmt.Errorf("%w: line %d: missing END:VCARD", ErrMalformed, card.Line)
+			}
+			return nil, err
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrMalformed, lineNumber, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCARD"):
+			if card != nil {
+				return nil, fmt.Errorf("%w: line %d: nested BEGIN:VCARD", ErrMalformed, lineNumber)
+			}
+			card = &Card{Line: lineNumber}
+		case card == nil:
+			return nil, fmt.Errorf("%w: line %d: property outside of a card", ErrMalformed, lineNumber)
+		case prop.name == "END" && strings.EqualFold(prop.value, "VCARD"):
+			return card, nil
+		default:
+			card.apply(prop)
+		}
+	}
+}
+
+func (r *Reader) unfoldedLine() (string, int, error) {
+	var builder strings.Builder
+	start := 0
+
+	if r.pending != nil {
+		builder.WriteString(*r.pending)
+		start = r.line
+		r.pending = nil
+	} else {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return "", r.line, err
+			}
+			return "", r.line, io.EOF
+		}
+		r.line++
+		start = r.line
+		builder.WriteString(strings.TrimRight(r.scanner.Text(), "\r"))
+	}
+
+	// Folded lines continue with a single leading space or tab.
+	for r.scanner.Scan() {
+		r.line++
+		next := strings.TrimRight(r.scanner.Text(), "\r")
+		if strings.HasPrefix(next, " ") || strings.HasPrefix(next, "\t") {
+			builder.WriteString(next[1:])
+			continue
+		}
+		r.pending = &next
+		break
+	}
+
+	return builder.String(), start, r.scanner.Err()
+}
+
+func parseProperty(line string) (property, error) {
+	colon := indexUnquoted(line, ':')
+	if colon < 0 {
+		return property{}, errors.New("missing ':'")
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := splitUnquoted(head, ';')
+
+	name := strings.ToUpper(strings.TrimSpace(parts[0]))
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		// Drop group prefixes such as "item1.EMAIL".
+		name = name[dot+1:]
+	}
+	if name == "" {
+		return property{}, errors.New("empty property name")
+	}
+
+	params := map[string][]string{}
+	for _, param := range parts[1:] {
+		key, val, found := strings.Cut(param, "=")
+		if !found {
+			// vCard 2.1 style bare parameter, e.g. "TEL;CELL:".
+			params["TYPE"] = append(params["TYPE"], strings.ToLower(key))
+			continue
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		for _, v := range splitUnquoted(val, ',') {
+			params[key] = append(params[key], strings.ToLower(strings.Trim(v, `"`)))
+		}
+	}
+
+	return property{name: name, params: params, value: value}, nil
+}
+
+func (c *Card) apply(prop property) {
+	switch prop.name {
+	case "VERSION":
+		c.Version = strings.TrimSpace(prop.value)
+	case "UID":
+		c.UID = strings.TrimSpace(unescape(prop.value))
+	case "CATEGORIES":
+		for _, category := range splitEscaped(prop.value, ',') {
+			if category = strings.TrimSpace(unescape(category)); category != "" {
+				c.Categories = append(c.Categories, category)
+			}
+		}
+	case "FN":
+		c.FormatName = unescape(prop.value)
+	case "N":
+		fields := splitComponents(prop.value)
+		c.FamilyName = component(fields, 0)
+		c.GivenName = component(fields, 1)
+	case "EMAIL":
+		if email := strings.TrimSpace(unescape(prop.value)); email != "" {
+			c.Emails = append(c.Emails, email)
+		}
+	case "TEL":
+		phone := strings.TrimSpace(unescape(prop.value))
+		phone = strings.TrimPrefix(phone, "tel:")
+		if phone != "" {
+			c.Phones = append(c.Phones, phone)
+		}
+	case "BDAY":
+		c.Birthday = parseDate(prop.value)
+	case "ANNIVERSARY", "X-ANNIVERSARY":
+		c.Anniversary = parseDate(prop.value)
+	case "ADR":
+		fields := splitComponents(prop.value)
+		street := component(fields, 2)
+		if extended := component(fields, 1); extended != "" {
+			street = strings.TrimSpace(street + " " + extended)
+		}
+		if poBox := component(fields, 0); poBox != "" && street == "" {
+			street = poBox
+		}
+		c.Addresses = append(c.Addresses, Address{
+			Types:      prop.params["TYPE"],
+			Street:     street,
+			City:       component(fields, 3),
+			Province:   component(fields, 4),
+			PostalCode: component(fields, 5),
+			Country:    component(fields, 6),
+		})
+	}
+}
+
+// parseDate reads the basic (19900517, --0517) and extended (1990-05-17,
+// --05-17) date forms, ignoring any time of day. Free text, which vCard 4.0
+// also allows, is not a date and yields "".
+func parseDate(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexByte(value, 'T'); i >= 0 {
+		value = value[:i]
+	}
+
+	yearless := strings.HasPrefix(value, "--")
+	digits := strings.ReplaceAll(strings.TrimPrefix(value, "--"), "-", "")
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+
+	switch {
+	case yearless && len(digits) == 4:
+		return "--" + digits[:2] + "-" + digits[2:]
+	case !yearless && len(digits) == 8:
+		return digits[:4] + "-" + digits[4:6] + "-" + digits[6:]
+	default:
+		return ""
+	}
+}
+
+// Names returns the given and family name, falling back to splitting FN for
+// cards that omit or leave N empty.
+func (c *Card) Names() (string, string) {
+	if c.GivenName != "" || c.FamilyName != "" {
+		return c.GivenName, c.FamilyName
+	}
+
+	fields := strings.Fields(c.FormatName)
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		return fields[0], ""
+	default:
+		return strings.Join(fields[:len(fields)-1], " "), fields[len(fields)-1]
+	}
+}
+
+func splitComponents(value string) []string {
+	raw := splitEscaped(value, ';')
+	fields := make([]string, len(raw))
+	for i, field := range raw {
+		// Multiple values within a component are comma separated; keep the first.
+		fields[i] = strings.TrimSpace(unescape(splitEscaped(field, ',')[0]))
+	}
+	return fields
+}
+
+func component(fields []string, index int) string {
+	if index < len(fields) {
+		return fields[index]
+	}
+	return ""
+}
+
+func splitEscaped(value string, separator byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case separator:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+func splitUnquoted(value string, separator byte) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			quoted = !quoted
+		case separator:
+			if !quoted {
+				parts = append(parts, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, value[start:])
+}
+
+func indexUnquoted(value string, target byte) int {
+	quoted := false
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			quoted = !quoted
+		case target:
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func unescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			builder.WriteByte('\n')
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}
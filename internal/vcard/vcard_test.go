@@ -0,0 +1,143 @@
+package vcard
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []Card
+		wantErr string
+	}{
+		{
+			name: "vCard 3.0 with every property",
+			source: "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:urn:uuid:1\r\nFN:Ada Lovelace\r\nN:Lovelace;Ada;Augusta;;\r\n" +
+				"EMAIL;TYPE=INTERNET:ada@example.com\r\nTEL;TYPE=CELL:+44 20 7946 0000\r\n" +
+				"ADR;TYPE=HOME,PREF:;Flat 2;12 St James\\, Square;London;;SW1Y 4JH;United Kingdom\r\n" +
+				"BDAY:1815-12-10\r\nX-ANNIVERSARY:18350708\r\nCATEGORIES:friends,math\\,science\r\nEND:VCARD\r\n",
+			want: []Card{{
+				Line:       1,
+				Version:    "3.0",
+				UID:        "urn:uuid:1",
+				FormatName: "Ada Lovelace",
+				FamilyName: "Lovelace",
+				GivenName:  "Ada",
+				Emails:     []string{"ada@example.com"},
+				Phones:     []string{"+44 20 7946 0000"},
+				Addresses: []Address{{
+					Types:      []string{"home", "pref"},
+					Street:     "12 St James, Square Flat 2",
+					City:       "London",
+					PostalCode: "SW1Y 4JH",
+					Country:    "United Kingdom",
+				}},
+				Categories:  []string{"friends", "math,science"},
+				Birthday:    "1815-12-10",
+				Anniversary: "1835-07-08",
+			}},
+		},
+		{
+			name: "vCard 4.0 with groups, tel URIs and yearless dates",
+			source: "BEGIN:VCARD\nVERSION:4.0\nFN:Grace Hopper\nitem1.EMAIL:grace@example.com\n" +
+				"TEL;VALUE=uri;TYPE=\"voice,cell\":tel:+1-555-0100\nBDAY:--1209\nANNIVERSARY:circa 1930\nEND:VCARD\n",
+			want: []Card{{
+				Line:       1,
+				Version:    "4.0",
+				FormatName: "Grace Hopper",
+				Emails:     []string{"grace@example.com"},
+				Phones:     []string{"+1-555-0100"},
+				Birthday:   "--12-09",
+			}},
+		},
+		{
+			name:   "vCard 2.1 bare parameters and a PO box",
+			source: "BEGIN:VCARD\nVERSION:2.1\nN:Doe;John\nTEL;CELL:555\nADR;WORK:PO Box 7;;;Springfield\nEND:VCARD\n",
+			want: []Card{{
+				Line:       1,
+				Version:    "2.1",
+				FamilyName: "Doe",
+				GivenName:  "John",
+				Phones:     []string{"555"},
+				Addresses:  []Address{{Types: []string{"work"}, Street: "PO Box 7", City: "Springfield"}},
+			}},
+		},
+		{
+			name:   "folded lines and escaped newlines",
+			source: "BEGIN:VCARD\r\nFN:Long\r\n  Name\r\nN:Name;Long\\nLine\r\n\tTail\r\nEND:VCARD\r\n",
+			want:   []Card{{Line: 1, FormatName: "Long Name", FamilyName: "Name", GivenName: "Long\nLineTail"}},
+		},
+		{
+			name:   "several cards and blank lines",
+			source: "BEGIN:VCARD\nFN:One\nEND:VCARD\n\nBEGIN:VCARD\nFN:Two\nEND:VCARD\n",
+			want:   []Card{{Line: 1, FormatName: "One"}, {Line: 5, FormatName: "Two"}},
+		},
+		{name: "empty input"},
+		{name: "missing END", source: "BEGIN:VCARD\nFN:Open\n", wantErr: "line 1: missing END:VCARD"},
+		{name: "nested BEGIN", source: "BEGIN:VCARD\nBEGIN:VCARD\n", wantErr: "line 2: nested BEGIN:VCARD"},
+		{name: "property outside of a card", source: "FN:Loose\n", wantErr: "line 1: property outside of a card"},
+		{name: "missing colon", source: "BEGIN:VCARD\nFN Loose\nEND:VCARD\n", wantErr: "line 2: missing ':'"},
+		{name: "empty property name", source: "BEGIN:VCARD\n:value\nEND:VCARD\n", wantErr: "line 2: empty property name"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cards, err := ReadAll(strings.NewReader(test.source))
+			if test.wantErr != "" {
+				if !errors.Is(err, ErrMalformed) || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("ReadAll() error = %v, want ErrMalformed containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if !reflect.DeepEqual(cards, test.want) {
+				t.Errorf("ReadAll() =\n%+v\nwant\n%+v", cards, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := map[string]string{
+		"1990-05-17":           "1990-05-17",
+		"19900517":             "1990-05-17",
+		"1990-05-17T10:00:00Z": "1990-05-17",
+		"--05-17":              "--05-17",
+		"--0517":               "--05-17",
+		" 1990-05-17 ":         "1990-05-17",
+		"1990-05":              "",
+		"--5-17":               "",
+		"May 17":               "",
+		"":                     "",
+	}
+	for value, want := range tests {
+		if got := parseDate(value); got != want {
+			t.Errorf("parseDate(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		card                  Card
+		wantGiven, wantFamily string
+	}{
+		{Card{GivenName: "Ada", FamilyName: "Lovelace", FormatName: "Countess"}, "Ada", "Lovelace"},
+		{Card{FamilyName: "Lovelace"}, "", "Lovelace"},
+		{Card{FormatName: "Mary Ann Evans"}, "Mary Ann", "Evans"},
+		{Card{FormatName: "Cher"}, "Cher", ""},
+		{Card{FormatName: "  "}, "", ""},
+	}
+	for _, test := range tests {
+		given, family := test.card.Names()
+		if given != test.wantGiven || family != test.wantFamily {
+			t.Errorf("Names() of %+v = %q, %q, want %q, %q", test.card, given, family, test.wantGiven, test.wantFamily)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package vcard
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the folding limit from RFC 6350 section 3.2, excluding the
+// line break.
+const maxLineOctets = 75
+
+// Writer emits vCard 3.0, which both 3.0 and 4.0 readers accept for the
+// properties used here.
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) Write(card *Card) error {
+	w.line("BEGIN:VCARD")
+	w.line("VERSION:3.0")
+
+	if card.UID != "" {
+		w.line("UID:" + escape(card.UID))
+	}
+
+	formatName := card.FormatName
+	if formatName == "" {
+		formatName = strings.TrimSpace(card.GivenName + " " + card.FamilyName)
+	}
+	w.line("FN:" + escape(formatName))
+	w.line("N:" + escape(card.FamilyName) + ";" + escape(card.GivenName) + ";;;")
+
+	for _, email := range card.Emails {
+		w.line("EMAIL;TYPE=INTERNET:" + escape(email))
+	}
+	for _, phone := range card.Phones {
+		w.line("TEL:" + escape(phone))
+	}
+
+	for _, address := range card.Addresses {
+		name := "ADR"
+		if len(address.Types) > 0 {
+			name += ";TYPE=" + strings.Join(address.Types, ",")
+		}
+		w.line(name + ":;;" + strings.Join([]string{
+			escape(address.Street),
+			escape(address.City),
+			escape(address.Province),
+			escape(address.PostalCode),
+			escape(address.Country),
+		}, ";"))
+	}
+
+	if card.Birthday != "" {
+		w.line("BDAY:" + card.Birthday)
+	}
+	// ANNIVERSARY only exists from vCard 4.0 on; 3.0 readers know the
+	// extension property instead.
+	if card.Anniversary != "" {
+		w.line("X-ANNIVERSARY:" + card.Anniversary)
+	}
+
+	if len(card.Categories) > 0 {
+		categories := make([]string, len(card.Categories))
+		for i, category := range card.Categories {
+			categories[i] = escape(category)
+		}
+		w.line("CATEGORIES:" + strings.Join(categories, ","))
+	}
+
+	w.line("END:VCARD")
+	return w.Flush()
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// line writes a content line, folding it so no physical line exceeds the
+// octet limit and no UTF-8 sequence is split.
+func (w *Writer) line(content string) {
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		w.w.WriteString(content[:cut])
+		w.w.WriteString("\r\n ")
+		content = content[cut:]
+		// The leading space of a continuation line counts towards its length.
+		limit = maxLineOctets - 1
+	}
+	w.w.WriteString(content)
+	w.w.WriteString("\r\n")
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
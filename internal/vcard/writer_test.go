@@ -0,0 +1,72 @@
+package vcard
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	card := Card{
+		UID:        "c-1",
+		FormatName: "Zoë Ünal",
+		FamilyName: "Ünal",
+		GivenName:  "Zoë",
+		Emails:     []string{"zoe@example.com"},
+		Phones:     []string{"+90 212 555 0000"},
+		Addresses: []Address{{
+			Types:      []string{"home"},
+			Street:     "Line one; line two, rear",
+			City:       "İstanbul",
+			PostalCode: "34000",
+			Country:    "TR",
+		}},
+		Categories:  []string{"family", "a,b"},
+		Birthday:    "--02-29",
+		Anniversary: "2010-06-01",
+	}
+
+	var buffer bytes.Buffer
+	writer := NewWriter(&buffer)
+	if err := writer.Write(&card); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	cards, err := ReadAll(&buffer)
+	if err != nil || len(cards) != 1 {
+		t.Fatalf("ReadAll() = %d cards, %v", len(cards), err)
+	}
+	card.Line = 1
+	card.Version = "3.0"
+	if !reflect.DeepEqual(cards[0], card) {
+		t.Errorf("round trip =\n%+v\nwant\n%+v", cards[0], card)
+	}
+}
+
+func TestWriterFolding(t *testing.T) {
+	card := Card{FormatName: strings.Repeat("é", 100), Emails: []string{strings.Repeat("a", 200) + "@example.com"}}
+
+	var buffer bytes.Buffer
+	if err := NewWriter(&buffer).Write(&card); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(buffer.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a UTF-8 sequence: %q", line)
+		}
+	}
+
+	cards, err := ReadAll(&buffer)
+	if err != nil || len(cards) != 1 {
+		t.Fatalf("ReadAll() = %d cards, %v", len(cards), err)
+	}
+	if cards[0].FormatName != card.FormatName || cards[0].Emails[0] != card.Emails[0] {
+		t.Errorf("folded values came back as %q, %q", cards[0].FormatName, cards[0].Emails[0])
+	}
+}